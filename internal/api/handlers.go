@@ -2,10 +2,14 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/boringsql/pg-storage-visualizer/internal/inspector"
 )
@@ -201,12 +205,189 @@ func (h *Handler) GetHeapPageDetail(w http.ResponseWriter, r *http.Request) {
 		h.err(w, 400, "invalid block number")
 		return
 	}
+	snap, ok := h.snapshotParam(w, r)
+	if !ok {
+		return
+	}
 	var out *inspector.HeapPageDetail
 	if r.URL.Query().Get("attrs") == "true" {
-		out, err = h.inspector.GetHeapPageWithAttrs(r.Context(), name, blk)
+		out, err = h.inspector.GetHeapPageWithAttrs(r.Context(), name, blk, snap)
+	} else {
+		out, err = h.inspector.GetHeapPageAt(r.Context(), name, blk, snap)
+	}
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+// snapshotParam reads ?snapshot= or ?session=; nil means the current
+// snapshot. It writes the error response itself.
+func (h *Handler) snapshotParam(w http.ResponseWriter, r *http.Request) (*inspector.Snapshot, bool) {
+	if s := r.URL.Query().Get("snapshot"); s != "" {
+		snap, err := inspector.ParseSnapshot(s)
+		if err != nil {
+			h.err(w, 400, err.Error())
+			return nil, false
+		}
+		return snap, true
+	}
+	if s := r.URL.Query().Get("session"); s != "" {
+		snap, err := h.inspector.SessionSnapshot(s)
+		if err != nil {
+			h.err(w, 404, err.Error())
+			return nil, false
+		}
+		return snap, true
+	}
+	return nil, true
+}
+
+func (h *Handler) GetHotChains(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "table name required")
+		return
+	}
+	blk, err := strconv.Atoi(r.PathValue("blockno"))
+	if err != nil {
+		h.err(w, 400, "invalid block number")
+		return
+	}
+	snap, ok := h.snapshotParam(w, r)
+	if !ok {
+		return
+	}
+	out, err := h.inspector.GetHotChains(r.Context(), name, blk, snap)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+// FindHotChain looks up a row's chain by ?pk= or by ?page=&item=
+func (h *Handler) FindHotChain(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "table name required")
+		return
+	}
+	snap, ok := h.snapshotParam(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	var out *inspector.RowHotChain
+	var err error
+	if pk := q.Get("pk"); pk != "" {
+		out, err = h.inspector.FindHotChainByPK(r.Context(), name, pk, snap)
 	} else {
-		out, err = h.inspector.GetHeapPageDetail(r.Context(), name, blk)
+		blk, err1 := strconv.Atoi(q.Get("page"))
+		item, err2 := strconv.Atoi(q.Get("item"))
+		if err1 != nil || err2 != nil {
+			h.err(w, 400, "pk or page and item required")
+			return
+		}
+		out, err = h.inspector.FindHotChain(r.Context(), name, blk, item, snap)
+	}
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+// GetRowVersions lists every on-disk version of a row by ?pk=, scanning
+// from ?from= (default 0)
+func (h *Handler) GetRowVersions(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "table name required")
+		return
+	}
+	q := r.URL.Query()
+	pk := q.Get("pk")
+	if pk == "" {
+		h.err(w, 400, "pk required")
+		return
+	}
+	from := 0
+	if s := q.Get("from"); s != "" {
+		var err error
+		if from, err = strconv.Atoi(s); err != nil || from < 0 {
+			h.err(w, 400, "invalid from block")
+			return
+		}
+	}
+	out, err := h.inspector.GetRowVersions(r.Context(), name, pk, from)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+// GetDeadTuples lists non-visible tuples and leftover line pointers across
+// the heap. Filters: xminFrom, xminTo, xmaxFrom, xmaxTo (epoch-qualified
+// or on-page 32-bit XIDs), lp (comma separated, e.g. dead,redirect),
+// removable=true|false; paging: cursor, limit.
+func (h *Handler) GetDeadTuples(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "table name required")
+		return
+	}
+	q := r.URL.Query()
+	var f inspector.DeadTupleFilter
+	for key, dst := range map[string]*int64{"xminFrom": &f.XminFrom, "xminTo": &f.XminTo, "xmaxFrom": &f.XmaxFrom, "xmaxTo": &f.XmaxTo} {
+		if s := q.Get(key); s != "" {
+			v, err := strconv.ParseInt(s, 10, 64)
+			if err != nil || v < 0 {
+				h.err(w, 400, "invalid "+key)
+				return
+			}
+			*dst = v
+		}
+	}
+	if s := q.Get("lp"); s != "" {
+		for _, st := range strings.Split(s, ",") {
+			st = strings.ToUpper(strings.TrimSpace(st))
+			if !strings.HasPrefix(st, "LP_") {
+				st = "LP_" + st
+			}
+			switch st {
+			case "LP_NORMAL", "LP_REDIRECT", "LP_DEAD", "LP_UNUSED":
+				f.LPStates = append(f.LPStates, st)
+			default:
+				h.err(w, 400, "invalid lp state "+st)
+				return
+			}
+		}
+	}
+	if s := q.Get("removable"); s != "" {
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			h.err(w, 400, "invalid removable")
+			return
+		}
+		f.Removable = &v
+	}
+	if s := q.Get("limit"); s != "" {
+		var err error
+		if f.Limit, err = strconv.Atoi(s); err != nil || f.Limit < 1 || f.Limit > 1000 {
+			h.err(w, 400, "limit must be between 1 and 1000")
+			return
+		}
 	}
+	if s := q.Get("cursor"); s != "" {
+		if n, _ := fmt.Sscanf(s, "(%d,%d)", &f.FromBlock, &f.AfterItem); n != 2 || f.FromBlock < 0 {
+			h.err(w, 400, "invalid cursor")
+			return
+		}
+	}
+	out, err := h.inspector.GetDeadTuples(r.Context(), name, f)
 	if err != nil {
 		h.err(w, 500, err.Error())
 		return
@@ -228,6 +409,48 @@ func (h *Handler) GetHeapPageMap(w http.ResponseWriter, r *http.Request) {
 	h.json(w, 200, out)
 }
 
+func (h *Handler) GetFreezeInfo(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "table name required")
+		return
+	}
+	out, err := h.inspector.GetFreezeInfo(r.Context(), name)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+func (h *Handler) PredictVacuum(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "table name required")
+		return
+	}
+	out, err := h.inspector.PredictVacuum(r.Context(), name)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+func (h *Handler) RunVacuumPrediction(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "table name required")
+		return
+	}
+	out, err := h.inspector.RunVacuumPrediction(r.Context(), name)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
 func (h *Handler) FindRow(w http.ResponseWriter, r *http.Request) {
 	name := r.PathValue("name")
 	if name == "" {
@@ -268,6 +491,7 @@ type demoUpdateReq struct {
 	PK       string `json:"pk"`
 	Column   string `json:"column"`
 	NewValue string `json:"newValue"`
+	Rollback bool   `json:"rollback"`
 }
 
 func (h *Handler) DemoUpdate(w http.ResponseWriter, r *http.Request) {
@@ -285,7 +509,281 @@ func (h *Handler) DemoUpdate(w http.ResponseWriter, r *http.Request) {
 		h.err(w, 400, "pk and column required")
 		return
 	}
-	out, err := h.inspector.ExecuteDemoUpdate(r.Context(), name, req.PK, req.Column, req.NewValue)
+	out, err := h.inspector.ExecuteDemoUpdate(r.Context(), name, req.PK, req.Column, req.NewValue, req.Rollback)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+type demoInsertReq struct {
+	Values map[string]string `json:"values"`
+}
+
+func (h *Handler) DemoInsert(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "table name required")
+		return
+	}
+	var req demoInsertReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.err(w, 400, "invalid request body")
+		return
+	}
+	out, err := h.inspector.ExecuteDemoInsert(r.Context(), name, req.Values)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+type demoDeleteReq struct {
+	PK string `json:"pk"`
+}
+
+func (h *Handler) DemoDelete(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "table name required")
+		return
+	}
+	var req demoDeleteReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.err(w, 400, "invalid request body")
+		return
+	}
+	if req.PK == "" {
+		h.err(w, 400, "pk required")
+		return
+	}
+	out, err := h.inspector.ExecuteDemoDelete(r.Context(), name, req.PK)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+type demoPruneReq struct {
+	PK     string `json:"pk"`
+	Column string `json:"column"`
+}
+
+func (h *Handler) DemoPrune(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "table name required")
+		return
+	}
+	var req demoPruneReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.err(w, 400, "invalid request body")
+		return
+	}
+	if req.PK == "" {
+		h.err(w, 400, "pk required")
+		return
+	}
+	out, err := h.inspector.ExecuteDemoPrune(r.Context(), name, req.PK, req.Column)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+type savepointScenarioReq struct {
+	PK     string `json:"pk"`
+	Column string `json:"column"`
+	Finish string `json:"finish"`
+}
+
+func (h *Handler) SavepointScenario(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "table name required")
+		return
+	}
+	var req savepointScenarioReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.err(w, 400, "invalid request body")
+		return
+	}
+	if req.PK == "" {
+		h.err(w, 400, "pk required")
+		return
+	}
+	out, err := h.inspector.RunSavepointScenario(r.Context(), name, req.PK, req.Column, req.Finish)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+func (h *Handler) UpdateExperiment(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "table name required")
+		return
+	}
+	var cfg inspector.UpdateExperimentConfig
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		h.err(w, 400, "invalid request body")
+		return
+	}
+	out, err := h.inspector.RunUpdateExperiment(r.Context(), name, cfg)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+type demoKillBitsReq struct {
+	Step string `json:"step"`
+	Rows int    `json:"rows"`
+}
+
+func (h *Handler) DemoKillBits(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "index name required")
+		return
+	}
+	var req demoKillBitsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.err(w, 400, "invalid request body")
+		return
+	}
+	if req.Step == "" {
+		req.Step = "scan"
+	}
+	out, err := h.inspector.ExecuteKillBitsDemo(r.Context(), name, req.Step, req.Rows)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+type demoSplitsReq struct {
+	Mode  string   `json:"mode"`
+	Count int      `json:"count"`
+	Keys  []string `json:"keys"`
+}
+
+func (h *Handler) DemoSplits(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "index name required")
+		return
+	}
+	var req demoSplitsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.err(w, 400, "invalid request body")
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = "sequential"
+	}
+	out, err := h.inspector.ExecuteSplitDemo(r.Context(), name, req.Mode, req.Count, req.Keys)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+type demoRowReq struct {
+	PK string `json:"pk"`
+}
+
+func (h *Handler) DemoMultiXact(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "table name required")
+		return
+	}
+	var req demoRowReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.err(w, 400, "invalid request body")
+		return
+	}
+	if req.PK == "" {
+		h.err(w, 400, "pk required")
+		return
+	}
+	out, err := h.inspector.ExecuteMultiXactDemo(r.Context(), name, req.PK)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+func (h *Handler) GetRowLocks(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "table name required")
+		return
+	}
+	out, err := h.inspector.GetRowLocks(r.Context(), name)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+type demoLockReq struct {
+	PK      string `json:"pk"`
+	Seconds int    `json:"seconds"`
+}
+
+func (h *Handler) DemoLock(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "table name required")
+		return
+	}
+	var req demoLockReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.err(w, 400, "invalid request body")
+		return
+	}
+	if req.PK == "" {
+		h.err(w, 400, "pk required")
+		return
+	}
+	out, err := h.inspector.ExecuteLockDemo(r.Context(), name, req.PK, time.Duration(req.Seconds)*time.Second)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+type demoMaintenanceReq struct {
+	Operation string `json:"operation"`
+	Index     string `json:"index"`
+}
+
+func (h *Handler) DemoMaintenance(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "table name required")
+		return
+	}
+	var req demoMaintenanceReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.err(w, 400, "invalid request body")
+		return
+	}
+	out, err := h.inspector.ExecuteMaintenanceDemo(r.Context(), name, req.Operation, req.Index)
 	if err != nil {
 		h.err(w, 500, err.Error())
 		return
@@ -322,6 +820,70 @@ func (h *Handler) GetIndexedColumns(w http.ResponseWriter, r *http.Request) {
 	h.json(w, 200, out)
 }
 
+func (h *Handler) EstimateIndex(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "table name required")
+		return
+	}
+	var req inspector.HypotheticalIndexSpec
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.err(w, 400, "invalid request body")
+		return
+	}
+	if len(req.Columns) == 0 {
+		h.err(w, 400, "columns required")
+		return
+	}
+	out, err := h.inspector.EstimateHypotheticalIndex(r.Context(), name, req)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+func (h *Handler) GetTableIndexAdvice(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if name == "" {
+		h.err(w, 400, "table name required")
+		return
+	}
+	out, err := h.inspector.GetIndexAdvice(r.Context(), name)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+func (h *Handler) GetIndexAdvice(w http.ResponseWriter, r *http.Request) {
+	out, err := h.inspector.GetIndexAdvice(r.Context(), "")
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+func (h *Handler) GetCleanupHorizon(w http.ResponseWriter, r *http.Request) {
+	table := r.URL.Query().Get("table")
+	blk := 0
+	if s := r.URL.Query().Get("page"); s != "" {
+		var err error
+		if blk, err = strconv.Atoi(s); err != nil {
+			h.err(w, 400, "invalid block number")
+			return
+		}
+	}
+	out, err := h.inspector.GetCleanupHorizon(r.Context(), table, blk)
+	if err != nil {
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
 func (h *Handler) GetMVCCInfo(w http.ResponseWriter, r *http.Request) {
 	out, err := h.inspector.GetMVCCInfo(r.Context())
 	if err != nil {
@@ -330,3 +892,76 @@ func (h *Handler) GetMVCCInfo(w http.ResponseWriter, r *http.Request) {
 	}
 	h.json(w, 200, out)
 }
+
+var sessionName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+type openSessionReq struct {
+	Name      string `json:"name"`
+	Isolation string `json:"isolation"`
+}
+
+func (h *Handler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	h.json(w, 200, h.inspector.ListSessions())
+}
+
+func (h *Handler) OpenSession(w http.ResponseWriter, r *http.Request) {
+	var req openSessionReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.err(w, 400, "invalid request body")
+		return
+	}
+	if !sessionName.MatchString(req.Name) {
+		h.err(w, 400, "session name must be 1-32 letters, digits, _ or -")
+		return
+	}
+	switch req.Isolation {
+	case "", "read committed", "repeatable read", "serializable":
+	default:
+		h.err(w, 400, "isolation must be read committed, repeatable read or serializable")
+		return
+	}
+	out, err := h.inspector.OpenSession(r.Context(), req.Name, req.Isolation)
+	switch {
+	case errors.Is(err, inspector.ErrSessionExists), errors.Is(err, inspector.ErrTooManySessions):
+		h.err(w, 409, err.Error())
+		return
+	case err != nil:
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 201, out)
+}
+
+type execSessionReq struct {
+	SQL string `json:"sql"`
+}
+
+func (h *Handler) ExecSession(w http.ResponseWriter, r *http.Request) {
+	var req execSessionReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.err(w, 400, "invalid request body")
+		return
+	}
+	if req.SQL == "" {
+		h.err(w, 400, "sql required")
+		return
+	}
+	out, err := h.inspector.ExecSession(r.Context(), r.PathValue("session"), req.SQL)
+	switch {
+	case errors.Is(err, inspector.ErrSessionNotFound):
+		h.err(w, 404, err.Error())
+		return
+	case err != nil:
+		h.err(w, 500, err.Error())
+		return
+	}
+	h.json(w, 200, out)
+}
+
+func (h *Handler) CloseSession(w http.ResponseWriter, r *http.Request) {
+	if err := h.inspector.CloseSession(r.PathValue("session")); err != nil {
+		h.err(w, 404, err.Error())
+		return
+	}
+	w.WriteHeader(204)
+}
@@ -16,10 +16,15 @@ func (h *Handler) HTMXMVCCInfo(w http.ResponseWriter, r *http.Request) {
 	}
 
 	templates.MVCCInfo(templates.MVCCData{
-		CurrentXID:      info.CurrentXID,
-		CurrentSnapshot: info.CurrentSnapshot,
-		XIDMin:          info.XIDMin,
-		XIDMax:          info.XIDMax,
+		CurrentSnapshot:     info.CurrentSnapshot,
+		XIDMin:              info.XIDMin,
+		XIDMax:              info.XIDMax,
+		ActiveXIDs:          info.ActiveXIDs,
+		Epoch:               info.Epoch,
+		NextXID:             info.NextXID,
+		FrozenXIDAge:        info.FrozenXIDAge,
+		WraparoundRemaining: info.WraparoundRemaining,
+		WraparoundPct:       info.WraparoundPct,
 	}).Render(r.Context(), w)
 }
 
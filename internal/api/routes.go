@@ -16,18 +16,45 @@ func SetupRoutes(mux *http.ServeMux, h *Handler, webFS embed.FS) {
 	mux.HandleFunc("GET /api/index/{name}/bloat", h.GetBloatInfo)
 	mux.HandleFunc("GET /api/index/{name}/density", h.GetPageDensityMap)
 	mux.HandleFunc("GET /api/index/{name}/pages", h.GetAllPageStats)
+	mux.HandleFunc("POST /api/index/{name}/demo/kill-bits", h.DemoKillBits)
+	mux.HandleFunc("POST /api/index/{name}/demo/splits", h.DemoSplits)
+	mux.HandleFunc("GET /api/index-advice", h.GetIndexAdvice)
 
 	mux.HandleFunc("GET /api/tables", h.ListTables)
 	mux.HandleFunc("GET /api/table/{name}", h.GetTableDetail)
 	mux.HandleFunc("GET /api/table/{name}/stats", h.GetTableStats)
 	mux.HandleFunc("GET /api/table/{name}/page/{blockno}", h.GetHeapPageDetail)
+	mux.HandleFunc("GET /api/table/{name}/page/{blockno}/hot-chains", h.GetHotChains)
+	mux.HandleFunc("GET /api/table/{name}/hot-chain", h.FindHotChain)
+	mux.HandleFunc("GET /api/table/{name}/row-versions", h.GetRowVersions)
+	mux.HandleFunc("GET /api/table/{name}/dead-tuples", h.GetDeadTuples)
 	mux.HandleFunc("GET /api/table/{name}/pages", h.GetHeapPageMap)
+	mux.HandleFunc("GET /api/table/{name}/freeze", h.GetFreezeInfo)
+	mux.HandleFunc("GET /api/table/{name}/vacuum-prediction", h.PredictVacuum)
+	mux.HandleFunc("POST /api/table/{name}/vacuum-prediction/run", h.RunVacuumPrediction)
 	mux.HandleFunc("GET /api/table/{name}/find", h.FindRow)
 	mux.HandleFunc("GET /api/table/{name}/indexed-columns", h.GetIndexedColumns)
+	mux.HandleFunc("POST /api/table/{name}/hypothetical-index", h.EstimateIndex)
+	mux.HandleFunc("GET /api/table/{name}/index-advice", h.GetTableIndexAdvice)
+	mux.HandleFunc("GET /api/table/{name}/row-locks", h.GetRowLocks)
 	mux.HandleFunc("POST /api/table/{name}/demo/update", h.DemoUpdate)
+	mux.HandleFunc("POST /api/table/{name}/demo/insert", h.DemoInsert)
+	mux.HandleFunc("POST /api/table/{name}/demo/delete", h.DemoDelete)
+	mux.HandleFunc("POST /api/table/{name}/demo/prune", h.DemoPrune)
+	mux.HandleFunc("POST /api/table/{name}/demo/savepoints", h.SavepointScenario)
+	mux.HandleFunc("POST /api/table/{name}/experiment/updates", h.UpdateExperiment)
 	mux.HandleFunc("GET /api/table/{name}/demo/row", h.DemoGetRow)
+	mux.HandleFunc("POST /api/table/{name}/demo/multixact", h.DemoMultiXact)
+	mux.HandleFunc("POST /api/table/{name}/demo/lock", h.DemoLock)
+	mux.HandleFunc("POST /api/table/{name}/demo/maintenance", h.DemoMaintenance)
 
 	mux.HandleFunc("GET /api/mvcc", h.GetMVCCInfo)
+	mux.HandleFunc("GET /api/mvcc/horizon", h.GetCleanupHorizon)
+
+	mux.HandleFunc("GET /api/sessions", h.ListSessions)
+	mux.HandleFunc("POST /api/sessions", h.OpenSession)
+	mux.HandleFunc("POST /api/sessions/{session}/exec", h.ExecSession)
+	mux.HandleFunc("DELETE /api/sessions/{session}", h.CloseSession)
 
 	mux.HandleFunc("GET /htmx/mvcc", h.HTMXMVCCInfo)
 	mux.HandleFunc("GET /htmx/tables", h.HTMXTableList)
@@ -54,7 +81,7 @@ func spaHandler(webFS embed.FS) http.HandlerFunc {
 func CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 		if r.Method == "OPTIONS" {
 			return
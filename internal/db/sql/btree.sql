@@ -16,7 +16,7 @@ WHERE am.amname = 'btree'
 ORDER BY c.relname
 
 -- name: bt-metap
-SELECT magic, version, root, level, fastroot, fastlevel FROM bt_metap($1)
+SELECT magic, version, root, level, fastroot, fastlevel, allequalimage FROM bt_metap($1)
 
 -- name: bt-page-stats
 SELECT blkno, type::text, live_items, dead_items, avg_item_size,
@@ -28,17 +28,108 @@ SELECT itemoffset, ctid::text, itemlen, nulls, vars, data,
        COALESCE(dead, false), htid::text, tids::text
 FROM bt_page_items($1, $2)
 
+-- name: index-attr-types
+SELECT pg_get_indexdef(a.attrelid, a.attnum, true), t.typname, t.typlen, t.typalign::text
+FROM pg_attribute a
+JOIN pg_type t ON t.oid = a.atttypid
+WHERE a.attrelid = $1::regclass
+  AND a.attnum > 0
+ORDER BY a.attnum
+
 -- name: pgstatindex
 SELECT version, tree_level, index_size, root_block_no, internal_pages,
        leaf_pages, empty_pages, deleted_pages,
        avg_leaf_density, leaf_fragmentation
 FROM pgstatindex($1)
 
--- name: index-page-count
-SELECT relpages FROM pg_class WHERE relname = $1
-
 -- name: all-page-stats
 SELECT s.blkno, s.type::text, s.live_items, s.dead_items, s.avg_item_size,
        s.page_size, s.free_size, s.btpo_prev, s.btpo_next, s.btpo_level, s.btpo_flags
 FROM generate_series(1, $2 - 1) AS g(n)
 CROSS JOIN LATERAL bt_page_stats($1, g.n) AS s
+
+-- name: index-build-options
+SELECT
+    COALESCE((SELECT option_value::int FROM pg_options_to_table(c.reloptions)
+              WHERE option_name = 'fillfactor'), 90) as fillfactor,
+    COALESCE((SELECT option_value::bool FROM pg_options_to_table(c.reloptions)
+              WHERE option_name = 'deduplicate_items'), true) as deduplicate_items,
+    ix.indisunique as is_unique,
+    ix.indnatts <> ix.indnkeyatts as has_include
+FROM pg_class c
+JOIN pg_index ix ON ix.indexrelid = c.oid
+WHERE c.oid = $1::regclass
+
+-- name: bt-leaf-items
+SELECT s.blkno, s.btpo_prev, s.btpo_next, i.itemlen, i.nulls, i.data,
+       COALESCE(i.dead, false), COALESCE(cardinality(i.tids), 0)
+FROM unnest($2::int[]) AS g(n)
+CROSS JOIN LATERAL bt_page_stats($1, g.n) AS s
+CROSS JOIN LATERAL bt_page_items($1, g.n) AS i
+WHERE s.type IN ('l', 'r')
+  AND s.btpo_level = 0
+  AND NOT (s.btpo_next <> 0 AND i.itemoffset = 1)
+ORDER BY s.blkno, i.itemoffset
+
+-- name: index-usage
+SELECT s.indexrelname, s.relname, s.idx_scan, s.idx_tup_read, s.idx_tup_fetch
+FROM pg_stat_user_indexes s
+WHERE s.schemaname = 'public'
+  AND ($1::text = '' OR s.relname::text = $1::text)
+ORDER BY s.relname, s.indexrelname
+
+-- name: stats-reset
+SELECT COALESCE(stats_reset::text, '')
+FROM pg_stat_database
+WHERE datname = current_database()
+
+-- name: bt-leaf-tids
+SELECT s.blkno, COALESCE(i.htid::text, ''), COALESCE(i.tids::text, '')
+FROM generate_series(1, $2 - 1) AS g(n)
+CROSS JOIN LATERAL bt_page_stats($1, g.n) AS s
+CROSS JOIN LATERAL bt_page_items($1, g.n) AS i
+WHERE s.type IN ('l', 'r')
+  AND s.btpo_level = 0
+  AND i.htid IS NOT NULL
+
+-- name: index-block-count
+SELECT (pg_relation_size($1::regclass) / current_setting('block_size')::int)::int
+
+-- name: bt-high-keys
+SELECT s.blkno, i.data, i.nulls
+FROM generate_series(1, $2 - 1) AS g(n)
+CROSS JOIN LATERAL bt_page_stats($1, g.n) AS s
+CROSS JOIN LATERAL bt_page_items($1, g.n) AS i
+WHERE s.type <> 'd'
+  AND s.btpo_next <> 0
+  AND i.itemoffset = 1
+
+-- name: scratch-index-twin
+SELECT c.relname
+FROM pg_index o
+JOIN pg_index s ON s.indrelid = $2::regclass
+  AND s.indkey::text = o.indkey::text
+  AND s.indclass::text = o.indclass::text
+  AND s.indoption::text = o.indoption::text
+  AND s.indisunique = o.indisunique
+  AND COALESCE(pg_get_expr(s.indexprs, s.indrelid), '') = COALESCE(pg_get_expr(o.indexprs, o.indrelid), '')
+  AND COALESCE(pg_get_expr(s.indpred, s.indrelid), '') = COALESCE(pg_get_expr(o.indpred, o.indrelid), '')
+JOIN pg_class c ON c.oid = s.indexrelid
+WHERE o.indexrelid = $1::regclass
+LIMIT 1
+
+-- name: scratch-other-pkey
+SELECT conname
+FROM pg_constraint
+WHERE conrelid = $1::regclass
+  AND contype = 'p'
+  AND conindid <> $2::regclass
+
+-- name: scratch-not-null-columns
+SELECT quote_ident(attname)
+FROM pg_attribute
+WHERE attrelid = $1::regclass
+  AND attnum > 0
+  AND NOT attisdropped
+  AND attnotnull
+  AND attidentity = ''
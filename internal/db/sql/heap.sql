@@ -41,6 +41,28 @@ WHERE c.relname = $1
   AND NOT a.attisdropped
 ORDER BY a.attnum
 
+-- name: column-stats
+SELECT
+    a.attname as name,
+    t.typname as type_name,
+    t.typlen as type_len,
+    t.typalign::text as type_align,
+    COALESCE(s.avg_width, CASE WHEN t.typlen > 0 THEN t.typlen ELSE 32 END) as avg_width,
+    COALESCE(s.null_frac, 0)::float8 as null_frac,
+    COALESCE(s.n_distinct, 0)::float8 as n_distinct
+FROM pg_class c
+JOIN pg_namespace n ON c.relnamespace = n.oid
+JOIN pg_attribute a ON a.attrelid = c.oid
+JOIN pg_type t ON t.oid = a.atttypid
+LEFT JOIN pg_stats s ON s.schemaname = n.nspname
+    AND s.tablename = c.relname
+    AND s.attname = a.attname
+WHERE c.relname = $1
+  AND n.nspname = 'public'
+  AND a.attnum > 0
+  AND NOT a.attisdropped
+ORDER BY a.attnum
+
 -- name: table-indexes
 SELECT
     c.relname as index_name,
@@ -55,7 +77,40 @@ SELECT
         FROM unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord)
         JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
         ORDER BY k.ord
-    ) as columns
+    ) as columns,
+    ARRAY(
+        SELECT pg_get_indexdef(ix.indexrelid, k.ord, true)
+        FROM generate_series(1, ix.indnatts::int) AS k(ord)
+        ORDER BY k.ord
+    ) as elements,
+    ix.indnkeyatts::int as key_count,
+    pg_get_indexdef(ix.indexrelid) as definition,
+    ARRAY(
+        SELECT pg_get_indexdef(ix.indexrelid, k.ord, true)
+        FROM generate_series(1, ix.indnatts::int) AS k(ord)
+        WHERE ix.indkey[k.ord - 1] = 0
+        ORDER BY k.ord
+    ) as expressions,
+    COALESCE(pg_get_expr(ix.indpred, ix.indrelid, true), '') as predicate,
+    ARRAY(
+        SELECT opc.opcname::text
+        FROM unnest(ix.indclass::oid[]) WITH ORDINALITY AS o(oid, ord)
+        JOIN pg_opclass opc ON opc.oid = o.oid
+        ORDER BY o.ord
+    ) as opclasses,
+    ARRAY(
+        SELECT COALESCE(coll.collname::text, '')
+        FROM unnest(ix.indcollation::oid[]) WITH ORDINALITY AS o(oid, ord)
+        LEFT JOIN pg_collation coll ON coll.oid = o.oid
+        ORDER BY o.ord
+    ) as collations,
+    ARRAY(
+        SELECT CASE WHEN o.opt & 1 = 1 THEN 'DESC' ELSE 'ASC' END ||
+               CASE WHEN o.opt & 2 = 2 THEN ' NULLS FIRST' ELSE ' NULLS LAST' END
+        FROM unnest(ix.indoption::int2[]) WITH ORDINALITY AS o(opt, ord)
+        WHERE pg_indexam_has_property(am.oid, 'can_order')
+        ORDER BY o.ord
+    ) as sort_order
 FROM pg_class c
 JOIN pg_index ix ON c.oid = ix.indexrelid
 JOIN pg_class t ON ix.indrelid = t.oid
@@ -92,7 +147,11 @@ SELECT
     COALESCE(COUNT(*) FILTER (WHERE lp_flags = 1 AND COALESCE(t_xmax::text::bigint, 0) = 0), 0) as live_tuples,
     COALESCE(COUNT(*) FILTER (WHERE lp_flags = 1 AND COALESCE(t_xmax::text::bigint, 0) != 0), 0) as dead_tuples,
     COALESCE(SUM(CASE WHEN lp_flags = 1 THEN lp_len ELSE 0 END), 0) as used_space,
-    COUNT(*) as lp_count
+    COUNT(*) as lp_count,
+    COUNT(*) FILTER (WHERE lp_flags = 1 AND (t_infomask & 768) <> 768 AND t_xmin::text::bigint > 2) as unfrozen_tuples,
+    COALESCE(MAX(age(t_xmin)) FILTER (WHERE lp_flags = 1 AND (t_infomask & 768) <> 768 AND t_xmin::text::bigint > 2), 0) as oldest_xmin_age,
+    COALESCE((ARRAY_AGG(t_xmin::text::bigint ORDER BY age(t_xmin) DESC)
+        FILTER (WHERE lp_flags = 1 AND (t_infomask & 768) <> 768 AND t_xmin::text::bigint > 2))[1], 0) as oldest_xmin
 FROM heap_page_items(get_raw_page($1, $2))
 
 -- name: primary-key-column
@@ -112,7 +171,11 @@ FROM pg_index idx
 JOIN pg_class i ON i.oid = idx.indexrelid
 JOIN pg_class t ON t.oid = idx.indrelid
 JOIN pg_namespace n ON n.oid = t.relnamespace
-JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = ANY(idx.indkey)
+JOIN pg_attribute a ON a.attrelid = t.oid
+  AND (a.attnum = ANY(idx.indkey)
+       OR a.attnum IN (SELECT d.refobjsubid FROM pg_depend d
+                       WHERE d.classid = 'pg_class'::regclass AND d.objid = idx.indexrelid
+                         AND d.refclassid = 'pg_class'::regclass AND d.refobjid = t.oid))
 WHERE t.relname = $1
   AND n.nspname = 'public'
   AND a.attnum > 0
@@ -125,3 +188,151 @@ JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
 JOIN pg_class c ON c.oid = i.indrelid
 WHERE c.relname = $1 AND i.indisprimary
 LIMIT 1
+
+-- name: column-type
+SELECT pg_catalog.format_type(a.atttypid, a.atttypmod), t.typcategory::text
+FROM pg_attribute a
+JOIN pg_type t ON t.oid = a.atttypid
+WHERE a.attrelid = $1::regclass
+  AND a.attname = $2
+  AND NOT a.attisdropped
+
+-- name: current-snapshot
+SELECT pg_current_snapshot()::text
+
+-- name: xact-status
+SELECT x::bigint, pg_xact_status(x::xid8)
+FROM unnest($1::text[]) AS x
+
+-- name: multixact-members
+SELECT xid::text::bigint, mode
+FROM pg_get_multixact_members($1::text::xid)
+
+-- name: track-commit-timestamp
+SELECT current_setting('track_commit_timestamp')
+
+-- name: commit-timestamps
+SELECT x::bigint, pg_xact_commit_timestamp((x::bigint % 4294967296)::text::xid)
+FROM unnest($1::text[]) AS x
+
+-- name: current-xact-id
+SELECT pg_current_xact_id()::text::bigint
+
+-- name: row-locks
+SELECT locked_row::text, locker::text::bigint, multi,
+       xids::text[]::bigint[], modes, pids
+FROM pgrowlocks($1)
+
+-- name: table-freeze-info
+SELECT
+    c.relfrozenxid::text::bigint,
+    age(c.relfrozenxid),
+    c.relminmxid::text::bigint,
+    mxid_age(c.relminmxid),
+    COALESCE((SELECT option_value::bigint FROM pg_options_to_table(c.reloptions)
+              WHERE option_name = 'autovacuum_freeze_max_age'),
+             current_setting('autovacuum_freeze_max_age')::bigint),
+    COALESCE((SELECT option_value::bigint FROM pg_options_to_table(c.reloptions)
+              WHERE option_name = 'autovacuum_multixact_freeze_max_age'),
+             current_setting('autovacuum_multixact_freeze_max_age')::bigint),
+    COALESCE((SELECT option_value::bigint FROM pg_options_to_table(c.reloptions)
+              WHERE option_name = 'autovacuum_freeze_min_age'),
+             current_setting('vacuum_freeze_min_age')::bigint),
+    COALESCE((SELECT option_value::bigint FROM pg_options_to_table(c.reloptions)
+              WHERE option_name = 'autovacuum_freeze_table_age'),
+             current_setting('vacuum_freeze_table_age')::bigint),
+    current_setting('vacuum_failsafe_age', true)::bigint
+FROM pg_class c
+WHERE c.oid = $1::regclass
+
+-- name: table-block-count
+SELECT pg_relation_size($1::regclass) / current_setting('block_size')::int
+
+-- name: heap-page-header
+SELECT flags, lower, upper, COALESCE(prune_xid::text::bigint, 0)
+FROM page_header(get_raw_page($1, $2))
+
+-- name: table-storage-info
+SELECT pg_relation_filenode(c.oid), c.relpages, c.relallvisible,
+       c.relfrozenxid::text::bigint, age(c.relfrozenxid)
+FROM pg_class c
+WHERE c.oid = $1::regclass
+
+-- name: relation-filenode
+SELECT pg_relation_filenode($1::regclass), pg_relation_size($1::regclass)
+
+-- name: table-fillfactor
+SELECT COALESCE((SELECT option_value::int FROM pg_options_to_table(c.reloptions)
+                 WHERE option_name = 'fillfactor'), 100)
+FROM pg_class c
+WHERE c.oid = $1::regclass
+
+-- name: fsm-page-avail
+SELECT pg_freespace($1::regclass, $2)
+
+-- name: index-column-usage
+SELECT c.relname, am.amname, a.attname, a.attnum = ANY(ix.indkey) AS direct
+FROM pg_index ix
+JOIN pg_class c ON c.oid = ix.indexrelid
+JOIN pg_am am ON am.oid = c.relam
+JOIN pg_attribute a ON a.attrelid = ix.indrelid AND a.attnum > 0 AND NOT a.attisdropped
+WHERE ix.indrelid = $1::regclass
+  AND (a.attnum = ANY(ix.indkey) OR EXISTS (
+        SELECT 1 FROM pg_depend d
+        WHERE d.classid = 'pg_class'::regclass AND d.objid = ix.indexrelid
+          AND d.refclassid = 'pg_class'::regclass AND d.refobjid = ix.indrelid
+          AND d.refobjsubid = a.attnum))
+ORDER BY c.relname, a.attnum
+
+-- name: column-type-categories
+SELECT a.attname, t.typcategory::text
+FROM pg_attribute a
+JOIN pg_type t ON t.oid = a.atttypid
+WHERE a.attrelid = $1::regclass
+  AND a.attnum > 0
+  AND NOT a.attisdropped
+
+-- name: xact-update-counts
+SELECT pg_stat_get_xact_tuples_updated($1::regclass), pg_stat_get_xact_tuples_hot_updated($1::regclass)
+
+-- name: heap-lp-summary
+SELECT count(*),
+       count(*) FILTER (WHERE lp_flags = 1),
+       count(*) FILTER (WHERE lp_flags = 2),
+       count(*) FILTER (WHERE lp_flags = 3),
+       count(*) FILTER (WHERE lp_flags = 0)
+FROM generate_series(0, pg_relation_size($1::text::regclass) / current_setting('block_size')::int - 1) AS b,
+     heap_page_items(get_raw_page($1::text, b::int))
+
+-- name: heap-free-summary
+SELECT count(*), COALESCE(sum(h.upper - h.lower), 0), COALESCE(min(h.upper - h.lower), 0)
+FROM generate_series(0, pg_relation_size($1::text::regclass) / current_setting('block_size')::int - 1) AS b,
+     page_header(get_raw_page($1::text, b::int)) h
+
+-- name: table-attr-types
+SELECT a.attname, a.attisdropped, COALESCE(t.typname, ''), a.attlen, a.attalign::text
+FROM pg_attribute a
+LEFT JOIN pg_type t ON t.oid = a.atttypid
+WHERE a.attrelid = $1::regclass
+  AND a.attnum > 0
+ORDER BY a.attnum
+
+-- name: heap-range-item-attrs
+SELECT b::int, h.lp, h.t_attrs
+FROM generate_series($2::int, $3::int) AS b,
+     heap_page_item_attrs(get_raw_page($1::text, b::int), $1::text::regclass) h
+WHERE h.lp_flags = 1
+ORDER BY b, h.lp
+
+-- name: heap-range-items
+SELECT b::int, h.lp, COALESCE(h.lp_off, 0), COALESCE(h.lp_flags, 0), COALESCE(h.lp_len, 0),
+       COALESCE(h.t_xmin::text::bigint, 0), COALESCE(h.t_xmax::text::bigint, 0), COALESCE(h.t_ctid::text, ''),
+       COALESCE(h.t_infomask, 0), COALESCE(h.t_infomask2, 0), h.t_attrs
+FROM generate_series($2::int, $3::int) AS b,
+     heap_page_item_attrs(get_raw_page($1::text, b::int), $1::text::regclass) h
+ORDER BY b, h.lp
+
+-- name: heap-page-cids
+SELECT lp, COALESCE(t_field3, 0)
+FROM heap_page_items(get_raw_page($1, $2))
+WHERE lp_flags = 1
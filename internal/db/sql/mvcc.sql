@@ -1,8 +1,36 @@
--- name: txid-current
-SELECT txid_current()
-
--- name: txid-current-snapshot
-SELECT txid_current_snapshot()::text
+-- name: mvcc-info
+SELECT pg_current_snapshot()::text,
+       age(datfrozenxid),
+       mxid_age(datminmxid)
+FROM pg_database
+WHERE datname = current_database()
 
 -- name: extension-exists
 SELECT EXISTS(SELECT 1 FROM pg_extension WHERE extname = $1)
+
+-- name: horizon-backends
+SELECT pid, COALESCE(usename::text, ''), COALESCE(application_name, ''), COALESCE(state, ''),
+       COALESCE(backend_type, ''), backend_xid::text::bigint, backend_xmin::text::bigint,
+       xact_start, COALESCE(left(query, 200), ''),
+       COALESCE(datname = current_database(), false)
+FROM pg_stat_activity
+WHERE (backend_xid IS NOT NULL OR backend_xmin IS NOT NULL)
+  AND pid <> pg_backend_pid()
+
+-- name: horizon-slots
+SELECT slot_name::text, slot_type, active,
+       xmin::text::bigint, catalog_xmin::text::bigint
+FROM pg_replication_slots
+WHERE xmin IS NOT NULL OR catalog_xmin IS NOT NULL
+
+-- name: horizon-prepared
+SELECT gid, transaction::text::bigint, prepared, owner::text,
+       database = current_database()
+FROM pg_prepared_xacts
+
+-- name: session-state
+SELECT pg_backend_pid(), COALESCE(pg_current_xact_id_if_assigned()::text::bigint, 0),
+       current_setting('transaction_isolation')
+
+-- name: server-version-num
+SELECT current_setting('server_version_num')::int
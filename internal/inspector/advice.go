@@ -0,0 +1,245 @@
+package inspector
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	indexBloatHigh = 30.0
+
+	// largest btree indexes that get a bloat estimate
+	adviceBloatCandidates = 10
+)
+
+type indexUsageStats struct {
+	scan, tupRead, tupFetch int64
+}
+
+// GetIndexAdvice combines usage statistics with index structure for one
+// table, or for the whole schema when table is empty.
+func (i *Inspector) GetIndexAdvice(ctx context.Context, table string) (*IndexAdvice, error) {
+	var tables []string
+	if table != "" {
+		tables = []string{table}
+	} else {
+		all, err := i.ListTables(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range all {
+			tables = append(tables, t.Name)
+		}
+	}
+
+	usage, err := i.getIndexUsage(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &IndexAdvice{Scope: "database", Indexes: []IndexUsage{}, Findings: []IndexFinding{}}
+	if table != "" {
+		out.Scope = table
+	}
+	if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("stats-reset").Query()).Scan(&out.StatsReset); err != nil {
+		return nil, fmt.Errorf("stats reset: %w", err)
+	}
+
+	type tableIndexes struct {
+		indexes []IndexInfo
+		usages  []IndexUsage
+	}
+	var all []tableIndexes
+	var btrees []*IndexUsage
+	for _, t := range tables {
+		indexes, err := i.GetTableIndexes(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		usages := make([]IndexUsage, len(indexes))
+		for j, idx := range indexes {
+			u := usage[idx.Name]
+			usages[j] = IndexUsage{
+				Name:        idx.Name,
+				TableName:   idx.TableName,
+				IndexType:   idx.IndexType,
+				Definition:  idx.Definition,
+				Size:        idx.Size,
+				IsUnique:    idx.IsUnique,
+				IsPrimary:   idx.IsPrimary,
+				IdxScan:     u.scan,
+				IdxTupRead:  u.tupRead,
+				IdxTupFetch: u.tupFetch,
+			}
+			if idx.IndexType == "btree" {
+				btrees = append(btrees, &usages[j])
+			}
+		}
+		all = append(all, tableIndexes{indexes, usages})
+	}
+
+	// the bloat estimate reads the index, so only the largest ones get it
+	slices.SortStableFunc(btrees, func(a, b *IndexUsage) int { return cmp.Compare(b.Size, a.Size) })
+	for _, u := range btrees[:min(len(btrees), adviceBloatCandidates)] {
+		u.BloatChecked = true
+		b, err := i.GetBloatInfo(ctx, u.Name)
+		if err != nil {
+			u.BloatError = err.Error()
+			continue
+		}
+		u.EstimatedBloat = b.EstimatedBloat
+		u.BloatBytes = b.WastedBytes
+	}
+	out.BloatSkipped = max(0, len(btrees)-adviceBloatCandidates)
+
+	for _, t := range all {
+		out.Indexes = append(out.Indexes, t.usages...)
+		out.Findings = append(out.Findings, indexFindings(t.indexes, t.usages)...)
+	}
+
+	wasted := make(map[string]int64)
+	for _, f := range out.Findings {
+		if f.WastedBytes > wasted[f.Index] {
+			wasted[f.Index] = f.WastedBytes
+		}
+	}
+	for _, w := range wasted {
+		out.TotalWastedBytes += w
+	}
+	return out, nil
+}
+
+func (i *Inspector) getIndexUsage(ctx context.Context, table string) (map[string]indexUsageStats, error) {
+	rows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("index-usage").Query(), table)
+	if err != nil {
+		return nil, fmt.Errorf("index usage: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]indexUsageStats)
+	for rows.Next() {
+		var name, rel string
+		var u indexUsageStats
+		if err := rows.Scan(&name, &rel, &u.scan, &u.tupRead, &u.tupFetch); err != nil {
+			return nil, fmt.Errorf("scan index usage: %w", err)
+		}
+		out[name] = u
+	}
+	return out, rows.Err()
+}
+
+// indexFindings flags the indexes of a single table
+func indexFindings(indexes []IndexInfo, usages []IndexUsage) []IndexFinding {
+	var out []IndexFinding
+
+	for j, idx := range indexes {
+		u := usages[j]
+		if u.IdxScan == 0 && !idx.IsUnique && !idx.IsPrimary {
+			out = append(out, IndexFinding{
+				Kind:        "unused",
+				Index:       idx.Name,
+				TableName:   idx.TableName,
+				WastedBytes: idx.Size,
+				Detail:      "never used by a scan since statistics were reset",
+			})
+		}
+		if u.EstimatedBloat >= indexBloatHigh {
+			out = append(out, IndexFinding{
+				Kind:        "bloated",
+				Index:       idx.Name,
+				TableName:   idx.TableName,
+				WastedBytes: u.BloatBytes,
+				Detail:      fmt.Sprintf("REINDEX would shrink it by %.1f%%", u.EstimatedBloat),
+			})
+		}
+	}
+
+	// exact duplicates: keep the constraint index, then the most used one
+	redundant := make(map[int]bool)
+	for a := range indexes {
+		for b := range indexes {
+			if a == b || redundant[b] || indexSignature(indexes[a]) != indexSignature(indexes[b]) {
+				continue
+			}
+			if !preferIndex(indexes[b], usages[b], indexes[a], usages[a]) {
+				continue
+			}
+			redundant[a] = true
+			out = append(out, IndexFinding{
+				Kind:         "duplicate",
+				Index:        indexes[a].Name,
+				TableName:    indexes[a].TableName,
+				RelatedIndex: indexes[b].Name,
+				WastedBytes:  indexes[a].Size,
+				Detail:       fmt.Sprintf("same definition as %s", indexes[b].Name),
+			})
+			break
+		}
+	}
+
+	// prefix overlap: (a) is covered by (a, b) unless it enforces uniqueness
+	for a, ia := range indexes {
+		if redundant[a] || ia.IsUnique || ia.IsPrimary || ia.IndexType != "btree" {
+			continue
+		}
+		for b, ib := range indexes {
+			if a == b || ib.IndexType != "btree" || ia.Predicate != ib.Predicate {
+				continue
+			}
+			n := len(ia.KeyColumns)
+			if n == 0 || n >= len(ib.KeyColumns) ||
+				!slices.Equal(ia.KeyColumns, ib.KeyColumns[:n]) ||
+				!prefixEqual(ia.Opclasses, ib.Opclasses, n) ||
+				!prefixEqual(ia.Collations, ib.Collations, n) {
+				continue
+			}
+			out = append(out, IndexFinding{
+				Kind:         "overlapping",
+				Index:        ia.Name,
+				TableName:    ia.TableName,
+				RelatedIndex: ib.Name,
+				WastedBytes:  ia.Size,
+				Detail: fmt.Sprintf("(%s) is a prefix of %s (%s)",
+					strings.Join(ia.KeyColumns, ", "), ib.Name, strings.Join(ib.KeyColumns, ", ")),
+			})
+			break
+		}
+	}
+
+	return out
+}
+
+func indexSignature(idx IndexInfo) string {
+	return strings.Join([]string{
+		idx.IndexType,
+		strings.Join(idx.KeyColumns, ","),
+		strings.Join(idx.IncludeColumns, ","),
+		strings.Join(idx.Opclasses, ","),
+		strings.Join(idx.Collations, ","),
+		strings.Join(idx.SortOrder, ","),
+		idx.Predicate,
+	}, "|")
+}
+
+// preferIndex reports whether a should be kept over b
+func preferIndex(a IndexInfo, ua IndexUsage, b IndexInfo, ub IndexUsage) bool {
+	switch {
+	case a.IsPrimary != b.IsPrimary:
+		return a.IsPrimary
+	case a.IsUnique != b.IsUnique:
+		return a.IsUnique
+	case ua.IdxScan != ub.IdxScan:
+		return ua.IdxScan > ub.IdxScan
+	}
+	return a.Name < b.Name
+}
+
+func prefixEqual(a, b []string, n int) bool {
+	if len(a) < n || len(b) < n {
+		return len(a) == len(b)
+	}
+	return slices.Equal(a[:n], b[:n])
+}
@@ -0,0 +1,108 @@
+package inspector
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestRowTimeline(t *testing.T) {
+	attrs := []heapAttr{
+		{col: columnStats{name: "id"}},
+		{dropped: true, col: columnStats{name: "........pg.dropped.2........"}},
+		{col: columnStats{name: "note"}},
+	}
+	val := func(s string) *string { return &s }
+	ver := func(tid string, xmin, xmax int64, ctid string, note *string) RowVersion {
+		return RowVersion{
+			TID: tid, Xmin: xmin, Xmax: xmax, Ctid: ctid,
+			Attrs: map[string]*string{
+				"id":                           val("1"),
+				"........pg.dropped.2........": val(tid),
+				"note":                         note,
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		versions []RowVersion
+		order    []string
+		links    []string // previous→next per version, in output order
+		changes  []string // column:old→new per version, in output order
+	}{
+		{
+			name: "update chain out of scan order",
+			versions: []RowVersion{
+				ver("(1,4)", 102, 0, "(1,4)", val("c")),
+				ver("(0,1)", 100, 101, "(0,2)", val("a")),
+				ver("(0,2)", 101, 102, "(1,4)", nil),
+			},
+			order:   []string{"(0,1)", "(0,2)", "(1,4)"},
+			links:   []string{"→(0,2)", "(0,1)→(1,4)", "(0,2)→"},
+			changes: []string{"", "note:a→NULL", "note:NULL→c"},
+		},
+		{
+			name: "deleted and inserted again",
+			versions: []RowVersion{
+				ver("(0,5)", 200, 0, "(0,5)", val("b")),
+				ver("(0,1)", 100, 150, "(0,1)", val("a")),
+			},
+			order:   []string{"(0,1)", "(0,5)"},
+			links:   []string{"→", "→"},
+			changes: []string{"", "note:a→b"},
+		},
+		{
+			name: "slot reused by a later insert",
+			versions: []RowVersion{
+				ver("(0,1)", 100, 101, "(0,2)", val("a")),
+				ver("(0,2)", 300, 0, "(0,2)", val("a")),
+			},
+			order:   []string{"(0,1)", "(0,2)"},
+			links:   []string{"→", "→"},
+			changes: []string{"", ""},
+		},
+		{
+			name: "two versions claim one successor",
+			versions: []RowVersion{
+				ver("(0,1)", 100, 101, "(0,3)", val("a")),
+				ver("(0,2)", 99, 101, "(0,3)", val("b")),
+				ver("(0,3)", 101, 0, "(0,3)", val("b")),
+			},
+			order:   []string{"(0,2)", "(0,1)", "(0,3)"},
+			links:   []string{"→", "→(0,3)", "(0,1)→"},
+			changes: []string{"", "note:b→a", "note:a→b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rowTimeline(tt.versions, attrs)
+			var order, links, changes []string
+			for _, v := range got {
+				order = append(order, v.TID)
+				links = append(links, v.Previous+"→"+v.Next)
+				c := ""
+				for _, ch := range v.Changes {
+					c += fmt.Sprintf("%s:%s→%s", ch.Column, nullable(ch.Old), nullable(ch.New))
+				}
+				changes = append(changes, c)
+			}
+			if !slices.Equal(order, tt.order) {
+				t.Errorf("order = %v, want %v", order, tt.order)
+			}
+			if !slices.Equal(links, tt.links) {
+				t.Errorf("links = %v, want %v", links, tt.links)
+			}
+			if !slices.Equal(changes, tt.changes) {
+				t.Errorf("changes = %q, want %q", changes, tt.changes)
+			}
+		})
+	}
+}
+
+func nullable(s *string) string {
+	if s == nil {
+		return "NULL"
+	}
+	return *s
+}
@@ -10,12 +10,13 @@ import (
 )
 
 type Inspector struct {
-	pool *pgxpool.Pool
-	qs   *queries.QueryStore
+	pool     *pgxpool.Pool
+	qs       *queries.QueryStore
+	sessions *sessionManager
 }
 
 func New(pool *pgxpool.Pool, qs *queries.QueryStore) *Inspector {
-	return &Inspector{pool: pool, qs: qs}
+	return &Inspector{pool: pool, qs: qs, sessions: newSessionManager()}
 }
 
 func (i *Inspector) ListIndexes(ctx context.Context) ([]IndexInfo, error) {
@@ -40,7 +41,7 @@ func (i *Inspector) ListIndexes(ctx context.Context) ([]IndexInfo, error) {
 func (i *Inspector) GetMeta(ctx context.Context, indexName string) (*BTreeMeta, error) {
 	var m BTreeMeta
 	err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("bt-metap").Query(), indexName).Scan(
-		&m.Magic, &m.Version, &m.Root, &m.Level, &m.FastRoot, &m.FastLevel,
+		&m.Magic, &m.Version, &m.Root, &m.Level, &m.FastRoot, &m.FastLevel, &m.AllequalImage,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("bt_metap %s: %w", indexName, err)
@@ -95,7 +96,20 @@ func (i *Inspector) GetPageDetail(ctx context.Context, indexName string, blockNo
 	if err != nil {
 		return nil, err
 	}
-	return &PageDetail{Stats: *stats, Items: items}, nil
+
+	cols, err := i.getIndexAttrTypes(ctx, indexName)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(cols))
+	for j, c := range cols {
+		names[j] = c.name
+	}
+	for idx := range items {
+		items[idx].Keys = decodeIndexKey(cols, items[idx].Data, items[idx].Nulls)
+	}
+
+	return &PageDetail{Stats: *stats, Columns: names, Items: items}, nil
 }
 
 func (i *Inspector) GetIndexStats(ctx context.Context, indexName string) (*IndexStats, error) {
@@ -119,7 +133,7 @@ func (i *Inspector) GetIndexStats(ctx context.Context, indexName string) (*Index
 
 func (i *Inspector) GetAllPageStats(ctx context.Context, indexName string) ([]PageStats, error) {
 	var numPages int
-	err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("index-page-count").Query(), indexName).Scan(&numPages)
+	err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("index-block-count").Query(), indexName).Scan(&numPages)
 	if err != nil {
 		return nil, fmt.Errorf("page count %s: %w", indexName, err)
 	}
@@ -183,22 +197,31 @@ func (i *Inspector) GetBloatInfo(ctx context.Context, indexName string) (*BloatI
 		return &BloatInfo{IndexName: indexName, RecommendAction: "ok"}, nil
 	}
 
-	wasted := stats.EmptyPages + stats.DeletedPages
-
-	// estimate waste from low density (well-packed should be ~90%)
-	densityWaste := 0.0
-	if stats.AvgLeafDensity < 90 && stats.LeafPages > 0 {
-		densityWaste = (90 - stats.AvgLeafDensity) / 100.0 * float64(stats.LeafPages)
+	est, err := i.SimulateReindex(ctx, indexName, stats.IndexSize)
+	if err != nil {
+		return nil, err
 	}
 
-	bloat := (float64(wasted) + densityWaste) / float64(total) * 100
-	wastedBytes := int64(float64(stats.IndexSize) * bloat / 100)
+	current := stats.IndexSize / pageSize
+	bloat := 0.0
+	if est.PagesDiff > 0 && current > 0 {
+		bloat = float64(est.PagesDiff) / float64(current) * 100
+	}
+	wastedBytes := est.BytesDiff
+	if wastedBytes < 0 {
+		wastedBytes = 0
+	}
 
+	// when empty and deleted pages account for all of the excess, the pages
+	// in use are as dense as a rebuild would make them: VACUUM puts those
+	// pages in the free space map for later splits, so the index stops
+	// growing, but the file never shrinks. Only REINDEX gives space back.
 	action := "ok"
-	if bloat >= 30 {
+	if bloat >= 10 {
 		action = "reindex"
-	} else if bloat >= 10 {
-		action = "vacuum"
+		if stats.EmptyPages+stats.DeletedPages >= est.PagesDiff {
+			action = "vacuum"
+		}
 	}
 
 	return &BloatInfo{
@@ -210,6 +233,7 @@ func (i *Inspector) GetBloatInfo(ctx context.Context, indexName string) (*BloatI
 		EstimatedBloat:  bloat,
 		WastedBytes:     wastedBytes,
 		RecommendAction: action,
+		Reindex:         est,
 	}, nil
 }
 
@@ -274,25 +298,28 @@ func (i *Inspector) buildTreeNode(ctx context.Context, indexName string, blockNo
 	return node, nil
 }
 
+// GetMVCCInfo reads the current snapshot without assigning an XID
 func (i *Inspector) GetMVCCInfo(ctx context.Context) (*MVCCInfo, error) {
-	var xid int64
-	err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("txid-current").Query()).Scan(&xid)
+	var out MVCCInfo
+	err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("mvcc-info").Query()).Scan(
+		&out.CurrentSnapshot, &out.FrozenXIDAge, &out.FrozenMXIDAge,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("txid_current: %w", err)
+		return nil, fmt.Errorf("mvcc info: %w", err)
 	}
 
-	var snapshot string
-	_ = i.pool.QueryRow(ctx, i.qs.MustHaveQuery("txid-current-snapshot").Query()).Scan(&snapshot)
-
-	var xmin, xmax int64
-	if snapshot != "" {
-		fmt.Sscanf(snapshot, "%d:%d:", &xmin, &xmax)
+	snap, err := ParseSnapshot(out.CurrentSnapshot)
+	if err != nil {
+		return nil, err
 	}
-
-	return &MVCCInfo{
-		CurrentXID:      xid,
-		CurrentSnapshot: snapshot,
-		XIDMin:          xmin,
-		XIDMax:          xmax,
-	}, nil
+	out.XIDMin, out.XIDMax = snap.Xmin, snap.Xmax
+	out.ActiveXIDs = snap.Xip
+	if out.ActiveXIDs == nil {
+		out.ActiveXIDs = []int64{}
+	}
+	out.Epoch = snap.Xmax >> 32
+	out.NextXID = snap.Xmax & 0xFFFFFFFF
+	out.WraparoundRemaining = xidWraparoundLimit - out.FrozenXIDAge
+	out.WraparoundPct = 100 * float64(out.FrozenXIDAge) / xidWraparoundLimit
+	return &out, nil
 }
@@ -0,0 +1,240 @@
+package inspector
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// on-disk datums are decoded assuming a little-endian server
+
+var pgEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// microseconds from the Unix epoch to the PostgreSQL one
+const pgEpochOffsetMicros = 946684800000000
+
+func (i *Inspector) getIndexAttrTypes(ctx context.Context, indexName string) ([]columnStats, error) {
+	rows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("index-attr-types").Query(), indexName)
+	if err != nil {
+		return nil, fmt.Errorf("index attributes %s: %w", indexName, err)
+	}
+	defer rows.Close()
+
+	var out []columnStats
+	for rows.Next() {
+		var c columnStats
+		if err := rows.Scan(&c.name, &c.typName, &c.typLen, &c.typAlign); err != nil {
+			return nil, fmt.Errorf("scan index attribute: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// decodeIndexKey splits the data of a B-tree tuple (as printed by
+// bt_page_items) into its attributes. Pivot tuples may carry fewer
+// attributes than the index has; decoding stops when the data runs out.
+func decodeIndexKey(cols []columnStats, data string, nulls bool) []string {
+	raw, err := hex.DecodeString(strings.ReplaceAll(data, " ", ""))
+	if err != nil || len(raw) == 0 {
+		return nil
+	}
+	// the null bitmap is not exposed, so we can't tell which attribute is missing
+	if nulls && len(cols) > 1 {
+		return nil
+	}
+
+	var out []string
+	off := 0
+	for _, c := range cols {
+		if off >= len(raw) {
+			break
+		}
+		var size int
+		switch {
+		case c.typLen > 0:
+			off = alignTo(off, c.typAlign)
+			size = c.typLen
+		case c.typLen == -1:
+			// a non-zero byte here is a short varlena header, zero is padding
+			if raw[off] == 0 {
+				off = alignTo(off, c.typAlign)
+			}
+			if off >= len(raw) {
+				return out
+			}
+			size = varlenaSize(raw[off:])
+		default:
+			end := off
+			for end < len(raw) && raw[end] != 0 {
+				end++
+			}
+			size = end - off + 1
+		}
+		if size <= 0 || off+size > len(raw) {
+			break
+		}
+		out = append(out, decodeAttr(c, raw[off:off+size]))
+		off += size
+	}
+	return out
+}
+
+// varlenaSize returns the total size (header included) of the varlena at b
+func varlenaSize(b []byte) int {
+	switch {
+	case b[0] == 0x01:
+		// external TOAST pointer: 2 byte header + pointer
+		if len(b) < 2 {
+			return -1
+		}
+		return 2 + toastPointerSize(b[1])
+	case b[0]&0x01 == 0x01:
+		return int(b[0] >> 1)
+	case len(b) >= 4:
+		return int(binary.LittleEndian.Uint32(b) >> 2)
+	}
+	return -1
+}
+
+func toastPointerSize(tag byte) int {
+	switch tag {
+	case 18: // VARTAG_ONDISK
+		return 16
+	default:
+		return 8
+	}
+}
+
+// decodeAttr renders one raw on-disk attribute (varlena header included)
+// as text for the common built-in types, hex otherwise.
+func decodeAttr(c columnStats, b []byte) string {
+	if c.typLen == -1 {
+		switch {
+		case len(b) == 0:
+			return ""
+		case b[0] == 0x01:
+			return "<toasted>"
+		case b[0]&0x01 == 0x01:
+			b = b[1:]
+		case len(b) >= 4 && b[0]&0x03 == 0x02:
+			return "<compressed>"
+		case len(b) >= 4:
+			b = b[4:]
+		}
+	}
+
+	switch c.typName {
+	case "int2":
+		if len(b) == 2 {
+			return strconv.Itoa(int(int16(binary.LittleEndian.Uint16(b))))
+		}
+	case "int4":
+		if len(b) == 4 {
+			return strconv.Itoa(int(int32(binary.LittleEndian.Uint32(b))))
+		}
+	case "oid", "xid", "cid":
+		if len(b) == 4 {
+			return strconv.FormatUint(uint64(binary.LittleEndian.Uint32(b)), 10)
+		}
+	case "int8":
+		if len(b) == 8 {
+			return strconv.FormatInt(int64(binary.LittleEndian.Uint64(b)), 10)
+		}
+	case "bool":
+		if len(b) == 1 {
+			return strconv.FormatBool(b[0] != 0)
+		}
+	case "float4":
+		if len(b) == 4 {
+			return strconv.FormatFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(b))), 'g', -1, 32)
+		}
+	case "float8":
+		if len(b) == 8 {
+			return strconv.FormatFloat(math.Float64frombits(binary.LittleEndian.Uint64(b)), 'g', -1, 64)
+		}
+	case "date":
+		if len(b) == 4 {
+			days := int32(binary.LittleEndian.Uint32(b))
+			switch days {
+			case math.MaxInt32:
+				return "infinity"
+			case math.MinInt32:
+				return "-infinity"
+			}
+			return pgEpoch.AddDate(0, 0, int(days)).Format("2006-01-02")
+		}
+	case "timestamp", "timestamptz":
+		if len(b) == 8 {
+			us := int64(binary.LittleEndian.Uint64(b))
+			switch us {
+			case math.MaxInt64:
+				return "infinity"
+			case math.MinInt64:
+				return "-infinity"
+			}
+			// time.Duration only spans ±292 years
+			ts := time.UnixMicro(us + pgEpochOffsetMicros).UTC().Format("2006-01-02 15:04:05.999999")
+			if c.typName == "timestamptz" {
+				ts += "+00"
+			}
+			return ts
+		}
+	case "uuid":
+		if len(b) == 16 {
+			h := hex.EncodeToString(b)
+			return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+		}
+	case "text", "varchar", "bpchar", "name", "cstring":
+		return strings.TrimRight(string(b), "\x00")
+	}
+	return `\x` + hex.EncodeToString(b)
+}
+
+// heapAttr is a table column in attribute number order, dropped ones
+// included, matching the positions in heap_page_item_attrs' t_attrs
+type heapAttr struct {
+	dropped bool
+	col     columnStats
+}
+
+func (i *Inspector) heapAttrs(ctx context.Context, table string) ([]heapAttr, error) {
+	rows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("table-attr-types").Query(), table)
+	if err != nil {
+		return nil, fmt.Errorf("table attributes %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var out []heapAttr
+	for rows.Next() {
+		var a heapAttr
+		if err := rows.Scan(&a.col.name, &a.dropped, &a.col.typName, &a.col.typLen, &a.col.typAlign); err != nil {
+			return nil, fmt.Errorf("scan table attribute: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// decodeHeapAttrs decodes t_attrs by column name. NULLs, and columns added
+// after the tuple was written, are nil.
+func decodeHeapAttrs(attrs []heapAttr, raw [][]byte) map[string]*string {
+	out := make(map[string]*string, len(attrs))
+	for j, a := range attrs {
+		if a.dropped {
+			continue
+		}
+		if j >= len(raw) || raw[j] == nil {
+			out[a.col.name] = nil
+			continue
+		}
+		v := decodeAttr(a.col, raw[j])
+		out[a.col.name] = &v
+	}
+	return out
+}
@@ -0,0 +1,209 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+const (
+	deadTupleChunk    = 64
+	deadTupleMaxPages = 1000
+	deadTupleMaxLimit = 1000
+)
+
+// GetDeadTuples lists the tuples the current snapshot can't see, plus
+// leftover line pointers, across the whole heap. A call scans at most
+// deadTupleMaxPages pages and returns at most f.Limit tuples; NextCursor
+// picks up where it stopped.
+func (i *Inspector) GetDeadTuples(ctx context.Context, table string, f DeadTupleFilter) (*DeadTupleList, error) {
+	if f.Limit <= 0 || f.Limit > deadTupleMaxLimit {
+		f.Limit = 100
+	}
+	if len(f.LPStates) == 0 {
+		f.LPStates = []string{lpFlagsStr(lpNormal), lpFlagsStr(lpDead)}
+	}
+
+	attrs, err := i.heapAttrs(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	var total int
+	if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("table-block-count").Query(), table).Scan(&total); err != nil {
+		return nil, fmt.Errorf("block count %s: %w", table, err)
+	}
+	c, err := i.newXactCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+	h, err := i.cleanupHorizon(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &DeadTupleList{Table: table, TotalPages: total, FromBlock: f.FromBlock, ToBlock: f.FromBlock, Horizon: h.Horizon, Filter: f, Tuples: []DeadTuple{}}
+	// XID bounds may be epoch-qualified, like the horizon and snapshots, or
+	// 32-bit as on the page; tuple XIDs are widened to compare with them
+	for _, x := range []*int64{&f.XminFrom, &f.XminTo, &f.XmaxFrom, &f.XmaxTo} {
+		if *x > 0 && *x < 1<<32 {
+			*x = c.ref.fullXid(*x)
+		}
+	}
+	to := min(total, f.FromBlock+deadTupleMaxPages) - 1
+
+scan:
+	for start := f.FromBlock; start <= to; start += deadTupleChunk {
+		end := min(start+deadTupleChunk-1, to)
+		blks, tuples, raws, err := i.heapRangeItems(ctx, table, start, end, f)
+		if err != nil {
+			return nil, err
+		}
+		if err := i.evaluateVisibility(ctx, c, c.ref, tuples); err != nil {
+			return nil, err
+		}
+		states, err := i.vacuumStates(ctx, c, tuples, h.Horizon)
+		if err != nil {
+			return nil, err
+		}
+
+		k := 0
+		for j := range tuples {
+			t := &tuples[j]
+			var st HorizonTuple
+			if t.LPFlags == lpNormal {
+				st = states[k]
+				k++
+			}
+			d, ok := deadTuple(c.ref, blks[j], t, st, f)
+			if !ok {
+				continue
+			}
+			if t.LPFlags == lpNormal {
+				d.Attrs = decodeHeapAttrs(attrs, raws[j])
+			}
+			out.Tuples = append(out.Tuples, d)
+			if len(out.Tuples) == f.Limit {
+				out.ToBlock = blks[j]
+				out.NextCursor = d.TID
+				break scan
+			}
+		}
+		out.ToBlock = end
+	}
+	if out.NextCursor == "" && out.ToBlock+1 < total {
+		out.NextCursor = fmt.Sprintf("(%d,0)", out.ToBlock+1)
+	}
+	out.Explanation = deadTuplesExplanation(out)
+	return out, nil
+}
+
+// heapRangeItems reads every line pointer on blocks start..end that comes
+// after the cursor, with raw t_attrs alongside
+func (i *Inspector) heapRangeItems(ctx context.Context, table string, start, end int, f DeadTupleFilter) ([]int, []HeapTuple, [][][]byte, error) {
+	rows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("heap-range-items").Query(), table, start, end)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("heap_page_item_attrs %s blk %d: %w", table, start, err)
+	}
+	defer rows.Close()
+
+	var blks []int
+	var tuples []HeapTuple
+	var raws [][][]byte
+	for rows.Next() {
+		var t HeapTuple
+		var blk, mask, mask2 int
+		var raw [][]byte
+		if err := rows.Scan(&blk, &t.LP, &t.LPOffset, &t.LPFlags, &t.ItemLen, &t.Xmin, &t.Xmax, &t.Ctid, &mask, &mask2, &raw); err != nil {
+			return nil, nil, nil, fmt.Errorf("scan tuple: %w", err)
+		}
+		if blk == f.FromBlock && t.LP <= f.AfterItem {
+			continue
+		}
+		t.LPFlagsStr = lpFlagsStr(t.LPFlags)
+		t.setInfoMask(mask, mask2)
+		blks = append(blks, blk)
+		tuples = append(tuples, t)
+		raws = append(raws, raw)
+	}
+	return blks, tuples, raws, rows.Err()
+}
+
+// deadTuple describes t if it is neither visible nor filtered out. f's XID
+// bounds are epoch-qualified; ref widens t's XIDs to match.
+func deadTuple(ref *Snapshot, blk int, t *HeapTuple, st HorizonTuple, f DeadTupleFilter) (DeadTuple, bool) {
+	d := DeadTuple{
+		TID:     fmt.Sprintf("(%d,%d)", blk, t.LP),
+		Page:    blk,
+		Item:    t.LP,
+		LPFlags: t.LPFlagsStr,
+		LPLen:   t.ItemLen,
+	}
+	if !slices.Contains(f.LPStates, t.LPFlagsStr) {
+		return d, false
+	}
+	xidFiltered := f.XminFrom != 0 || f.XminTo != 0 || f.XmaxFrom != 0 || f.XmaxTo != 0
+
+	switch t.LPFlags {
+	case lpNormal:
+		if t.IsLive {
+			return d, false
+		}
+		xmin, xmax := ref.fullXid(t.Xmin), int64(0)
+		if t.Xmax != 0 {
+			xmax = ref.fullXid(t.Xmax)
+		}
+		if f.XminFrom != 0 && xmin < f.XminFrom || f.XminTo != 0 && xmin > f.XminTo ||
+			f.XmaxFrom != 0 && xmax < f.XmaxFrom || f.XmaxTo != 0 && xmax > f.XmaxTo {
+			return d, false
+		}
+		d.Xmin, d.Xmax, d.Ctid, d.InfoMask = t.Xmin, t.Xmax, t.Ctid, t.InfoMask
+		d.XminStatus, d.XmaxStatus = t.XminStatus, t.XmaxStatus
+		d.VacuumState, d.Removable, d.Reason = st.State, st.State == vacuumDead, st.Reason
+		if st.State == vacuumLive {
+			d.Reason += "; not visible to the current snapshot yet"
+		}
+	case lpDead:
+		d.Removable = true
+		d.Reason = "pruned tuple: only the line pointer is left, until VACUUM has removed the index entries pointing at it"
+	case lpRedirect:
+		d.RedirectTo = t.LPOffset
+		d.Reason = fmt.Sprintf("root of a pruned HOT chain, redirecting to lp %d; it stays while index entries point here", t.LPOffset)
+	default:
+		d.Reason = "free slot that the next insert on this page can reuse"
+	}
+	if xidFiltered && t.LPFlags != lpNormal {
+		return d, false
+	}
+	if f.Removable != nil && *f.Removable != d.Removable {
+		return d, false
+	}
+	return d, true
+}
+
+func deadTuplesExplanation(l *DeadTupleList) string {
+	var removable, pruned, kept, bytes int
+	for _, d := range l.Tuples {
+		if d.Removable {
+			removable++
+		}
+		switch d.LPFlags {
+		case lpFlagsStr(lpDead):
+			pruned++
+		case lpFlagsStr(lpNormal):
+			bytes += d.LPLen
+			if !d.Removable {
+				kept++
+			}
+		}
+	}
+	out := fmt.Sprintf("🧟 %d matching item(s) on pages %d–%d of %d: %d removable by VACUUM under the cleanup horizon %d, %d already pruned to LP_DEAD. "+
+		"The non-visible tuples hold %d bytes of tuple data that only VACUUM (or pruning, on their page) can give back.",
+		len(l.Tuples), l.FromBlock, l.ToBlock, l.TotalPages, removable, l.Horizon, pruned, bytes)
+	if kept > 0 {
+		out += fmt.Sprintf(" The %d tuple(s) that aren't removable are either still visible to some older snapshot (RECENTLY_DEAD) or involve a transaction that is still running.", kept)
+	}
+	if l.NextCursor != "" {
+		out += fmt.Sprintf(" More of the heap is left to scan; continue from %s.", l.NextCursor)
+	}
+	return out
+}
@@ -0,0 +1,299 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ExecuteDemoInsert inserts a row and works out why it landed on its page:
+// a page the free space map offered, the last page, or a new one.
+// Columns missing from values get their defaults.
+func (i *Inspector) ExecuteDemoInsert(ctx context.Context, table string, values map[string]string) (*DemoInsertResult, error) {
+	fail := func(msg string) *DemoInsertResult {
+		return &DemoInsertResult{Success: false, Error: msg, Table: table}
+	}
+
+	info, err := i.GetIndexedColumns(ctx, table)
+	if err != nil {
+		return fail(fmt.Sprintf("table info: %v", err)), nil
+	}
+	cols := make([]string, 0, len(values))
+	for col := range values {
+		if _, ok := info.ColumnTypes[col]; !ok {
+			return fail(fmt.Sprintf("column %s not found", col)), nil
+		}
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+
+	var blocksBefore int
+	if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("table-block-count").Query(), table).Scan(&blocksBefore); err != nil {
+		return fail(fmt.Sprintf("block count: %v", err)), nil
+	}
+	lastFree := 0
+	if blocksBefore > 0 {
+		hdr, err := i.heapPageHeader(ctx, table, blocksBefore-1)
+		if err != nil {
+			return fail(err.Error()), nil
+		}
+		lastFree = hdr.free
+	}
+
+	q := fmt.Sprintf("INSERT INTO %s DEFAULT VALUES", table)
+	args := make([]any, len(cols))
+	if len(cols) > 0 {
+		params := make([]string, len(cols))
+		for j, col := range cols {
+			params[j] = fmt.Sprintf("$%d", j+1)
+			args[j] = values[col]
+		}
+		q = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(cols, ", "), strings.Join(params, ", "))
+	}
+	returning := "''"
+	if info.PKColumn != "" {
+		returning = info.PKColumn + "::text"
+	}
+	q += fmt.Sprintf(" RETURNING ctid::text, %s", returning)
+
+	var ctid, pk string
+	if err := i.pool.QueryRow(ctx, q, args...).Scan(&ctid, &pk); err != nil {
+		return fail(fmt.Sprintf("insert: %v", err)), nil
+	}
+	blk, item, _ := parseCtid(ctid)
+	loc := &RowLocation{Found: true, TID: ctid, Page: blk, Item: item}
+
+	page, err := i.GetHeapPageDetail(ctx, table, blk)
+	if err != nil {
+		return fail(fmt.Sprintf("page detail: %v", err)), nil
+	}
+	t := findTuple(page.Tuples, item)
+	if t == nil {
+		return fail("tuple not on page"), nil
+	}
+	hdr, err := i.heapPageHeader(ctx, table, blk)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+
+	res := &DemoInsertResult{
+		Success:            true,
+		Table:              table,
+		Values:             values,
+		PK:                 pk,
+		After:              tupleState(loc, t),
+		PagesBefore:        blocksBefore,
+		TupleSize:          t.ItemLen,
+		SpaceNeeded:        maxAlign(t.ItemLen),
+		ReusedLP:           item < len(page.Tuples),
+		TargetFreeAfter:    hdr.free,
+		LastPageFreeBefore: lastFree,
+	}
+	// a line pointer freed by VACUUM is reused before the array grows
+	if !res.ReusedLP {
+		res.SpaceNeeded += lpSize
+	}
+	if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("table-block-count").Query(), table).Scan(&res.PagesAfter); err != nil {
+		return fail(fmt.Sprintf("block count: %v", err)), nil
+	}
+	if res.FillFactor, err = i.tableFillFactor(ctx, table); err != nil {
+		return fail(err.Error()), nil
+	}
+	res.ReservedSpace = pageSize * (100 - res.FillFactor) / 100
+
+	switch {
+	case blk >= blocksBefore:
+		res.Placement = "new_page"
+		res.TargetFreeBefore = pageSize - pageHeader
+	case blk == blocksBefore-1:
+		res.Placement = "last_page"
+		res.TargetFreeBefore = hdr.free + res.SpaceNeeded
+	default:
+		res.Placement = "fsm"
+		res.TargetFreeBefore = hdr.free + res.SpaceNeeded
+	}
+
+	var hasFSM bool
+	if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("extension-exists").Query(), "pg_freespacemap").Scan(&hasFSM); err == nil && hasFSM {
+		var avail int
+		if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("fsm-page-avail").Query(), table, blk).Scan(&avail); err == nil {
+			res.FSMAvail = &avail
+		}
+	}
+	res.Explanation = insertExplanation(res)
+	return res, nil
+}
+
+// ExecuteDemoDelete deletes a row by primary key and shows what DELETE
+// leaves on the page and what pruning it later would do.
+func (i *Inspector) ExecuteDemoDelete(ctx context.Context, table, pk string) (*DemoDeleteResult, error) {
+	fail := func(msg string) *DemoDeleteResult {
+		return &DemoDeleteResult{Success: false, Error: msg, Table: table, PK: pk}
+	}
+
+	info, err := i.GetIndexedColumns(ctx, table)
+	if err != nil {
+		return fail(fmt.Sprintf("table info: %v", err)), nil
+	}
+	if info.PKColumn == "" {
+		return fail("table has no primary key"), nil
+	}
+	loc, err := i.FindRowByPK(ctx, table, pk)
+	if err != nil || !loc.Found {
+		return fail(fmt.Sprintf("row pk=%s not found", pk)), nil
+	}
+	beforeT, err := i.tupleAt(ctx, table, loc, nil)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	hdrBefore, err := i.heapPageHeader(ctx, table, loc.Page)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+
+	q := fmt.Sprintf("DELETE FROM %s WHERE %s = $1", table, info.PKColumn)
+	tag, err := i.pool.Exec(ctx, q, pk)
+	if err != nil {
+		return fail(fmt.Sprintf("delete: %v", err)), nil
+	}
+	if tag.RowsAffected() == 0 {
+		return fail(fmt.Sprintf("row pk=%s was deleted concurrently", pk)), nil
+	}
+
+	afterT, err := i.tupleAt(ctx, table, loc, nil)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	hdrAfter, err := i.heapPageHeader(ctx, table, loc.Page)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	ff, err := i.tableFillFactor(ctx, table)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+
+	res := &DemoDeleteResult{
+		Success:         true,
+		Table:           table,
+		PK:              pk,
+		Before:          tupleState(loc, beforeT),
+		After:           tupleState(loc, afterT),
+		InfoMaskSet:     []string{},
+		InfoMaskCleared: []string{},
+		PruneXidBefore:  hdrBefore.pruneXid,
+		PruneXidAfter:   hdrAfter.pruneXid,
+		PageFree:        hdrAfter.free,
+		PruneThreshold:  pruneOnReadThreshold(ff),
+	}
+	for _, f := range res.After.InfoMask {
+		if !slices.Contains(res.Before.InfoMask, f) {
+			res.InfoMaskSet = append(res.InfoMaskSet, f)
+		}
+	}
+	for _, f := range res.Before.InfoMask {
+		if !slices.Contains(res.After.InfoMask, f) {
+			res.InfoMaskCleared = append(res.InfoMaskCleared, f)
+		}
+	}
+
+	c, err := i.newXactCache(ctx)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	h, err := i.cleanupHorizon(ctx, c)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	states, err := i.vacuumStates(ctx, c, []HeapTuple{*afterT}, h.Horizon)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	if len(states) > 0 {
+		res.VacuumState = fmt.Sprintf("%s: %s", states[0].State, states[0].Reason)
+	}
+	p, err := i.planPrune(ctx, c, table, loc.Page, h.Horizon)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	for _, ch := range p.pred.Changes {
+		if ch.LP == loc.Item {
+			res.Prune = &ch
+		}
+	}
+	res.Explanation = deleteExplanation(res)
+	return res, nil
+}
+
+func (i *Inspector) tableFillFactor(ctx context.Context, table string) (int, error) {
+	var ff int
+	if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("table-fillfactor").Query(), table).Scan(&ff); err != nil {
+		return 0, fmt.Errorf("fillfactor %s: %w", table, err)
+	}
+	return ff, nil
+}
+
+// pruneOnReadThreshold is the free space below which a read prunes the page
+// (heap_page_prune_opt): the fillfactor reserve, but at least a tenth of it
+func pruneOnReadThreshold(fillfactor int) int {
+	return max(pageSize*(100-fillfactor)/100, pageSize/10)
+}
+
+func insertExplanation(res *DemoInsertResult) string {
+	a := res.After
+	var out string
+	switch res.Placement {
+	case "new_page":
+		out = fmt.Sprintf(
+			"🆕 NEW PAGE: no existing page could take the %d bytes this tuple needs, so the table was extended from %d to %d pages and the tuple became %s. "+
+				"The free space map had no candidate and the last page only had %d bytes free.",
+			res.SpaceNeeded, res.PagesBefore, res.PagesAfter, a.TID, res.LastPageFreeBefore)
+	case "last_page":
+		out = fmt.Sprintf(
+			"📄 LAST PAGE: the tuple went to page %d, the end of the table, which had %d bytes free for the %d it needed. "+
+				"An insert first tries the page its connection last inserted into, then the free space map, then the last page.",
+			a.Page, res.TargetFreeBefore, res.SpaceNeeded)
+	case "fsm":
+		out = fmt.Sprintf(
+			"♻️ FREE SPACE MAP: the tuple went to page %d although the table has %d pages. The free space map offered it because it had %d bytes free for the %d needed, "+
+				"space that VACUUM freed and recorded after earlier deletes or updates.",
+			a.Page, res.PagesBefore, res.TargetFreeBefore, res.SpaceNeeded)
+	}
+	if res.ReusedLP {
+		out += fmt.Sprintf(" It reused line pointer %d, which VACUUM had set LP_UNUSED.", a.Item)
+	}
+	if res.FSMAvail != nil {
+		out += fmt.Sprintf(" The free space map now records %d bytes for the page; it is only updated by VACUUM and when an insert finds a page fuller than recorded, so it lags behind.", *res.FSMAvail)
+	}
+	if res.FillFactor < 100 {
+		out += fmt.Sprintf(" With fillfactor %d, inserts leave %d bytes of every page free for updates.", res.FillFactor, res.ReservedSpace)
+	}
+	return out + fmt.Sprintf(" xmin %d is the inserting transaction; the tuple is visible to snapshots taken after it committed.", a.Xmin)
+}
+
+func deleteExplanation(res *DemoDeleteResult) string {
+	b, a := res.Before, res.After
+	out := fmt.Sprintf(
+		"🗑️ DELETE: nothing was removed from page %d. Transaction %d stamped its xid into xmax of %s (was %d), so snapshots taken after it committed no longer see the row.",
+		a.Page, a.Xmax, a.TID, b.Xmax)
+	if len(res.InfoMaskSet) > 0 || len(res.InfoMaskCleared) > 0 {
+		out += fmt.Sprintf(" Infomask: set %s, cleared %s.", strings.Join(res.InfoMaskSet, ", "), strings.Join(res.InfoMaskCleared, ", "))
+	}
+	if res.PruneXidAfter != res.PruneXidBefore {
+		out += fmt.Sprintf(" pd_prune_xid went %d→%d, marking the page as having something to prune.", res.PruneXidBefore, res.PruneXidAfter)
+	}
+
+	if res.Prune != nil {
+		out += fmt.Sprintf(" 🧹 Pruning the page now would turn lp %d from %s into %s: %s.", res.Prune.LP, res.Prune.From, res.Prune.To, res.Prune.Reason)
+	} else {
+		out += fmt.Sprintf(" 🧹 Pruning the page now would leave it alone (%s).", res.VacuumState)
+	}
+	if res.PageFree < res.PruneThreshold {
+		out += fmt.Sprintf(" The page has %d bytes free, below the %d-byte threshold, so the next read of it prunes opportunistically.", res.PageFree, res.PruneThreshold)
+	} else {
+		out += fmt.Sprintf(" The page still has %d bytes free, above the %d-byte threshold for pruning on read, so the space waits for VACUUM.", res.PageFree, res.PruneThreshold)
+	}
+	return out
+}
@@ -0,0 +1,309 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	experimentMaxRows    = 10000
+	experimentMaxUpdates = 20000
+	experimentMaxSteps   = 100
+)
+
+// RunUpdateExperiment copies the table into a scratch table with the given
+// fillfactor, runs a stream of single-row updates over it and records after
+// every step how many were HOT and what the heap looks like.
+func (i *Inspector) RunUpdateExperiment(ctx context.Context, table string, cfg UpdateExperimentConfig) (*UpdateExperimentResult, error) {
+	fail := func(msg string) *UpdateExperimentResult {
+		return &UpdateExperimentResult{Success: false, Error: msg, Table: table, Config: cfg}
+	}
+
+	if cfg.Updates == 0 {
+		cfg.Updates = 1000
+	}
+	if cfg.Steps == 0 {
+		cfg.Steps = 20
+	}
+	if cfg.Distribution == "" {
+		cfg.Distribution = "uniform"
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = 1
+	}
+	switch {
+	case cfg.Updates < 1 || cfg.Updates > experimentMaxUpdates:
+		return fail(fmt.Sprintf("updates must be between 1 and %d", experimentMaxUpdates)), nil
+	case cfg.Steps < 1 || cfg.Steps > experimentMaxSteps || cfg.Steps > cfg.Updates:
+		return fail(fmt.Sprintf("steps must be between 1 and %d and at most the number of updates", experimentMaxSteps)), nil
+	case cfg.FillFactor != 0 && (cfg.FillFactor < 10 || cfg.FillFactor > 100):
+		return fail("fillfactor must be between 10 and 100"), nil
+	case !slices.Contains([]string{"uniform", "skewed", "sequential"}, cfg.Distribution):
+		return fail("distribution must be uniform, skewed or sequential"), nil
+	}
+
+	info, err := i.GetIndexedColumns(ctx, table)
+	if err != nil {
+		return fail(fmt.Sprintf("table info: %v", err)), nil
+	}
+	if info.PKColumn == "" {
+		return fail("table has no primary key"), nil
+	}
+	cats, err := i.columnCategories(ctx, table)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	if len(cfg.Columns) == 0 {
+		for _, col := range info.NonIndexed {
+			if _, ok := updateExpr(col, cats[col]); ok {
+				cfg.Columns = []string{col}
+				break
+			}
+		}
+		if len(cfg.Columns) == 0 {
+			return fail("no non-indexed column with a type the experiment can change"), nil
+		}
+	}
+	exprs := make([]string, len(cfg.Columns))
+	for j, col := range cfg.Columns {
+		if col == info.PKColumn {
+			return fail("the primary key can't be updated by the experiment"), nil
+		}
+		cat, ok := cats[col]
+		if !ok {
+			return fail(fmt.Sprintf("column %s not found", col)), nil
+		}
+		if exprs[j], ok = updateExpr(col, cat); !ok {
+			return fail(fmt.Sprintf("column %s has a type the experiment can't change", col)), nil
+		}
+	}
+	if cfg.FillFactor == 0 {
+		if cfg.FillFactor, err = i.tableFillFactor(ctx, table); err != nil {
+			return fail(err.Error()), nil
+		}
+	}
+
+	// autovacuum stays off so only pruning on access reclaims space
+	scratch := fmt.Sprintf("pgstoviz_exp_%d", time.Now().UnixNano())
+	create := fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING ALL) WITH (fillfactor = %d, autovacuum_enabled = false)", scratch, table, cfg.FillFactor)
+	if _, err := i.pool.Exec(ctx, create); err != nil {
+		return fail(fmt.Sprintf("create scratch table: %v", err)), nil
+	}
+	defer i.pool.Exec(context.WithoutCancel(ctx), fmt.Sprintf("DROP TABLE IF EXISTS %s", scratch))
+
+	copyQ := fmt.Sprintf("INSERT INTO %s OVERRIDING SYSTEM VALUE SELECT * FROM %s LIMIT %d", scratch, table, experimentMaxRows)
+	tag, err := i.pool.Exec(ctx, copyQ)
+	if err != nil {
+		return fail(fmt.Sprintf("copy rows: %v", err)), nil
+	}
+	res := &UpdateExperimentResult{
+		Success:    true,
+		Table:      table,
+		Config:     cfg,
+		Rows:       int(tag.RowsAffected()),
+		RowsCapped: tag.RowsAffected() == experimentMaxRows,
+		Series:     []UpdateExperimentStep{},
+	}
+	if res.Rows == 0 {
+		return fail("table is empty"), nil
+	}
+
+	var pks []string
+	rows, err := i.pool.Query(ctx, fmt.Sprintf("SELECT %s::text FROM %s ORDER BY %s", info.PKColumn, scratch, info.PKColumn))
+	if err != nil {
+		return fail(fmt.Sprintf("load keys: %v", err)), nil
+	}
+	if pks, err = pgx.CollectRows(rows, pgx.RowTo[string]); err != nil {
+		return fail(fmt.Sprintf("load keys: %v", err)), nil
+	}
+
+	if res.Start, err = i.experimentStep(ctx, scratch, 0); err != nil {
+		return fail(err.Error()), nil
+	}
+	queries := make([]string, len(cfg.Columns))
+	for j, col := range cfg.Columns {
+		queries[j] = fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s = $1", scratch, col, exprs[j], info.PKColumn)
+	}
+
+	rng := rand.New(rand.NewPCG(cfg.Seed, cfg.Seed))
+	pick := func(n int) int {
+		switch cfg.Distribution {
+		case "skewed":
+			// 80% of the updates hit the first 20% of the rows
+			if rng.Float64() < 0.8 {
+				return rng.IntN(max(1, len(pks)/5))
+			}
+			return rng.IntN(len(pks))
+		case "sequential":
+			return n % len(pks)
+		}
+		return rng.IntN(len(pks))
+	}
+
+	start := time.Now()
+	done, hot := 0, 0
+	for s := 1; s <= cfg.Steps; s++ {
+		n := cfg.Updates*s/cfg.Steps - done
+		stepUpdated, stepHot, err := i.experimentBatch(ctx, scratch, queries, pks, done, n, pick)
+		if err != nil {
+			return fail(err.Error()), nil
+		}
+		done += stepUpdated
+		hot += stepHot
+
+		st, err := i.experimentStep(ctx, scratch, s)
+		if err != nil {
+			return fail(err.Error()), nil
+		}
+		st.Updates, st.HotUpdates, st.NonHotUpdates = done, hot, done-hot
+		if done > 0 {
+			st.HotRatio = float64(hot) / float64(done)
+		}
+		res.Series = append(res.Series, st)
+	}
+	res.DurationMs = time.Since(start).Milliseconds()
+	res.HotUpdates = hot
+	if done > 0 {
+		res.HotRatio = float64(hot) / float64(done)
+	}
+	res.Explanation = experimentExplanation(res, info)
+	return res, nil
+}
+
+// experimentBatch runs one step's updates in a single transaction and reads
+// the transaction's own update counters before committing
+func (i *Inspector) experimentBatch(ctx context.Context, scratch string, queries, pks []string, done, n int, pick func(int) int) (updated, hot int, err error) {
+	tx, err := i.pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	batch := &pgx.Batch{}
+	for k := done; k < done+n; k++ {
+		batch.Queue(queries[k%len(queries)], pks[pick(k)])
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return 0, 0, fmt.Errorf("updates: %w", err)
+	}
+	if err := tx.QueryRow(ctx, i.qs.MustHaveQuery("xact-update-counts").Query(), scratch).Scan(&updated, &hot); err != nil {
+		return 0, 0, fmt.Errorf("update counters: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, fmt.Errorf("commit: %w", err)
+	}
+	return updated, hot, nil
+}
+
+func (i *Inspector) experimentStep(ctx context.Context, table string, step int) (UpdateExperimentStep, error) {
+	st := UpdateExperimentStep{Step: step}
+	err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("heap-lp-summary").Query(), table).Scan(
+		&st.LinePointers, &st.NormalLPs, &st.Redirects, &st.DeadLPs, &st.UnusedLPs,
+	)
+	if err != nil {
+		return st, fmt.Errorf("line pointer summary %s: %w", table, err)
+	}
+	if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("heap-free-summary").Query(), table).Scan(&st.Pages, &st.FreeSpace, &st.MinPageFree); err != nil {
+		return st, fmt.Errorf("free space summary %s: %w", table, err)
+	}
+	if st.Pages > 0 {
+		st.AvgPageFree = st.FreeSpace / st.Pages
+	}
+	return st, nil
+}
+
+func (i *Inspector) columnCategories(ctx context.Context, table string) (map[string]string, error) {
+	rows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("column-type-categories").Query(), table)
+	if err != nil {
+		return nil, fmt.Errorf("column types %s: %w", table, err)
+	}
+	defer rows.Close()
+	out := map[string]string{}
+	for rows.Next() {
+		var col, cat string
+		if err := rows.Scan(&col, &cat); err != nil {
+			return nil, fmt.Errorf("scan column type: %w", err)
+		}
+		out[col] = cat
+	}
+	return out, rows.Err()
+}
+
+// updateExpr is a new value for col that keeps its size, by pg_type
+// category, so the tuple width stays the same across updates
+func updateExpr(col, category string) (string, bool) {
+	switch category {
+	case "N":
+		return col + " + 1", true
+	case "S":
+		return fmt.Sprintf("left(repeat(md5(random()::text), length(%s) / 32 + 1), length(%s))", col, col), true
+	case "B":
+		return "NOT " + col, true
+	case "D":
+		return col + " + interval '1 second'", true
+	}
+	return "", false
+}
+
+// savedColumn is a column value of one row kept as text along with its
+// type, so a demo that changed a real row can write the original back
+type savedColumn struct {
+	column string
+	typ    string
+	value  *string
+}
+
+func (i *Inspector) saveColumn(ctx context.Context, table, pkCol, pk, column string) (*savedColumn, error) {
+	s := &savedColumn{column: column}
+	q := fmt.Sprintf("SELECT %[1]s::text, pg_typeof(%[1]s)::text FROM %[2]s WHERE %[3]s = $1", column, table, pkCol)
+	if err := i.pool.QueryRow(ctx, q, pk).Scan(&s.value, &s.typ); err != nil {
+		return nil, fmt.Errorf("read %s: %w", column, err)
+	}
+	return s, nil
+}
+
+// restoreQuery writes the saved value back; it takes the key as $1 and the
+// value as $2
+func (s *savedColumn) restoreQuery(table, pkCol string) string {
+	return fmt.Sprintf("UPDATE %s SET %s = $2::text::%s WHERE %s = $1", table, s.column, s.typ, pkCol)
+}
+
+func experimentExplanation(res *UpdateExperimentResult, info *IndexedColumnsInfo) string {
+	first, last := res.Start, res.Start
+	if len(res.Series) > 0 {
+		last = res.Series[len(res.Series)-1]
+	}
+	cfg := res.Config
+	out := fmt.Sprintf(
+		"📈 %d updates of %s over %d rows (%s) on a copy with fillfactor %d: %d were HOT (%.0f%%). "+
+			"Pages %d→%d, line pointers %d→%d, redirects %d→%d, free space %d→%d bytes.",
+		last.Updates, strings.Join(cfg.Columns, ", "), res.Rows, cfg.Distribution, cfg.FillFactor, res.HotUpdates, 100*res.HotRatio,
+		first.Pages, last.Pages, first.LinePointers, last.LinePointers, first.Redirects, last.Redirects, first.FreeSpace, last.FreeSpace)
+
+	var indexed []string
+	for _, col := range cfg.Columns {
+		if slices.Contains(info.IndexedColumns, col) {
+			indexed = append(indexed, col)
+		}
+	}
+	if len(indexed) > 0 {
+		out += fmt.Sprintf(" %s is indexed, so updates to it are never HOT whatever the fillfactor.", strings.Join(indexed, ", "))
+	}
+	out += " A HOT update needs room on the row's own page; once a page is full and pruning can't reclaim anything, updates leave it and every index gets a new entry."
+	switch {
+	case cfg.FillFactor == 100 && res.HotRatio < 0.9:
+		out += " With fillfactor 100 the pages start full, so rerun with a lower fillfactor (say 70) to give updates headroom."
+	case cfg.FillFactor < 100:
+		out += fmt.Sprintf(" fillfactor %d left about %d bytes per page free at load time for new versions to use.", cfg.FillFactor, pageSize*(100-cfg.FillFactor)/100)
+	}
+	if last.Redirects > first.Redirects {
+		out += " The growing LP_REDIRECT count is pruning at work: pruned HOT chains keep a redirect at their root so index entries stay valid."
+	}
+	return out
+}
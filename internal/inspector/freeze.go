@@ -0,0 +1,49 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetFreezeInfo reports how close a table is to an anti-wraparound
+// (forced) autovacuum.
+func (i *Inspector) GetFreezeInfo(ctx context.Context, table string) (*FreezeInfo, error) {
+	out := &FreezeInfo{Table: table}
+	err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("table-freeze-info").Query(), table).Scan(
+		&out.RelFrozenXID, &out.FrozenXIDAge, &out.RelMinMXID, &out.MinMXIDAge,
+		&out.FreezeMaxAge, &out.MultixactFreezeMaxAge, &out.FreezeMinAge, &out.FreezeTableAge, &out.FailsafeAge,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("freeze info %s: %w", table, err)
+	}
+
+	out.XIDsUntilForced = out.FreezeMaxAge - out.FrozenXIDAge
+	out.MXIDsUntilForced = out.MultixactFreezeMaxAge - out.MinMXIDAge
+	if out.FreezeMaxAge > 0 {
+		out.ForcedPct = 100 * float64(out.FrozenXIDAge) / float64(out.FreezeMaxAge)
+	}
+	// VACUUM scans all-visible but not all-frozen pages once the table is
+	// older than freeze_table_age (capped at 95% of the max age)
+	out.NextVacuumAggressive = out.FrozenXIDAge >= min(out.FreezeTableAge, out.FreezeMaxAge*95/100)
+
+	switch {
+	// vacuum_failsafe_age is new in PostgreSQL 14
+	case out.FailsafeAge != nil && out.FrozenXIDAge >= *out.FailsafeAge:
+		out.Status = "failsafe"
+		out.Explanation = fmt.Sprintf("🚨 relfrozenxid is %d transactions old, past vacuum_failsafe_age (%d). VACUUM skips index cleanup and cost delays to freeze the table as fast as possible.",
+			out.FrozenXIDAge, *out.FailsafeAge)
+	case out.XIDsUntilForced <= 0 || out.MXIDsUntilForced <= 0:
+		out.Status = "forced"
+		out.Explanation = fmt.Sprintf("🔴 The table passed autovacuum_freeze_max_age (%d): an anti-wraparound autovacuum runs even if autovacuum is disabled, and cannot be cancelled by lock conflicts.",
+			out.FreezeMaxAge)
+	case out.NextVacuumAggressive:
+		out.Status = "aggressive"
+		out.Explanation = fmt.Sprintf("🟠 relfrozenxid age %d exceeds vacuum_freeze_table_age (%d): the next VACUUM will be aggressive and read every page that is not all-frozen. %d XIDs remain before a forced autovacuum.",
+			out.FrozenXIDAge, out.FreezeTableAge, out.XIDsUntilForced)
+	default:
+		out.Status = "ok"
+		out.Explanation = fmt.Sprintf("🟢 relfrozenxid is %d transactions old (%.1f%% of autovacuum_freeze_max_age). Tuples older than vacuum_freeze_min_age (%d) get frozen as VACUUM passes their pages.",
+			out.FrozenXIDAge, out.ForcedPct, out.FreezeMinAge)
+	}
+	return out, nil
+}
@@ -86,9 +86,16 @@ func (i *Inspector) GetTableIndexes(ctx context.Context, table string) ([]IndexI
 	var out []IndexInfo
 	for rows.Next() {
 		var idx IndexInfo
-		if err := rows.Scan(&idx.Name, &idx.TableName, &idx.IndexType, &idx.Size, &idx.NumPages, &idx.IsUnique, &idx.IsPrimary, &idx.Columns); err != nil {
+		var keyCount int
+		if err := rows.Scan(&idx.Name, &idx.TableName, &idx.IndexType, &idx.Size, &idx.NumPages, &idx.IsUnique, &idx.IsPrimary, &idx.Columns,
+			&idx.Elements, &keyCount, &idx.Definition, &idx.Expressions, &idx.Predicate, &idx.Opclasses, &idx.Collations, &idx.SortOrder); err != nil {
 			return nil, fmt.Errorf("scan index: %w", err)
 		}
+		if keyCount > len(idx.Elements) {
+			keyCount = len(idx.Elements)
+		}
+		idx.KeyColumns = idx.Elements[:keyCount]
+		idx.IncludeColumns = idx.Elements[keyCount:]
 		out = append(out, idx)
 	}
 	return out, rows.Err()
@@ -128,6 +135,22 @@ func (i *Inspector) GetTableDetail(ctx context.Context, table string) (*TableDet
 }
 
 func (i *Inspector) GetHeapPageDetail(ctx context.Context, table string, blockNo int) (*HeapPageDetail, error) {
+	return i.GetHeapPageAt(ctx, table, blockNo, nil)
+}
+
+// GetHeapPageAt reads a heap page and judges tuple visibility against snap,
+// or against a fresh snapshot when snap is nil.
+func (i *Inspector) GetHeapPageAt(ctx context.Context, table string, blockNo int, snap *Snapshot) (*HeapPageDetail, error) {
+	c, err := i.newXactCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return i.heapPageWith(ctx, c, table, blockNo, snap)
+}
+
+// heapPageWith is GetHeapPageAt looking up transaction status through c,
+// so callers reading many pages share one cache
+func (i *Inspector) heapPageWith(ctx context.Context, c *xactCache, table string, blockNo int, snap *Snapshot) (*HeapPageDetail, error) {
 	var totalPages int
 	err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("table-page-count").Query(), table).Scan(&totalPages)
 	if err != nil {
@@ -153,11 +176,23 @@ func (i *Inspector) GetHeapPageDetail(ctx context.Context, table string, blockNo
 
 		t.LPOffset = lpOff
 		t.LPFlagsStr = lpFlagsStr(t.LPFlags)
-		t.InfoMask = decodeInfoMask(mask, mask2)
-		t.IsLive = t.LPFlags == lpNormal && t.Xmax == 0
-		t.IsHot = hasFlag(t.InfoMask, "HEAP_HOT_UPDATED") || hasFlag(t.InfoMask, "HEAP_ONLY_TUPLE")
-		t.IsUpdated = t.Xmax != 0
+		t.setInfoMask(mask, mask2)
+
+		tuples = append(tuples, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if snap == nil {
+		snap = c.ref
+	}
+	if err := i.evaluateVisibility(ctx, c, snap, tuples); err != nil {
+		return nil, err
+	}
 
+	for _, t := range tuples {
 		used += lpSize
 		switch t.LPFlags {
 		case lpNormal:
@@ -170,11 +205,6 @@ func (i *Inspector) GetHeapPageDetail(ctx context.Context, table string, blockNo
 		case lpDead:
 			lpDeadCnt++
 		}
-
-		tuples = append(tuples, t)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
 	}
 
 	free := pageSize - used
@@ -193,12 +223,13 @@ func (i *Inspector) GetHeapPageDetail(ctx context.Context, table string, blockNo
 			LpCount:     len(tuples),
 			LpDeadCount: lpDeadCnt,
 		},
-		Tuples: tuples,
+		Snapshot: snap,
+		Tuples:   tuples,
 	}, nil
 }
 
-func (i *Inspector) GetHeapPageWithAttrs(ctx context.Context, table string, blockNo int) (*HeapPageDetail, error) {
-	detail, err := i.GetHeapPageDetail(ctx, table, blockNo)
+func (i *Inspector) GetHeapPageWithAttrs(ctx context.Context, table string, blockNo int, snap *Snapshot) (*HeapPageDetail, error) {
+	detail, err := i.GetHeapPageAt(ctx, table, blockNo, snap)
 	if err != nil {
 		return nil, err
 	}
@@ -246,8 +277,9 @@ func (i *Inspector) GetHeapPageWithAttrs(ctx context.Context, table string, bloc
 }
 
 func (i *Inspector) GetHeapPageMap(ctx context.Context, table string) (*HeapPageMap, error) {
+	// relpages lags behind until the next VACUUM or ANALYZE
 	var total int
-	err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("table-page-count").Query(), table).Scan(&total)
+	err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("table-block-count").Query(), table).Scan(&total)
 	if err != nil {
 		return nil, fmt.Errorf("page count %s: %w", table, err)
 	}
@@ -268,9 +300,13 @@ func (i *Inspector) GetHeapPageMap(ctx context.Context, table string) (*HeapPage
 		var used, lps int64
 		p.BlockNo = blk
 
-		if err := i.pool.QueryRow(ctx, q, table, blk).Scan(&p.LiveTuples, &p.DeadTuples, &used, &lps); err != nil {
+		if err := i.pool.QueryRow(ctx, q, table, blk).Scan(
+			&p.LiveTuples, &p.DeadTuples, &used, &lps,
+			&p.UnfrozenTuples, &p.OldestXminAge, &p.OldestUnfrozenXmin,
+		); err != nil {
 			continue
 		}
+		p.AllFrozen = p.UnfrozenTuples == 0
 
 		p.FreeSpace = pageSize - pageHeader - int(lps)*lpSize - int(used)
 		if p.FreeSpace < 0 {
@@ -310,6 +346,8 @@ func (i *Inspector) GetIndexedColumns(ctx context.Context, table string) (*Index
 		colTypes[c.Name] = c.Type
 	}
 
+	// a column used only in an index expression or predicate blocks HOT
+	// updates just like a plain key column
 	rows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("indexed-columns").Query(), table)
 	if err != nil {
 		return nil, fmt.Errorf("indexed columns: %w", err)
@@ -345,7 +383,10 @@ func (i *Inspector) GetIndexedColumns(ctx context.Context, table string) (*Index
 	}, nil
 }
 
-func (i *Inspector) ExecuteDemoUpdate(ctx context.Context, table, pk, column, newVal string) (*DemoUpdateResult, error) {
+// ExecuteDemoUpdate updates one column of a row and shows where the new
+// version went. With rollback, the update runs in a transaction that is
+// inspected from both sides and then rolled back.
+func (i *Inspector) ExecuteDemoUpdate(ctx context.Context, table, pk, column, newVal string, rollback bool) (*DemoUpdateResult, error) {
 	fail := func(msg string) *DemoUpdateResult {
 		return &DemoUpdateResult{Success: false, Error: msg}
 	}
@@ -381,6 +422,13 @@ func (i *Inspector) ExecuteDemoUpdate(ctx context.Context, table, pk, column, ne
 	_ = i.pool.QueryRow(ctx, q, pk).Scan(&oldVal)
 
 	before := tupleState(loc, beforeTuple)
+	reason, err := i.hotPrecheck(ctx, table, column, info.ColumnTypes[column], oldVal, newVal, beforePage, beforeTuple)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	if rollback {
+		return i.executeRollbackUpdate(ctx, table, column, oldVal, newVal, pk, info, loc, before, reason)
+	}
 
 	updateQ := fmt.Sprintf("UPDATE %s SET %s = $1 WHERE %s = $2", table, column, info.PKColumn)
 	if _, err := i.pool.Exec(ctx, updateQ, newVal, pk); err != nil {
@@ -437,7 +485,8 @@ func (i *Inspector) ExecuteDemoUpdate(ctx context.Context, table, pk, column, ne
 		updateType = "hot"
 	}
 
-	explanation := buildExplanation(isHot, samePage, isColumnIndexed, loc, newLoc, column)
+	reason.resolve(isHot, newTuple)
+	explanation := buildExplanation(isHot, samePage, loc, newLoc, reason)
 
 	return &DemoUpdateResult{
 		Success:         true,
@@ -451,6 +500,7 @@ func (i *Inspector) ExecuteDemoUpdate(ctx context.Context, table, pk, column, ne
 		NewTuple:        newState,
 		SamePage:        samePage,
 		Explanation:     explanation,
+		HotReason:       reason,
 	}, nil
 }
 
@@ -630,26 +680,26 @@ func tupleState(loc *RowLocation, t *HeapTuple) DemoTupleState {
 	}
 }
 
-func buildExplanation(isHot, samePage, isColumnIndexed bool, before, after *RowLocation, col string) string {
+func buildExplanation(isHot, samePage bool, before, after *RowLocation, r *HotReason) string {
 	if isHot {
-		if isColumnIndexed {
-			// This shouldn't happen - HOT with indexed column change
-			return fmt.Sprintf(
-				"🔥 HOT UPDATE (unexpected): column '%s' IS indexed but PostgreSQL used HOT. Value may be bitwise identical, or check if index exists. Page %d, lp %d→%d.",
-				col, before.Page, before.Item, after.Item)
-		}
-		return fmt.Sprintf(
-			"🔥 HOT UPDATE: column '%s' is not indexed, so no index update needed. New tuple on same page (%d), lp %d→%d.",
-			col, before.Page, before.Item, after.Item)
+		return fmt.Sprintf("🔥 HOT UPDATE: new tuple on the same page (%d), lp %d→%d, no index entries added. %s",
+			before.Page, before.Item, after.Item, r.Detail)
 	}
 	if samePage {
-		return fmt.Sprintf(
-			"📦 REGULAR UPDATE (same page %d): column '%s' is indexed, index was updated. lp %d→%d.",
-			before.Page, col, before.Item, after.Item)
+		return fmt.Sprintf("📦 REGULAR UPDATE (same page %d): lp %d→%d, indexes got entries for the new version. %s",
+			before.Page, before.Item, after.Item, r.Detail)
 	}
-	return fmt.Sprintf(
-		"📦 REGULAR UPDATE: tuple moved from page %d (lp=%d) to page %d (lp=%d). Index updated.",
-		before.Page, before.Item, after.Page, after.Item)
+	return fmt.Sprintf("📦 REGULAR UPDATE: tuple moved from page %d (lp=%d) to page %d (lp=%d). Indexes updated. %s",
+		before.Page, before.Item, after.Page, after.Item, r.Detail)
+}
+
+// setInfoMask fills in the fields derived from the raw infomask bits
+func (t *HeapTuple) setInfoMask(mask, mask2 int) {
+	t.InfoMask = decodeInfoMask(mask, mask2)
+	t.RawInfoMask, t.RawInfoMask2 = mask, mask2
+	t.IsHot = hasFlag(t.InfoMask, "HEAP_HOT_UPDATED") || hasFlag(t.InfoMask, "HEAP_ONLY_TUPLE")
+	t.IsUpdated = t.Xmax != 0 && !xmaxLockedOnly(mask) && mask&heapXmaxInvalid == 0
+	t.LockState = xmaxLockState(t.Xmax, mask, mask2)
 }
 
 func lpFlagsStr(f int) string {
@@ -691,6 +741,7 @@ func decodeInfoMask(m, m2 int) []string {
 		{0x8000, "HEAP_MOVED_IN"},
 	}
 	flags2 := []flag{
+		{0x2000, "HEAP_KEYS_UPDATED"},
 		{0x4000, "HEAP_HOT_UPDATED"},
 		{0x8000, "HEAP_ONLY_TUPLE"},
 	}
@@ -701,6 +752,10 @@ func decodeInfoMask(m, m2 int) []string {
 			out = append(out, f.name)
 		}
 	}
+	// committed and invalid together mean the xmin was frozen
+	if m&heapXminFrozen == heapXminFrozen {
+		out = append(out, "HEAP_XMIN_FROZEN")
+	}
 	for _, f := range flags2 {
 		if m2&f.mask != 0 {
 			out = append(out, f.name)
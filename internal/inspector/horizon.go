@@ -0,0 +1,284 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// HeapTupleSatisfiesVacuum results (heapam.h)
+const (
+	vacuumDead             = "DEAD"
+	vacuumLive             = "LIVE"
+	vacuumRecentlyDead     = "RECENTLY_DEAD"
+	vacuumInsertInProgress = "INSERT_IN_PROGRESS"
+	vacuumDeleteInProgress = "DELETE_IN_PROGRESS"
+)
+
+// GetCleanupHorizon works out the oldest XID VACUUM still has to keep row
+// versions for, and who is responsible for it. With a table, it also
+// classifies the tuples of one heap page against that horizon.
+func (i *Inspector) GetCleanupHorizon(ctx context.Context, table string, blockNo int) (*CleanupHorizon, error) {
+	c, err := i.newXactCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out, err := i.cleanupHorizon(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	if table == "" {
+		return out, nil
+	}
+	page, err := i.GetHeapPageDetail(ctx, table, blockNo)
+	if err != nil {
+		return nil, err
+	}
+	out.Table, out.BlockNo = table, blockNo
+	out.Tuples, err = i.vacuumStates(ctx, c, page.Tuples, out.Horizon)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (i *Inspector) cleanupHorizon(ctx context.Context, c *xactCache) (*CleanupHorizon, error) {
+	sources, err := i.horizonSources(ctx, c.ref)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &CleanupHorizon{NextXID: c.ref.Xmax, Horizon: c.ref.Xmax, Sources: sources}
+	for j, s := range sources {
+		if s.AffectsTables && s.Xid < out.Horizon {
+			out.Horizon = s.Xid
+			out.HeldBy = &sources[j]
+		}
+	}
+	if out.HeldBy != nil {
+		out.HeldBy.IsOldest = true
+	}
+	out.HorizonAge = out.NextXID - out.Horizon
+	out.Explanation = horizonExplanation(out)
+	return out, nil
+}
+
+func (i *Inspector) horizonSources(ctx context.Context, ref *Snapshot) ([]HorizonSource, error) {
+	var out []HorizonSource
+	add := func(s HorizonSource) {
+		s.Xid = ref.fullXid(s.Xid)
+		s.Age = ref.Xmax - s.Xid
+		out = append(out, s)
+	}
+
+	rows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("horizon-backends").Query())
+	if err != nil {
+		return nil, fmt.Errorf("horizon backends: %w", err)
+	}
+	for rows.Next() {
+		var pid int
+		var user, app, state, backendType, query string
+		var xid, xmin *int64
+		var since *time.Time
+		var sameDB bool
+		if err := rows.Scan(&pid, &user, &app, &state, &backendType, &xid, &xmin, &since, &query, &sameDB); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan backend: %w", err)
+		}
+		s := HorizonSource{
+			Kind:          "backend",
+			Name:          fmt.Sprintf("pid %d (%s, %s)", pid, user, state),
+			AffectsTables: sameDB,
+			Since:         since,
+			Detail:        query,
+		}
+		// a walsender's xmin is the standby's oldest snapshot, sent with
+		// hot_standby_feedback, and applies to every database
+		if backendType == "walsender" {
+			s.Kind = "standby_feedback"
+			s.Name = fmt.Sprintf("standby %s (pid %d)", app, pid)
+			s.AffectsTables = true
+			s.Detail = ""
+		}
+		if xid != nil {
+			s.Field, s.Xid = "backend_xid", *xid
+			add(s)
+		}
+		if xmin != nil && (xid == nil || *xmin != *xid) {
+			s.Field, s.Xid = "backend_xmin", *xmin
+			add(s)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = i.pool.Query(ctx, i.qs.MustHaveQuery("horizon-slots").Query())
+	if err != nil {
+		return nil, fmt.Errorf("horizon slots: %w", err)
+	}
+	for rows.Next() {
+		var name, typ string
+		var active bool
+		var xmin, catalogXmin *int64
+		if err := rows.Scan(&name, &typ, &active, &xmin, &catalogXmin); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan slot: %w", err)
+		}
+		s := HorizonSource{Kind: "replication_slot", Name: fmt.Sprintf("%s (%s)", name, typ)}
+		if !active {
+			s.Detail = "slot is inactive: nothing is consuming it"
+		}
+		if xmin != nil {
+			s.Field, s.Xid, s.AffectsTables = "xmin", *xmin, true
+			add(s)
+		}
+		// catalog_xmin only holds back system catalogs
+		if catalogXmin != nil {
+			s.Field, s.Xid, s.AffectsTables = "catalog_xmin", *catalogXmin, false
+			add(s)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = i.pool.Query(ctx, i.qs.MustHaveQuery("horizon-prepared").Query())
+	if err != nil {
+		return nil, fmt.Errorf("horizon prepared: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var gid, owner string
+		var xid int64
+		var prepared time.Time
+		var sameDB bool
+		if err := rows.Scan(&gid, &xid, &prepared, &owner, &sameDB); err != nil {
+			return nil, fmt.Errorf("scan prepared xact: %w", err)
+		}
+		add(HorizonSource{
+			Kind:          "prepared_xact",
+			Name:          fmt.Sprintf("%s (%s)", gid, owner),
+			Field:         "transaction",
+			Xid:           xid,
+			AffectsTables: sameDB,
+			Since:         &prepared,
+			Detail:        "run COMMIT PREPARED or ROLLBACK PREPARED to release it",
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(out, func(a, b int) bool { return out[a].Xid < out[b].Xid })
+	return out, nil
+}
+
+// vacuumStates classifies each tuple the way VACUUM would with the given
+// horizon
+func (i *Inspector) vacuumStates(ctx context.Context, c *xactCache, tuples []HeapTuple, horizon int64) ([]HorizonTuple, error) {
+	var xids []int64
+	for _, t := range tuples {
+		if t.LPFlags != lpNormal {
+			continue
+		}
+		xids = append(xids, c.ref.fullXid(t.Xmin))
+		if t.Xmax != 0 && t.RawInfoMask&heapXmaxIsMulti == 0 {
+			xids = append(xids, c.ref.fullXid(t.Xmax))
+		}
+		if t.Visibility != nil && t.Visibility.UpdateXid != 0 {
+			xids = append(xids, t.Visibility.UpdateXid)
+		}
+	}
+	if err := i.loadXactStatus(ctx, c, xids); err != nil {
+		return nil, err
+	}
+
+	var out []HorizonTuple
+	for _, t := range tuples {
+		if t.LPFlags != lpNormal {
+			continue
+		}
+		state, reason := satisfiesVacuum(c, &t, horizon)
+		out = append(out, HorizonTuple{LP: t.LP, Xmin: t.Xmin, Xmax: t.Xmax, State: state, Reason: reason})
+	}
+	return out, nil
+}
+
+// satisfiesVacuum follows HeapTupleSatisfiesVacuumHorizon
+func satisfiesVacuum(c *xactCache, t *HeapTuple, horizon int64) (string, string) {
+	mask := t.RawInfoMask
+	xmin := c.ref.fullXid(t.Xmin)
+
+	if mask&heapXminFrozen != heapXminFrozen && mask&heapXminCommitted == 0 {
+		switch c.status[xmin] {
+		case xactInProgress:
+			return vacuumInsertInProgress, fmt.Sprintf("inserting transaction %d is still running", xmin)
+		case xactAborted:
+			return vacuumDead, fmt.Sprintf("inserting transaction %d aborted: removable now", xmin)
+		}
+		if mask&heapXminInvalid != 0 {
+			return vacuumDead, fmt.Sprintf("inserting transaction %d aborted: removable now", xmin)
+		}
+	}
+
+	if t.Xmax == 0 || mask&heapXmaxInvalid != 0 {
+		return vacuumLive, "never deleted"
+	}
+	if xmaxLockedOnly(mask) {
+		return vacuumLive, "xmax only locks the row"
+	}
+
+	xmax := c.ref.fullXid(t.Xmax)
+	if mask&heapXmaxIsMulti != 0 {
+		if t.Visibility == nil || t.Visibility.UpdateXid == 0 {
+			return vacuumLive, "multixact xmax without an updater"
+		}
+		xmax = t.Visibility.UpdateXid
+	}
+
+	if mask&heapXmaxCommitted == 0 || mask&heapXmaxIsMulti != 0 {
+		switch c.status[xmax] {
+		case xactInProgress:
+			return vacuumDeleteInProgress, fmt.Sprintf("deleting transaction %d is still running", xmax)
+		case xactCommitted:
+		default:
+			return vacuumLive, fmt.Sprintf("deleting transaction %d aborted", xmax)
+		}
+	}
+
+	if xmax >= horizon {
+		return vacuumRecentlyDead, fmt.Sprintf("deleted by %d, but the horizon %d is older: some snapshot may still see it", xmax, horizon)
+	}
+	return vacuumDead, fmt.Sprintf("deleted by %d before the horizon %d: VACUUM can remove it", xmax, horizon)
+}
+
+func horizonExplanation(h *CleanupHorizon) string {
+	if h.HeldBy == nil {
+		return fmt.Sprintf("🟢 Nothing holds back cleanup: VACUUM can remove every row version deleted before XID %d.", h.NextXID)
+	}
+	s := h.HeldBy
+	out := fmt.Sprintf("⏳ VACUUM can only remove row versions deleted before XID %d, %d transactions behind the next XID. "+
+		"The horizon is held by %s %s (%s = %d)",
+		h.Horizon, h.HorizonAge, kindLabel(s.Kind), s.Name, s.Field, s.Xid)
+	if s.Since != nil {
+		out += ", open since " + s.Since.Format(time.RFC3339)
+	}
+	return out + ". Dead tuples newer than this stay on disk as RECENTLY_DEAD until it moves forward."
+}
+
+func kindLabel(kind string) string {
+	switch kind {
+	case "standby_feedback":
+		return "hot_standby_feedback from"
+	case "replication_slot":
+		return "replication slot"
+	case "prepared_xact":
+		return "prepared transaction"
+	}
+	return "backend"
+}
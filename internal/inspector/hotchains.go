@@ -0,0 +1,161 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// GetHotChains lists the HOT chains on a heap page, judged against snap (or
+// the current snapshot when nil). Roots without HOT updates are left out.
+func (i *Inspector) GetHotChains(ctx context.Context, table string, blk int, snap *Snapshot) (*PageHotChains, error) {
+	page, err := i.GetHeapPageAt(ctx, table, blk, snap)
+	if err != nil {
+		return nil, err
+	}
+	chains, orphans := buildHotChains(blk, page.Tuples)
+	out := &PageHotChains{Table: table, BlockNo: blk, Snapshot: page.Snapshot, Chains: []HotChain{}, Orphans: orphans}
+	for _, c := range chains {
+		if len(c.Members) > 1 || c.RootFlags != lpFlagsStr(lpNormal) || c.Break != "" {
+			out.Chains = append(out.Chains, c)
+		}
+	}
+	return out, nil
+}
+
+// FindHotChain returns the chain that the line pointer at (blk,item) is
+// part of, whether as root, redirect target or member.
+func (i *Inspector) FindHotChain(ctx context.Context, table string, blk, item int, snap *Snapshot) (*RowHotChain, error) {
+	page, err := i.GetHeapPageAt(ctx, table, blk, snap)
+	if err != nil {
+		return nil, err
+	}
+	out := &RowHotChain{Table: table, TID: fmt.Sprintf("(%d,%d)", blk, item)}
+	chains, _ := buildHotChains(blk, page.Tuples)
+	for _, c := range chains {
+		if c.Root == item || slices.ContainsFunc(c.Members, func(m HotChainMember) bool { return m.LP == item }) {
+			out.Found, out.Chain = true, &c
+			break
+		}
+	}
+	return out, nil
+}
+
+// FindHotChainByPK finds the chain holding the version of a row that the
+// current snapshot sees
+func (i *Inspector) FindHotChainByPK(ctx context.Context, table, pk string, snap *Snapshot) (*RowHotChain, error) {
+	loc, err := i.FindRowByPK(ctx, table, pk)
+	if err != nil {
+		return nil, err
+	}
+	if !loc.Found {
+		return &RowHotChain{Table: table}, nil
+	}
+	return i.FindHotChain(ctx, table, loc.Page, loc.Item, snap)
+}
+
+// buildHotChains follows every chain from its root the way
+// heap_hot_search_buffer does: through an LP_REDIRECT, then along t_ctid
+// while each version is HEAP_HOT_UPDATED and the next one's xmin matches.
+// Heap-only tuples no root reaches are returned as orphans.
+func buildHotChains(blk int, tuples []HeapTuple) ([]HotChain, []int) {
+	byLP := make(map[int]*HeapTuple, len(tuples))
+	for j := range tuples {
+		byLP[tuples[j].LP] = &tuples[j]
+	}
+
+	var chains []HotChain
+	visited := map[int]bool{}
+	for j := range tuples {
+		root := &tuples[j]
+		switch {
+		case root.LPFlags == lpRedirect, root.LPFlags == lpDead:
+		case root.LPFlags == lpNormal && root.RawInfoMask2&heapOnlyTuple == 0:
+		default:
+			continue
+		}
+		c := HotChain{BlockNo: blk, Root: root.LP, RootFlags: root.LPFlagsStr, Members: []HotChainMember{}}
+		if root.LPFlags == lpDead {
+			chains = append(chains, c)
+			continue
+		}
+
+		next := root
+		if root.LPFlags == lpRedirect {
+			c.RedirectTo = root.LPOffset
+			next = byLP[root.LPOffset]
+		}
+		var prev *HeapTuple
+		for {
+			if next == nil || next.LPFlags != lpNormal {
+				if prev == nil {
+					c.Break = fmt.Sprintf("redirect points at lp %d, which holds no tuple", c.RedirectTo)
+					c.BreakLP = c.RedirectTo
+				} else {
+					_, item, _ := parseCtid(prev.Ctid)
+					c.Break = fmt.Sprintf("lp %d's t_ctid points at lp %d, which no longer holds a tuple", prev.LP, item)
+					if prev.XmaxStatus == xactAborted {
+						c.Break = fmt.Sprintf("the update by aborted transaction %d left lp %d, since pruned", prev.Xmax, item)
+					}
+					c.BreakLP = item
+				}
+				break
+			}
+			if prev != nil && next.Xmin != prev.Xmax {
+				c.Break = fmt.Sprintf("lp %d has xmin %d, not lp %d's xmax %d: the slot was reused after pruning", next.LP, next.Xmin, prev.LP, prev.Xmax)
+				c.BreakLP = next.LP
+				break
+			}
+			if visited[next.LP] {
+				break
+			}
+			visited[next.LP] = true
+
+			m := HotChainMember{
+				LP:         next.LP,
+				Xmin:       next.Xmin,
+				Xmax:       next.Xmax,
+				XminStatus: next.XminStatus,
+				Ctid:       next.Ctid,
+				HeapOnly:   next.RawInfoMask2&heapOnlyTuple != 0,
+				HotUpdated: next.RawInfoMask2&heapHotUpdated != 0,
+				Visible:    next.IsLive,
+				Aborted:    next.XminStatus == xactAborted,
+			}
+			c.Members = append(c.Members, m)
+			if m.Visible {
+				c.VisibleLP = m.LP
+			}
+			if m.Aborted {
+				if prev != nil {
+					c.Break = fmt.Sprintf("lp %d was written by aborted transaction %d, so the chain really ends at lp %d", m.LP, m.Xmin, prev.LP)
+					c.BreakLP = m.LP
+				}
+				break
+			}
+
+			nb, item, ok := parseCtid(next.Ctid)
+			if !m.HotUpdated {
+				// a regular update leaves the chain for another page or slot
+				// that has its own index entries
+				if ok && next.IsUpdated && (nb != blk || item != next.LP) {
+					c.ContinuesAt = next.Ctid
+				}
+				break
+			}
+			if !ok || nb != blk || item == next.LP {
+				break
+			}
+			prev, next = next, byLP[item]
+		}
+		chains = append(chains, c)
+	}
+
+	orphans := []int{}
+	for _, t := range tuples {
+		if t.LPFlags == lpNormal && t.RawInfoMask2&heapOnlyTuple != 0 && !visited[t.LP] {
+			orphans = append(orphans, t.LP)
+		}
+	}
+	return chains, orphans
+}
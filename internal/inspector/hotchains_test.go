@@ -0,0 +1,138 @@
+package inspector
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestBuildHotChains(t *testing.T) {
+	// tuple with a t_ctid on block 0; mask2 carries the HOT bits
+	tup := func(lp int, xmin, xmax int64, next int, mask2 int) HeapTuple {
+		return HeapTuple{
+			LP: lp, LPFlags: lpNormal, LPFlagsStr: lpFlagsStr(lpNormal),
+			Xmin: xmin, Xmax: xmax, Ctid: fmt.Sprintf("(0,%d)", next),
+			RawInfoMask2: mask2, IsUpdated: xmax != 0, XminStatus: xactCommitted,
+		}
+	}
+	live := func(h HeapTuple) HeapTuple { h.IsLive = true; return h }
+	lp := func(lp, flags, offset int) HeapTuple {
+		return HeapTuple{LP: lp, LPFlags: flags, LPFlagsStr: lpFlagsStr(flags), LPOffset: offset}
+	}
+
+	type chain struct {
+		root, redirectTo int
+		members          []int
+		visibleLP        int
+		breakLP          int
+		continuesAt      string
+	}
+	tests := []struct {
+		name    string
+		tuples  []HeapTuple
+		want    []chain
+		orphans []int
+	}{
+		{
+			name:   "plain row",
+			tuples: []HeapTuple{live(tup(1, 100, 0, 1, 0))},
+			want:   []chain{{root: 1, members: []int{1}, visibleLP: 1}},
+		},
+		{
+			name: "hot updated twice",
+			tuples: []HeapTuple{
+				tup(1, 100, 101, 2, heapHotUpdated),
+				tup(2, 101, 102, 3, heapOnlyTuple|heapHotUpdated),
+				live(tup(3, 102, 0, 3, heapOnlyTuple)),
+			},
+			want: []chain{{root: 1, members: []int{1, 2, 3}, visibleLP: 3}},
+		},
+		{
+			name: "pruned root redirects",
+			tuples: []HeapTuple{
+				lp(1, lpRedirect, 3),
+				lp(2, lpUnused, 0),
+				live(tup(3, 102, 0, 3, heapOnlyTuple)),
+			},
+			want: []chain{{root: 1, redirectTo: 3, members: []int{3}, visibleLP: 3}},
+		},
+		{
+			name:   "redirect to an empty slot",
+			tuples: []HeapTuple{lp(1, lpRedirect, 4), lp(4, lpUnused, 0)},
+			want:   []chain{{root: 1, redirectTo: 4, members: []int{}, breakLP: 4}},
+		},
+		{
+			name:   "dead root",
+			tuples: []HeapTuple{lp(1, lpDead, 0)},
+			want:   []chain{{root: 1, members: []int{}}},
+		},
+		{
+			name: "successor pruned away",
+			tuples: []HeapTuple{
+				tup(1, 100, 101, 2, heapHotUpdated),
+				lp(2, lpUnused, 0),
+			},
+			want: []chain{{root: 1, members: []int{1}, breakLP: 2}},
+		},
+		{
+			name: "slot reused by another row",
+			tuples: []HeapTuple{
+				tup(1, 100, 101, 2, heapHotUpdated),
+				live(tup(2, 200, 0, 2, heapOnlyTuple)),
+			},
+			want:    []chain{{root: 1, members: []int{1}, breakLP: 2}},
+			orphans: []int{2},
+		},
+		{
+			name: "aborted hot update",
+			tuples: func() []HeapTuple {
+				old, upd := live(tup(1, 100, 101, 2, heapHotUpdated)), tup(2, 101, 0, 2, heapOnlyTuple)
+				old.XmaxStatus, upd.XminStatus = xactAborted, xactAborted
+				return []HeapTuple{old, upd}
+			}(),
+			want: []chain{{root: 1, members: []int{1, 2}, visibleLP: 1, breakLP: 2}},
+		},
+		{
+			name: "regular update to another page",
+			tuples: func() []HeapTuple {
+				old := tup(1, 100, 101, 0, 0)
+				old.Ctid = "(5,3)"
+				return []HeapTuple{old}
+			}(),
+			want: []chain{{root: 1, members: []int{1}, continuesAt: "(5,3)"}},
+		},
+		{
+			name: "heap-only tuple without a root",
+			tuples: []HeapTuple{
+				lp(1, lpUnused, 0),
+				live(tup(2, 101, 0, 2, heapOnlyTuple)),
+			},
+			orphans: []int{2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chains, orphans := buildHotChains(0, tt.tuples)
+			var got []chain
+			for _, c := range chains {
+				g := chain{root: c.Root, redirectTo: c.RedirectTo, members: []int{}, visibleLP: c.VisibleLP, breakLP: c.BreakLP, continuesAt: c.ContinuesAt}
+				for _, m := range c.Members {
+					g.members = append(g.members, m.LP)
+				}
+				if (c.Break != "") != (c.BreakLP != 0) {
+					t.Errorf("chain at lp %d: break %q with break lp %d", c.Root, c.Break, c.BreakLP)
+				}
+				got = append(got, g)
+			}
+			if !slices.EqualFunc(got, tt.want, func(a, b chain) bool {
+				return a.root == b.root && a.redirectTo == b.redirectTo && slices.Equal(a.members, b.members) &&
+					a.visibleLP == b.visibleLP && a.breakLP == b.breakLP && a.continuesAt == b.continuesAt
+			}) {
+				t.Errorf("chains = %+v, want %+v", got, tt.want)
+			}
+			if !slices.Equal(orphans, tt.orphans) {
+				t.Errorf("orphans = %v, want %v", orphans, tt.orphans)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	maxHeapTuplesPerPage = 291  // MaxHeapTuplesPerPage for 8kB pages
+	toastTupleThreshold  = 2032 // TOAST_TUPLE_THRESHOLD for 8kB pages
+
+	// summarizing indexes stopped blocking HOT in PostgreSQL 16
+	pgSummarizingHot = 160000
+)
+
+// hotPrecheck gathers what heap_update looks at before the new version
+// exists: which indexes cover the column, whether the value really
+// changes, and how much room the old version's page has.
+func (i *Inspector) hotPrecheck(ctx context.Context, table, column, colType, oldVal, newVal string, page *HeapPageDetail, old *HeapTuple) (*HotReason, error) {
+	r := &HotReason{
+		Column:             column,
+		BlockingIndexes:    []string{},
+		ExpressionIndexes:  []string{},
+		SummarizingIndexes: []string{},
+		PageFree:           page.Stats.FreeSpace,
+		OldTupleSize:       old.ItemLen,
+		LpCount:            page.Stats.LpCount,
+		MaxLps:             maxHeapTuplesPerPage,
+		ToastThreshold:     toastTupleThreshold,
+	}
+	if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("server-version-num").Query()).Scan(&r.ServerVersion); err != nil {
+		return nil, fmt.Errorf("server version: %w", err)
+	}
+	ff, err := i.tableFillFactor(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	r.FillFactor, r.ReservedSpace = ff, pageSize*(100-ff)/100
+
+	rows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("index-column-usage").Query(), table)
+	if err != nil {
+		return nil, fmt.Errorf("index column usage %s: %w", table, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var idx, am, col string
+		var direct bool
+		if err := rows.Scan(&idx, &am, &col, &direct); err != nil {
+			return nil, fmt.Errorf("scan index column usage: %w", err)
+		}
+		switch {
+		case col != column:
+		case am == "brin":
+			r.SummarizingIndexes = append(r.SummarizingIndexes, idx)
+		case direct:
+			r.BlockingIndexes = append(r.BlockingIndexes, idx)
+		default:
+			r.ExpressionIndexes = append(r.ExpressionIndexes, idx)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// heap_update compares the binary datums; the canonical text form is a
+	// close enough stand-in
+	var canon string
+	q := fmt.Sprintf("SELECT $1::%s::text", colType)
+	if err := i.pool.QueryRow(ctx, q, newVal).Scan(&canon); err != nil {
+		canon = newVal
+	}
+	r.ValueChanged = canon != oldVal
+
+	hdr, err := i.heapPageHeader(ctx, table, page.Stats.BlockNo)
+	if err != nil {
+		return nil, err
+	}
+	r.HeapFreeSpace = heapFreeSpace(hdr.free, page.Tuples)
+	return r, nil
+}
+
+// heapFreeSpace is PageGetHeapFreeSpace: room for one more tuple and its
+// line pointer, or none once the line pointer array is at its limit
+func heapFreeSpace(free int, tuples []HeapTuple) int {
+	if len(tuples) >= maxHeapTuplesPerPage && !slices.ContainsFunc(tuples, func(t HeapTuple) bool { return t.LPFlags == lpUnused }) {
+		return 0
+	}
+	return max(free-lpSize, 0)
+}
+
+// resolve settles the reason once the update is done and the new version
+// is known
+func (r *HotReason) resolve(isHot bool, newT *HeapTuple) {
+	r.Hot = isHot
+	if newT != nil {
+		r.NewTupleSize = maxAlign(newT.ItemLen)
+		r.Toasted = hasFlag(newT.InfoMask, "HEAP_HASEXTERNAL")
+	}
+	fits := r.NewTupleSize <= r.HeapFreeSpace
+	blocked := r.ValueChanged && (len(r.BlockingIndexes) > 0 || len(r.ExpressionIndexes) > 0 ||
+		len(r.SummarizingIndexes) > 0 && r.ServerVersion < pgSummarizingHot)
+
+	switch {
+	case isHot && !r.ValueChanged && (len(r.BlockingIndexes) > 0 || len(r.ExpressionIndexes) > 0):
+		r.Code = "hot_unchanged_value"
+		r.Detail = fmt.Sprintf("Column '%s' is indexed by %s, but the new value is identical to the old one, so no index key changed and HOT was allowed.",
+			r.Column, strings.Join(slices.Concat(r.BlockingIndexes, r.ExpressionIndexes), ", "))
+	case isHot && len(r.SummarizingIndexes) > 0 && r.ValueChanged:
+		r.Code = "hot_summarizing"
+		r.Detail = fmt.Sprintf("Column '%s' is only covered by summarizing (BRIN) indexes %s. Since PostgreSQL 16 those don't block HOT; they are just told about the new version.",
+			r.Column, strings.Join(r.SummarizingIndexes, ", "))
+	case isHot && !fits:
+		r.Code = "hot_after_prune"
+		r.Detail = fmt.Sprintf("The %d-byte new version didn't fit in the %d bytes free before the update, so the page must have been pruned when the UPDATE read it, freeing dead versions first.",
+			r.NewTupleSize, r.HeapFreeSpace)
+	case isHot:
+		r.Code = "hot"
+		r.Detail = fmt.Sprintf("Column '%s' is in no index and the %d-byte new version fit in the %d bytes free on the page.",
+			r.Column, r.NewTupleSize, r.HeapFreeSpace)
+	case blocked && len(r.BlockingIndexes) > 0:
+		r.Code = "indexed_column"
+		r.Detail = fmt.Sprintf("Column '%s' changed and is a column of %s, so every index needs an entry for the new version.",
+			r.Column, strings.Join(r.BlockingIndexes, ", "))
+	case blocked && len(r.ExpressionIndexes) > 0:
+		r.Code = "expression_index"
+		r.Detail = fmt.Sprintf("Column '%s' changed and is used in the expression or predicate of %s. Any change to a referenced column blocks HOT, even if the expression result stays the same.",
+			r.Column, strings.Join(r.ExpressionIndexes, ", "))
+	case blocked:
+		r.Code = "brin_pre16"
+		r.Detail = fmt.Sprintf("Column '%s' is only in BRIN indexes %s, but before PostgreSQL 16 (server is %d) any indexed column blocked HOT.",
+			r.Column, strings.Join(r.SummarizingIndexes, ", "), r.ServerVersion)
+	case r.HeapFreeSpace == 0 && r.LpCount >= r.MaxLps:
+		r.Code = "line_pointer_limit"
+		r.Detail = fmt.Sprintf("The page already has %d line pointers, the most a heap page can hold, and none is unused, so the new version had to go elsewhere.",
+			r.LpCount)
+	case !fits:
+		r.Code = "no_space"
+		r.Detail = fmt.Sprintf("No index blocked HOT, but the %d-byte new version didn't fit: the page had %d bytes usable (%d free by page stats).",
+			r.NewTupleSize, r.HeapFreeSpace, r.PageFree)
+		if r.FillFactor == 100 {
+			r.Detail += " The table's fillfactor is 100, so inserts filled the page completely; a lower fillfactor keeps room for HOT updates."
+		} else {
+			r.Detail += fmt.Sprintf(" fillfactor %d kept %d bytes per page free for updates, but it was already used up.", r.FillFactor, r.ReservedSpace)
+		}
+	default:
+		r.Code = "unknown"
+		r.Detail = fmt.Sprintf("No index blocked HOT and %d bytes were free for the %d-byte version, yet it went elsewhere. A concurrent insert may have taken the space while the value was being toasted.",
+			r.HeapFreeSpace, r.NewTupleSize)
+	}
+	if r.Toasted {
+		r.Detail += fmt.Sprintf(" The new version keeps values out of line in TOAST (rows over %d bytes get toasted), so only the %d-byte main tuple counted for page space; "+
+			"a changed TOAST value gets new chunks in the TOAST table whether or not the heap update is HOT.", r.ToastThreshold, r.NewTupleSize)
+	}
+}
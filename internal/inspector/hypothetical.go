@@ -0,0 +1,466 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	hypoSampleRows = 10000
+
+	// BTMaxItemSize for 8kB pages
+	btMaxItemSize = 2704
+)
+
+// types without a btree equalimage support function never deduplicate
+var noDedupTypes = map[string]bool{
+	"numeric":  true,
+	"float4":   true,
+	"float8":   true,
+	"interval": true,
+	"jsonb":    true,
+}
+
+type columnStats struct {
+	name      string
+	typName   string
+	typLen    int
+	typAlign  string
+	avgWidth  int
+	nullFrac  float64
+	nDistinct float64
+}
+
+type sampleSize struct {
+	tuple int
+	key   int
+}
+
+func (i *Inspector) getColumnStats(ctx context.Context, table string) (map[string]columnStats, error) {
+	rows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("column-stats").Query(), table)
+	if err != nil {
+		return nil, fmt.Errorf("column stats %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]columnStats)
+	for rows.Next() {
+		var c columnStats
+		if err := rows.Scan(&c.name, &c.typName, &c.typLen, &c.typAlign, &c.avgWidth, &c.nullFrac, &c.nDistinct); err != nil {
+			return nil, fmt.Errorf("scan column stats: %w", err)
+		}
+		out[c.name] = c
+	}
+	return out, rows.Err()
+}
+
+func (i *Inspector) EstimateHypotheticalIndex(ctx context.Context, table string, spec HypotheticalIndexSpec) (*HypotheticalIndex, error) {
+	if len(spec.Columns) == 0 {
+		return nil, fmt.Errorf("at least one key column required")
+	}
+	fillfactor := spec.Fillfactor
+	if fillfactor == 0 {
+		fillfactor = btDefaultFillfactor
+	}
+	if fillfactor < 10 || fillfactor > 100 {
+		return nil, fmt.Errorf("fillfactor must be between 10 and 100")
+	}
+
+	stats, err := i.getColumnStats(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		return nil, fmt.Errorf("table %s not found", table)
+	}
+
+	var keyCols, allCols []columnStats
+	for _, name := range spec.Columns {
+		c, ok := stats[name]
+		if !ok {
+			return nil, fmt.Errorf("column %s not found", name)
+		}
+		keyCols = append(keyCols, c)
+	}
+	allCols = append(allCols, keyCols...)
+	for _, name := range spec.Include {
+		c, ok := stats[name]
+		if !ok {
+			return nil, fmt.Errorf("column %s not found", name)
+		}
+		allCols = append(allCols, c)
+	}
+
+	// the predicate is rebuilt from its parsed terms, so no user text
+	// reaches the server as SQL
+	terms, err := parsePredicate(spec.Predicate, stats)
+	if err != nil {
+		return nil, err
+	}
+	spec.Predicate = renderPredicate(terms)
+
+	out := &HypotheticalIndex{
+		TableName:  table,
+		Definition: hypotheticalDefinition(table, spec, fillfactor),
+		Fillfactor: fillfactor,
+	}
+
+	tables, err := i.ListTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var info TableInfo
+	for _, t := range tables {
+		if t.Name == table {
+			info = t
+			break
+		}
+	}
+
+	tx, err := i.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	out.TableRows = info.RowCount
+	if out.TableRows < 0 || (out.TableRows == 0 && info.TotalPages > 0) {
+		// never analyzed, count instead
+		if err := tx.QueryRow(ctx, fmt.Sprintf("SELECT count(*) FROM %s", table)).Scan(&out.TableRows); err != nil {
+			return nil, fmt.Errorf("count %s: %w", table, err)
+		}
+		out.Warnings = append(out.Warnings, "table has no statistics, run ANALYZE for better width and distinct estimates")
+	}
+
+	samples, matched, seen, err := sampleIndexTuples(ctx, tx, table, spec.Predicate, keyCols, allCols, out.TableRows)
+	if err != nil {
+		return nil, err
+	}
+	out.SampleRows = seen
+
+	out.Selectivity = 1
+	if spec.Predicate != "" && seen > 0 {
+		out.Selectivity = float64(matched) / float64(seen)
+	}
+	out.IndexedRows = int64(float64(out.TableRows)*out.Selectivity + 0.5)
+
+	if len(samples) == 0 {
+		// nothing sampled, fall back to pg_stats average widths
+		s := sampleSize{tuple: avgIndexTupleSize(allCols), key: avgIndexTupleSize(keyCols)}
+		samples = append(samples, s)
+		if out.IndexedRows > 0 {
+			out.Warnings = append(out.Warnings, "no rows sampled, sizes based on pg_stats average widths")
+		}
+	}
+
+	var sumTuple, sumKey int
+	maxTuple := 0
+	for _, s := range samples {
+		sumTuple += s.tuple
+		sumKey += s.key
+		if s.tuple > maxTuple {
+			maxTuple = s.tuple
+		}
+	}
+	out.AvgTupleSize = float64(sumTuple) / float64(len(samples))
+	out.AvgKeySize = float64(sumKey) / float64(len(samples))
+	if maxTuple > btMaxItemSize {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("sampled tuples up to %d bytes exceed the %d byte B-tree limit, CREATE INDEX would fail", maxTuple, btMaxItemSize))
+	}
+
+	out.DistinctKeys = out.IndexedRows
+	if !spec.Unique {
+		distinct := 1.0
+		for _, c := range keyCols {
+			d := c.distinct(out.TableRows)
+			if d == 0 {
+				out.Warnings = append(out.Warnings, fmt.Sprintf("no n_distinct for %s, assuming unique keys", c.name))
+				distinct = float64(out.IndexedRows)
+				break
+			}
+			distinct *= d
+		}
+		// compared as floats: the product of several near-unique columns
+		// can pass MaxInt64
+		if distinct < float64(out.DistinctKeys) {
+			out.DistinctKeys = int64(distinct)
+		}
+	}
+	if out.DistinctKeys < 1 && out.IndexedRows > 0 {
+		out.DistinctKeys = 1
+	}
+
+	out.Deduplicate = !spec.Unique && len(spec.Include) == 0
+	for _, c := range keyCols {
+		if noDedupTypes[c.typName] || strings.HasPrefix(c.typName, "_") {
+			out.Deduplicate = false
+		}
+	}
+
+	shape := packBTree(hypotheticalTuples(out.IndexedRows, out.DistinctKeys, samples, out.Deduplicate), fillfactor)
+	out.LeafTuples = shape.LeafTuples
+	out.LeafPages = shape.LeafPages
+	out.InternalPages = shape.InternalPages
+	out.Height = shape.Levels
+	if shape.Levels > 0 {
+		out.TreeLevel = shape.Levels - 1
+	}
+	out.TotalPages = shape.totalPages()
+	out.Size = out.TotalPages * pageSize
+
+	return out, nil
+}
+
+// sampleIndexTuples reads a Bernoulli sample of the heap and computes the
+// index tuple size every matching row would produce.
+func sampleIndexTuples(ctx context.Context, tx pgx.Tx, table, predicate string, keyCols, allCols []columnStats, rows int64) ([]sampleSize, int, int, error) {
+	if rows == 0 {
+		return nil, 0, 0, nil
+	}
+
+	pct := 100.0
+	if rows > hypoSampleRows {
+		pct = 100.0 * hypoSampleRows / float64(rows)
+	}
+
+	pred := "true"
+	if predicate != "" {
+		pred = "(" + predicate + ")"
+	}
+	exprs := make([]string, len(allCols))
+	for j, c := range allCols {
+		exprs[j] = fmt.Sprintf("pg_column_size(%s)", c.name)
+	}
+
+	q := fmt.Sprintf("SELECT COALESCE(%s, false), %s FROM %s TABLESAMPLE BERNOULLI(%g) LIMIT %d",
+		pred, strings.Join(exprs, ", "), table, pct, hypoSampleRows)
+	res, err := tx.Query(ctx, q)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("sample %s: %w", table, err)
+	}
+	defer res.Close()
+
+	var out []sampleSize
+	var matched, seen int
+	for res.Next() {
+		var match bool
+		sizes := make([]*int, len(allCols))
+		targets := []any{&match}
+		for j := range sizes {
+			targets = append(targets, &sizes[j])
+		}
+		if err := res.Scan(targets...); err != nil {
+			return nil, 0, 0, fmt.Errorf("scan sample: %w", err)
+		}
+		seen++
+		if !match {
+			continue
+		}
+		matched++
+		out = append(out, sampleSize{
+			tuple: indexTupleSize(allCols, sizes),
+			key:   indexTupleSize(keyCols, sizes[:len(keyCols)]),
+		})
+	}
+	return out, matched, seen, res.Err()
+}
+
+// hypotheticalTuples streams the leaf tuples of the would-be index: distinct
+// keys in order, each carrying its share of heap TIDs, sizes cycling through
+// the sampled rows.
+func hypotheticalTuples(rows, distinct int64, samples []sampleSize, dedup bool) func() (btreeTuple, bool) {
+	var key int64
+	var pending []int
+	var cur sampleSize
+
+	per, extra := int64(0), int64(0)
+	if distinct > 0 {
+		per, extra = rows/distinct, rows%distinct
+	}
+
+	return func() (btreeTuple, bool) {
+		for len(pending) == 0 {
+			if key >= distinct {
+				return btreeTuple{}, false
+			}
+			ntids := per
+			if key < extra {
+				ntids++
+			}
+			cur = samples[key%int64(len(samples))]
+			key++
+			postingTuples(cur.tuple, ntids, dedup, func(size int) {
+				pending = append(pending, size)
+			})
+		}
+		size := pending[0]
+		pending = pending[1:]
+		return btreeTuple{size: size, pivot: cur.key}, true
+	}
+}
+
+func (c columnStats) distinct(rows int64) float64 {
+	switch {
+	case c.nDistinct > 0:
+		return c.nDistinct
+	case c.nDistinct < 0:
+		return -c.nDistinct * float64(rows)
+	}
+	return 0
+}
+
+// indexTupleSize lays the datums out like index_form_tuple: header, optional
+// null bitmap, each datum aligned to its type (short varlenas unaligned).
+func indexTupleSize(cols []columnStats, sizes []*int) int {
+	hdr := btTupleHeader
+	for _, s := range sizes {
+		if s == nil {
+			hdr = btTupleHeaderNulls
+			break
+		}
+	}
+	off := hdr
+	for j, c := range cols {
+		if sizes[j] == nil {
+			continue
+		}
+		off = alignDatum(off, c, *sizes[j]) + *sizes[j]
+	}
+	return maxAlign(off)
+}
+
+func avgIndexTupleSize(cols []columnStats) int {
+	sizes := make([]*int, len(cols))
+	for j := range cols {
+		w := cols[j].avgWidth
+		sizes[j] = &w
+	}
+	return indexTupleSize(cols, sizes)
+}
+
+func alignDatum(off int, c columnStats, size int) int {
+	if c.typLen == -1 && size <= 127 {
+		return off
+	}
+	return alignTo(off, c.typAlign)
+}
+
+// alignTo applies a pg_type.typalign code to an offset
+func alignTo(off int, typAlign string) int {
+	a := 1
+	switch typAlign {
+	case "s":
+		a = 2
+	case "i":
+		a = 4
+	case "d":
+		a = 8
+	}
+	return (off + a - 1) &^ (a - 1)
+}
+
+func hypotheticalDefinition(table string, spec HypotheticalIndexSpec, fillfactor int) string {
+	var b strings.Builder
+	b.WriteString("CREATE ")
+	if spec.Unique {
+		b.WriteString("UNIQUE ")
+	}
+	fmt.Fprintf(&b, "INDEX ON %s (%s)", table, strings.Join(spec.Columns, ", "))
+	if len(spec.Include) > 0 {
+		fmt.Fprintf(&b, " INCLUDE (%s)", strings.Join(spec.Include, ", "))
+	}
+	if fillfactor != btDefaultFillfactor {
+		fmt.Fprintf(&b, " WITH (fillfactor = %d)", fillfactor)
+	}
+	if spec.Predicate != "" {
+		fmt.Fprintf(&b, " WHERE %s", spec.Predicate)
+	}
+	return b.String()
+}
+
+// predicateTerm is one comparison of a partial index predicate
+type predicateTerm struct {
+	column string
+	op     string
+	value  string // a rendered literal, empty for IS [NOT] NULL
+}
+
+var predicateToken = regexp.MustCompile(`^\s*(?:([A-Za-z_][A-Za-z0-9_]*)|(-?[0-9]+(?:\.[0-9]+)?)|('(?:[^']|'')*')|(<>|!=|<=|>=|=|<|>))`)
+
+// parsePredicate accepts column comparisons with literals joined by AND,
+// e.g. "status = 'active' AND amount > 100 AND deleted_at IS NULL"
+func parsePredicate(pred string, stats map[string]columnStats) ([]predicateTerm, error) {
+	type token struct{ ident, number, str, op string }
+	var toks []token
+	rest := strings.TrimSpace(pred)
+	for rest != "" {
+		m := predicateToken.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, fmt.Errorf("predicate: unexpected %q; use column comparisons with literals joined by AND", rest)
+		}
+		toks = append(toks, token{m[1], m[2], m[3], m[4]})
+		rest = strings.TrimSpace(rest[len(m[0]):])
+	}
+
+	keyword := func(j int, kw string) bool { return j < len(toks) && strings.EqualFold(toks[j].ident, kw) }
+	var out []predicateTerm
+	for j := 0; j < len(toks); {
+		if len(out) > 0 {
+			if !keyword(j, "AND") {
+				return nil, fmt.Errorf("predicate: terms must be joined by AND")
+			}
+			j++
+		}
+		if j >= len(toks) || toks[j].ident == "" {
+			return nil, fmt.Errorf("predicate: expected a column name")
+		}
+		t := predicateTerm{column: toks[j].ident}
+		if _, ok := stats[t.column]; !ok {
+			return nil, fmt.Errorf("predicate: column %s not found", t.column)
+		}
+		j++
+
+		switch {
+		case keyword(j, "IS") && keyword(j+1, "NULL"):
+			t.op, j = "IS NULL", j+2
+		case keyword(j, "IS") && keyword(j+1, "NOT") && keyword(j+2, "NULL"):
+			t.op, j = "IS NOT NULL", j+3
+		case j+1 < len(toks) && toks[j].op != "":
+			t.op = toks[j].op
+			if t.op == "!=" {
+				t.op = "<>"
+			}
+			v := toks[j+1]
+			switch {
+			case v.number != "":
+				t.value = v.number
+			case v.str != "":
+				t.value = v.str
+			case keyword(j+1, "true"), keyword(j+1, "false"):
+				t.value = strings.ToLower(v.ident)
+			default:
+				return nil, fmt.Errorf("predicate: %s %s must be compared with a literal", t.column, t.op)
+			}
+			j += 2
+		default:
+			return nil, fmt.Errorf("predicate: expected an operator after %s", t.column)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func renderPredicate(terms []predicateTerm) string {
+	parts := make([]string, len(terms))
+	for j, t := range terms {
+		parts[j] = t.column + " " + t.op
+		if t.value != "" {
+			parts[j] += " " + t.value
+		}
+	}
+	return strings.Join(parts, " AND ")
+}
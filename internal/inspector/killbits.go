@@ -0,0 +1,503 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const killBitsMaxRounds = 50
+
+func (i *Inspector) indexByName(ctx context.Context, indexName string) (*IndexInfo, error) {
+	all, err := i.ListIndexes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, idx := range all {
+		if idx.Name != indexName {
+			continue
+		}
+		indexes, err := i.GetTableIndexes(ctx, idx.TableName)
+		if err != nil {
+			return nil, err
+		}
+		for _, ti := range indexes {
+			if ti.Name == indexName {
+				return &ti, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("index %s not found", indexName)
+}
+
+// ExecuteKillBitsDemo shows how index entries pointing to dead heap tuples
+// get marked LP_DEAD by a scan ("scan" step) and are later removed when a
+// full leaf page needs room ("delete" step).
+func (i *Inspector) ExecuteKillBitsDemo(ctx context.Context, indexName, step string, rows int) (*KillBitsDemoResult, error) {
+	fail := func(msg string) *KillBitsDemoResult {
+		return &KillBitsDemoResult{Success: false, Error: msg, Step: step}
+	}
+	if rows <= 0 {
+		rows = 20
+	}
+	if rows > 200 {
+		rows = 200
+	}
+
+	idx, err := i.indexByName(ctx, indexName)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	if len(idx.KeyColumns) == 0 || slices.Contains(idx.Expressions, idx.KeyColumns[0]) {
+		return fail("leading index column must be a plain table column"), nil
+	}
+
+	res := &KillBitsDemoResult{
+		Success: true,
+		Step:    step,
+		Index:   indexName,
+		Table:   idx.TableName,
+		Column:  idx.KeyColumns[0],
+	}
+
+	// the rows are churned on a scratch copy so triggers, foreign keys and
+	// identity columns of the real table never get involved
+	scratch := fmt.Sprintf("pgstoviz_exp_%d", time.Now().UnixNano())
+	create := fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING ALL) WITH (autovacuum_enabled = false)", scratch, idx.TableName)
+	if _, err := i.pool.Exec(ctx, create); err != nil {
+		return fail(fmt.Sprintf("create scratch table: %v", err)), nil
+	}
+	defer i.pool.Exec(context.WithoutCancel(ctx), fmt.Sprintf("DROP TABLE IF EXISTS %s", scratch))
+
+	copyQ := fmt.Sprintf("INSERT INTO %s OVERRIDING SYSTEM VALUE SELECT * FROM %s ORDER BY %s LIMIT %d", scratch, idx.TableName, res.Column, experimentMaxRows)
+	if _, err := i.pool.Exec(ctx, copyQ); err != nil {
+		return fail(fmt.Sprintf("copy rows: %v", err)), nil
+	}
+	if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("scratch-index-twin").Query(), indexName, scratch).Scan(&res.ScratchIndex); err != nil {
+		return fail(fmt.Sprintf("find copy of %s: %v", indexName, err)), nil
+	}
+	res.Scratch = scratch
+
+	q := fmt.Sprintf("SELECT min(%[1]s)::text, max(%[1]s)::text FROM (SELECT %[1]s FROM %[2]s WHERE %[1]s IS NOT NULL ORDER BY %[1]s LIMIT $1) s",
+		res.Column, res.Scratch)
+	var lo, hi *string
+	if err := i.pool.QueryRow(ctx, q, rows).Scan(&lo, &hi); err != nil || lo == nil {
+		return fail("table has no rows to work with"), nil
+	}
+	res.KeyFrom, res.KeyTo = *lo, *hi
+
+	switch step {
+	case "scan":
+		err = i.killBitsScan(ctx, res)
+	case "delete":
+		err = i.killBitsDelete(ctx, res)
+	default:
+		return fail("step must be scan or delete"), nil
+	}
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	return res, nil
+}
+
+func (i *Inspector) killBitsScan(ctx context.Context, res *KillBitsDemoResult) error {
+	oldTids, err := i.rowTids(ctx, res.Scratch, res.Column, res.KeyFrom, res.KeyTo)
+	if err != nil {
+		return err
+	}
+	res.LeafPages, err = i.leafPagesFor(ctx, res.ScratchIndex, oldTids)
+	if err != nil {
+		return err
+	}
+
+	if res.DeadVersions, res.ChurnXid, err = i.churnRows(ctx, res.Scratch, res.Column, res.KeyFrom, res.KeyTo); err != nil {
+		return err
+	}
+	if err := i.killBitsHorizon(ctx, res); err != nil {
+		return err
+	}
+
+	before, err := i.snapshotLeafItems(ctx, res.ScratchIndex, res.LeafPages)
+	if err != nil {
+		return err
+	}
+	if err := i.indexScan(ctx, res.Scratch, res.Column, res.KeyFrom, res.KeyTo); err != nil {
+		return err
+	}
+	after, err := i.snapshotLeafItems(ctx, res.ScratchIndex, res.LeafPages)
+	if err != nil {
+		return err
+	}
+
+	wasDead := make(map[string]bool)
+	for blk, items := range before {
+		for _, it := range items {
+			wasDead[fmt.Sprintf("%d/%s", blk, it.Htid)] = it.Dead
+			if it.Dead {
+				res.DeadItemsBefore++
+			}
+		}
+	}
+	for _, blk := range res.LeafPages {
+		for _, it := range after[blk] {
+			if !it.Dead {
+				continue
+			}
+			res.DeadItemsAfter++
+			if dead, ok := wasDead[fmt.Sprintf("%d/%s", blk, it.Htid)]; ok && !dead {
+				res.NewlyDead = append(res.NewlyDead, itemChange(blk, it, false))
+			}
+		}
+	}
+
+	res.Explanation = fmt.Sprintf(
+		"🪦 %d rows of a scratch copy of %s were deleted and re-inserted (xid %d), leaving %d dead heap versions still referenced from the index. ",
+		res.DeadVersions, res.Table, res.ChurnXid, res.DeadVersions)
+	switch {
+	case !res.Killable:
+		res.Explanation += horizonHeldText(res) +
+			" The index scan found the old versions only recently dead and left their index items alone; no LP_DEAD bits could be set."
+	case len(res.NewlyDead) == 0:
+		res.Explanation += "The old versions were dead to everyone, but the index scan marked no items: the leaf pages may have changed " +
+			"since the scan's snapshot, or another scan got there first."
+	default:
+		res.Explanation += fmt.Sprintf(
+			"The index scan visited them, found every version dead to all transactions (the cleanup horizon %d is past xid %d) "+
+				"and set LP_DEAD on %d index items (the \"dead\" flag). "+
+				"Later scans skip these items without visiting the heap; the space is reclaimed by the next deletion pass on the page.",
+			res.Horizon, res.ChurnXid, len(res.NewlyDead))
+	}
+	return nil
+}
+
+// killBitsHorizon records whether the versions left behind by the churn are
+// already dead to every snapshot, the condition for kill_prior_tuple
+func (i *Inspector) killBitsHorizon(ctx context.Context, res *KillBitsDemoResult) error {
+	c, err := i.newXactCache(ctx)
+	if err != nil {
+		return err
+	}
+	h, err := i.cleanupHorizon(ctx, c)
+	if err != nil {
+		return err
+	}
+	res.Horizon, res.HeldBy = h.Horizon, h.HeldBy
+	res.Killable = res.ChurnXid < h.Horizon
+	return nil
+}
+
+func horizonHeldText(res *KillBitsDemoResult) string {
+	out := fmt.Sprintf("The cleanup horizon %d isn't past xid %d", res.Horizon, res.ChurnXid)
+	if res.HeldBy != nil {
+		out += fmt.Sprintf(" (held back by %s %s)", res.HeldBy.Kind, res.HeldBy.Name)
+	}
+	return out + ", so some snapshot may still see the deleted versions."
+}
+
+func (i *Inspector) killBitsDelete(ctx context.Context, res *KillBitsDemoResult) error {
+	tids, err := i.rowTids(ctx, res.Scratch, res.Column, res.KeyFrom, res.KeyTo)
+	if err != nil {
+		return err
+	}
+	if res.LeafPages, err = i.leafPagesFor(ctx, res.ScratchIndex, tids); err != nil {
+		return err
+	}
+
+	before, err := i.snapshotLeafItems(ctx, res.ScratchIndex, res.LeafPages)
+	if err != nil {
+		return err
+	}
+	nextBefore := make(map[int]int)
+	for _, blk := range res.LeafPages {
+		if s, err := i.GetPageStats(ctx, res.ScratchIndex, blk); err == nil {
+			nextBefore[blk] = s.BtpoNext
+		}
+	}
+
+	// keep adding versions of the same keys to the page until it runs out of
+	// room and nbtree has to delete (or split) to make space
+	var after map[int][]PageItem
+	pages := res.LeafPages
+	for res.Rounds = 1; res.Rounds <= killBitsMaxRounds; res.Rounds++ {
+		n, xid, err := i.churnRows(ctx, res.Scratch, res.Column, res.KeyFrom, res.KeyTo)
+		if err != nil {
+			return err
+		}
+		res.DeadVersions += n
+		res.ChurnXid = xid
+		if err := i.indexScan(ctx, res.Scratch, res.Column, res.KeyFrom, res.KeyTo); err != nil {
+			return err
+		}
+
+		pages = slices.Clone(res.LeafPages)
+		for _, blk := range res.LeafPages {
+			s, err := i.GetPageStats(ctx, res.ScratchIndex, blk)
+			if err != nil {
+				return err
+			}
+			if s.BtpoNext != nextBefore[blk] {
+				res.PageSplit = true
+				pages = append(pages, s.BtpoNext)
+			}
+		}
+		if after, err = i.snapshotLeafItems(ctx, res.ScratchIndex, pages); err != nil {
+			return err
+		}
+		if removedItems(before, after) > 0 || res.PageSplit {
+			break
+		}
+	}
+	if res.Rounds > killBitsMaxRounds {
+		res.Rounds = killBitsMaxRounds
+	}
+	if err := i.killBitsHorizon(ctx, res); err != nil {
+		return err
+	}
+
+	present := make(map[string]bool)
+	for _, blk := range pages {
+		for _, it := range after[blk] {
+			if it.Dead {
+				res.DeadItemsAfter++
+			}
+			for _, t := range itemTids(it) {
+				present[t] = true
+			}
+		}
+	}
+	bottomUp := false
+	for _, blk := range res.LeafPages {
+		for _, it := range before[blk] {
+			if it.Dead {
+				res.DeadItemsBefore++
+			}
+			gone := true
+			for _, t := range itemTids(it) {
+				if present[t] {
+					gone = false
+				}
+			}
+			if gone {
+				res.Removed = append(res.Removed, itemChange(blk, it, it.Dead))
+				if !it.Dead {
+					bottomUp = true
+				}
+			}
+		}
+	}
+
+	switch {
+	case len(res.Removed) > 0 && bottomUp:
+		res.Explanation = fmt.Sprintf(
+			"🧹 After %d rounds the leaf page filled up. Before splitting, nbtree ran a deletion pass: LP_DEAD items were removed (simple deletion) "+
+				"and entries without the LP_DEAD bit were checked against the heap and removed too (bottom-up deletion). %d items were removed.",
+			res.Rounds, len(res.Removed))
+	case len(res.Removed) > 0:
+		res.Explanation = fmt.Sprintf(
+			"🧹 After %d rounds the leaf page filled up. Instead of splitting, nbtree ran a simple deletion pass and physically removed the %d items "+
+				"previously marked LP_DEAD by index scans, freeing room for the new entries.",
+			res.Rounds, len(res.Removed))
+	case res.PageSplit:
+		res.Explanation = fmt.Sprintf(
+			"✂️ After %d rounds the page split: deleting LP_DEAD items could not free enough space for the incoming entries.", res.Rounds)
+	case !res.Killable:
+		res.Explanation = fmt.Sprintf("⏳ %d rounds did not free anything on the leaf page. ", res.Rounds) + horizonHeldText(res) +
+			" Neither scans nor bottom-up deletion may remove index entries whose heap versions are only recently dead."
+	default:
+		res.Explanation = fmt.Sprintf(
+			"⏳ %d rounds did not fill the leaf page yet, no deletion pass was needed. Run the step again or with more rows.", res.Rounds)
+	}
+	res.Explanation += " All of this ran on a scratch copy of the table, dropped afterwards; the real table was not touched."
+	return nil
+}
+
+// churnRows deletes and re-inserts the rows in one statement, leaving the
+// old heap versions dead while their index entries stay behind. It returns
+// the number of rows and the xid that deleted them.
+func (i *Inspector) churnRows(ctx context.Context, table, col, lo, hi string) (int, int64, error) {
+	tx, err := i.pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	var xid int64
+	if err := tx.QueryRow(ctx, i.qs.MustHaveQuery("current-xact-id").Query()).Scan(&xid); err != nil {
+		return 0, 0, fmt.Errorf("xid: %w", err)
+	}
+	q := fmt.Sprintf("WITH d AS (DELETE FROM %[1]s WHERE %[2]s BETWEEN $1 AND $2 RETURNING *) INSERT INTO %[1]s OVERRIDING SYSTEM VALUE SELECT * FROM d",
+		table, col)
+	tag, err := tx.Exec(ctx, q, lo, hi)
+	if err != nil {
+		return 0, 0, fmt.Errorf("churn rows: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, fmt.Errorf("commit: %w", err)
+	}
+	return int(tag.RowsAffected()), xid, nil
+}
+
+// indexScan reads the key range through a plain index scan so kill_prior_tuple
+// can mark entries whose heap tuples are dead to everyone
+func (i *Inspector) indexScan(ctx context.Context, table, col, lo, hi string) error {
+	tx, err := i.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, s := range []string{
+		"SET LOCAL enable_seqscan = off",
+		"SET LOCAL enable_bitmapscan = off",
+		"SET LOCAL enable_indexonlyscan = off",
+	} {
+		if _, err := tx.Exec(ctx, s); err != nil {
+			return fmt.Errorf("%s: %w", s, err)
+		}
+	}
+
+	q := fmt.Sprintf("SELECT count(*) FROM (SELECT * FROM %s WHERE %s BETWEEN $1 AND $2) s", table, col)
+	var n int
+	if err := tx.QueryRow(ctx, q, lo, hi).Scan(&n); err != nil {
+		return fmt.Errorf("index scan: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+func (i *Inspector) rowTids(ctx context.Context, table, col, lo, hi string) (map[string]bool, error) {
+	rows, err := i.pool.Query(ctx, fmt.Sprintf("SELECT ctid::text FROM %s WHERE %s BETWEEN $1 AND $2", table, col), lo, hi)
+	if err != nil {
+		return nil, fmt.Errorf("row tids: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]bool)
+	for rows.Next() {
+		var tid string
+		if err := rows.Scan(&tid); err != nil {
+			return nil, fmt.Errorf("scan tid: %w", err)
+		}
+		out[tid] = true
+	}
+	return out, rows.Err()
+}
+
+// leafPagesFor returns the leaf pages holding any of the heap TIDs
+func (i *Inspector) leafPagesFor(ctx context.Context, indexName string, tids map[string]bool) ([]int, error) {
+	var numPages int
+	if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("index-block-count").Query(), indexName).Scan(&numPages); err != nil {
+		return nil, fmt.Errorf("block count %s: %w", indexName, err)
+	}
+	if numPages <= 1 {
+		return nil, nil
+	}
+
+	rows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("bt-leaf-tids").Query(), indexName, numPages)
+	if err != nil {
+		return nil, fmt.Errorf("leaf tids %s: %w", indexName, err)
+	}
+	defer rows.Close()
+
+	seen := make(map[int]bool)
+	for rows.Next() {
+		var blk int
+		var htid, list string
+		if err := rows.Scan(&blk, &htid, &list); err != nil {
+			return nil, fmt.Errorf("scan leaf tid: %w", err)
+		}
+		for _, t := range itemTids(PageItem{Htid: htid, Tids: list}) {
+			if tids[t] {
+				seen[blk] = true
+				break
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	out := make([]int, 0, len(seen))
+	for blk := range seen {
+		out = append(out, blk)
+	}
+	sort.Ints(out)
+	return out, nil
+}
+
+func (i *Inspector) snapshotLeafItems(ctx context.Context, indexName string, pages []int) (map[int][]PageItem, error) {
+	cols, err := i.getIndexAttrTypes(ctx, indexName)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int][]PageItem, len(pages))
+	for _, blk := range pages {
+		items, err := i.GetPageItems(ctx, indexName, blk)
+		if err != nil {
+			return nil, err
+		}
+		for idx := range items {
+			items[idx].Keys = decodeIndexKey(cols, items[idx].Data, items[idx].Nulls)
+		}
+		out[blk] = items
+	}
+	return out, nil
+}
+
+// removedItems counts heap TIDs referenced before but gone after
+func removedItems(before, after map[int][]PageItem) int {
+	present := make(map[string]bool)
+	for _, items := range after {
+		for _, it := range items {
+			for _, t := range itemTids(it) {
+				present[t] = true
+			}
+		}
+	}
+	n := 0
+	for _, items := range before {
+		for _, it := range items {
+			for _, t := range itemTids(it) {
+				if !present[t] {
+					n++
+				}
+			}
+		}
+	}
+	return n
+}
+
+// itemTids lists the heap TIDs of an item, expanding posting lists
+func itemTids(it PageItem) []string {
+	if it.Tids == "" {
+		if it.Htid == "" {
+			return nil
+		}
+		return []string{it.Htid}
+	}
+	list := strings.Trim(it.Tids, "{}")
+	var out []string
+	for _, t := range strings.Split(strings.ReplaceAll(list, `"`, ""), "),") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if !strings.HasSuffix(t, ")") {
+			t += ")"
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+func itemChange(blk int, it PageItem, wasDead bool) IndexItemChange {
+	return IndexItemChange{
+		BlockNo:    blk,
+		ItemOffset: it.ItemOffset,
+		Htid:       it.Htid,
+		Key:        strings.Join(it.Keys, ", "),
+		WasDead:    wasDead,
+	}
+}
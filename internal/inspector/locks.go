@@ -0,0 +1,262 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	heapKeysUpdated = 0x2000 // t_infomask2
+
+	lockDemoDefaultHold = 30 * time.Second
+	lockDemoMaxHold     = 2 * time.Minute
+)
+
+// lockDemoSeq numbers the lock demo sessions
+var lockDemoSeq atomic.Int64
+
+// xmaxLockState turns the xmax lock bits into the row lock (or update) they
+// stand for
+func xmaxLockState(xmax int64, mask, mask2 int) string {
+	if xmax == 0 || mask&heapXmaxInvalid != 0 {
+		return ""
+	}
+	if mask&heapXmaxIsMulti != 0 {
+		if xmaxLockedOnly(mask) {
+			return "MultiXact: shared by several lockers"
+		}
+		return "MultiXact: lockers plus an updater"
+	}
+	if !xmaxLockedOnly(mask) {
+		if mask2&heapKeysUpdated != 0 {
+			return "deleted, or updated with key change"
+		}
+		return "updated without key change"
+	}
+	switch {
+	case mask&heapLockMask == heapLockMask:
+		return "FOR SHARE"
+	case mask&heapXmaxKeyshrLock != 0:
+		return "FOR KEY SHARE"
+	case mask&heapXmaxExclLock != 0 && mask2&heapKeysUpdated != 0:
+		return "FOR UPDATE"
+	case mask&heapXmaxExclLock != 0:
+		return "FOR NO KEY UPDATE"
+	}
+	return "locked"
+}
+
+// GetRowLocks lists the currently locked rows of a table using pgrowlocks,
+// which is optional.
+func (i *Inspector) GetRowLocks(ctx context.Context, table string) (*RowLocks, error) {
+	out := &RowLocks{Table: table, Locks: []RowLock{}}
+	if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("extension-exists").Query(), "pgrowlocks").Scan(&out.Available); err != nil {
+		return nil, fmt.Errorf("check extension pgrowlocks: %w", err)
+	}
+	if !out.Available {
+		out.Hint = "run: CREATE EXTENSION pgrowlocks"
+		return out, nil
+	}
+
+	rows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("row-locks").Query(), table)
+	if err != nil {
+		return nil, fmt.Errorf("pgrowlocks %s: %w", table, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var l RowLock
+		if err := rows.Scan(&l.Tid, &l.Locker, &l.Multi, &l.Xids, &l.Modes, &l.Pids); err != nil {
+			return nil, fmt.Errorf("scan row lock: %w", err)
+		}
+		fmt.Sscanf(l.Tid, "(%d,%d)", &l.Page, &l.Item)
+		out.Locks = append(out.Locks, l)
+	}
+	return out, rows.Err()
+}
+
+// ExecuteLockDemo locks a row FOR UPDATE in a session whose transaction
+// stays open for hold, so the lock can be inspected from the page and row
+// lock views. The session counts against the session limit and is closed on
+// shutdown like any other.
+func (i *Inspector) ExecuteLockDemo(ctx context.Context, table, pk string, hold time.Duration) (*LockDemoResult, error) {
+	fail := func(msg string) *LockDemoResult {
+		return &LockDemoResult{Success: false, Error: msg, Table: table, PK: pk}
+	}
+	if hold <= 0 {
+		hold = lockDemoDefaultHold
+	}
+	hold = min(hold, lockDemoMaxHold)
+
+	pkCol, err := i.GetPrimaryKeyColumn(ctx, table)
+	if err != nil {
+		return fail("table has no primary key"), nil
+	}
+	loc, err := i.FindRowByPK(ctx, table, pk)
+	if err != nil || !loc.Found {
+		return fail(fmt.Sprintf("row pk=%s not found", pk)), nil
+	}
+	before, err := i.rowTupleState(ctx, table, loc)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+
+	name := fmt.Sprintf("lock-demo-%d", lockDemoSeq.Add(1))
+	if _, err := i.OpenSession(ctx, name, ""); err != nil {
+		return fail(err.Error()), nil
+	}
+	keep := false
+	defer func() {
+		if !keep {
+			i.CloseSession(name)
+		}
+	}()
+
+	res := &LockDemoResult{Success: true, Table: table, PK: pk, Session: name, Before: before.state}
+	lit := quoteLiteral(pk)
+	q := fmt.Sprintf("BEGIN; SELECT 1 FROM %s WHERE %s = %s FOR UPDATE NOWAIT", table, pkCol, lit)
+	r, err := i.ExecSession(ctx, name, q)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	if !r.Success {
+		return fail(fmt.Sprintf("lock row: %s", r.Error)), nil
+	}
+	res.Locker = r.Session.Xid
+
+	during, err := i.rowTupleState(ctx, table, loc)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	res.During = during.state
+	res.LockState = during.tuple.LockState
+	if locks, err := i.GetRowLocks(ctx, table); err == nil {
+		res.RowLocks = locks.Locks
+	}
+
+	// closing the session, by hand or when it expires, rolls back and
+	// releases the lock
+	if err := i.holdSession(name, hold); err != nil {
+		return fail(err.Error()), nil
+	}
+	keep = true
+	res.HeldFor = int(hold.Seconds())
+	res.ReleaseAt = time.Now().Add(hold)
+
+	res.Explanation = fmt.Sprintf(
+		"🔐 Transaction %d in session %s ran SELECT ... FOR UPDATE and is holding the lock for %ds, or until the session is closed. "+
+			"Row locks are not kept in shared memory: "+
+			"PostgreSQL writes the locker's xid into the tuple's xmax and sets HEAP_XMAX_LOCK_ONLY and HEAP_XMAX_EXCL_LOCK "+
+			"(plus HEAP_KEYS_UPDATED for FOR UPDATE, as opposed to FOR NO KEY UPDATE). The row stays visible because the xmax only locks it. "+
+			"Other writers find the xid in xmax, see it is still running and wait for it.",
+		res.Locker, name, res.HeldFor)
+	return res, nil
+}
+
+// ExecuteMultiXactDemo takes FOR KEY SHARE locks on one row from two open
+// transactions so the tuple's xmax becomes a MultiXact, captures the tuple
+// and rolls both back. Unlike ExecuteLockDemo nothing stays locked: During
+// is a point-in-time capture.
+func (i *Inspector) ExecuteMultiXactDemo(ctx context.Context, table, pk string) (*MultiXactDemoResult, error) {
+	fail := func(msg string) *MultiXactDemoResult {
+		return &MultiXactDemoResult{Success: false, Error: msg, Table: table, PK: pk}
+	}
+
+	pkCol, err := i.GetPrimaryKeyColumn(ctx, table)
+	if err != nil {
+		return fail("table has no primary key"), nil
+	}
+	loc, err := i.FindRowByPK(ctx, table, pk)
+	if err != nil || !loc.Found {
+		return fail(fmt.Sprintf("row pk=%s not found", pk)), nil
+	}
+
+	before, err := i.rowTupleState(ctx, table, loc)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	res := &MultiXactDemoResult{Success: true, Table: table, PK: pk, Before: before.state}
+
+	var txs []pgx.Tx
+	defer func() {
+		for _, tx := range txs {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	lockQ := fmt.Sprintf("SELECT 1 FROM %s WHERE %s = $1 FOR KEY SHARE", table, pkCol)
+	for range 2 {
+		tx, err := i.pool.Begin(ctx)
+		if err != nil {
+			return fail(fmt.Sprintf("begin: %v", err)), nil
+		}
+		txs = append(txs, tx)
+		if _, err := tx.Exec(ctx, lockQ, pk); err != nil {
+			return fail(fmt.Sprintf("lock row: %v", err)), nil
+		}
+		var xid int64
+		if err := tx.QueryRow(ctx, i.qs.MustHaveQuery("current-xact-id").Query()).Scan(&xid); err != nil {
+			return fail(fmt.Sprintf("xact id: %v", err)), nil
+		}
+		res.Lockers = append(res.Lockers, xid)
+	}
+
+	during, err := i.rowTupleState(ctx, table, loc)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	res.During = during.state
+	res.Members = during.tuple.XmaxMembers
+
+	for _, tx := range txs {
+		tx.Rollback(ctx)
+	}
+	txs = nil
+
+	after, err := i.rowTupleState(ctx, table, loc)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	res.After = after.state
+
+	res.Explanation = multiXactExplanation(res)
+	return res, nil
+}
+
+type rowTuple struct {
+	state DemoTupleState
+	tuple *HeapTuple
+}
+
+func (i *Inspector) rowTupleState(ctx context.Context, table string, loc *RowLocation) (*rowTuple, error) {
+	page, err := i.GetHeapPageDetail(ctx, table, loc.Page)
+	if err != nil {
+		return nil, fmt.Errorf("page detail: %w", err)
+	}
+	t := findTuple(page.Tuples, loc.Item)
+	if t == nil {
+		return nil, fmt.Errorf("tuple not on page")
+	}
+	return &rowTuple{state: tupleState(loc, t), tuple: t}, nil
+}
+
+func multiXactExplanation(res *MultiXactDemoResult) string {
+	if !hasFlag(res.During.InfoMask, "HEAP_XMAX_IS_MULTI") {
+		return fmt.Sprintf("🔒 Both transactions locked the row, but xmax is %d rather than a MultiXact at the moment it was captured. Both were rolled back right after.", res.During.Xmax)
+	}
+	modes := make([]string, len(res.Members))
+	for j, m := range res.Members {
+		modes[j] = fmt.Sprintf("%d %s", m.Xid, m.Lock)
+	}
+	return fmt.Sprintf(
+		"🔒 xmax has room for a single transaction id. When transactions %d and %d both locked the row FOR KEY SHARE, "+
+			"PostgreSQL created MultiXact %d to hold both lockers and stored it in xmax with HEAP_XMAX_IS_MULTI and HEAP_XMAX_LOCK_ONLY set. "+
+			"Members: %s. A lock-only xmax does not delete the row, so the tuple stays visible. "+
+			"The tuple was captured while both transactions were open; they were rolled back right after, so nothing is locked now. "+
+			"The MultiXact stays in xmax after they ended; readers check that its members are gone and ignore it.",
+		res.Lockers[0], res.Lockers[1], res.During.Xmax, strings.Join(modes, ", "))
+}
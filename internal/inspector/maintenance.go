@@ -0,0 +1,238 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ExecuteMaintenanceDemo runs VACUUM, VACUUM (FREEZE), VACUUM FULL or
+// CLUSTER on a table and diffs its storage before and after.
+func (i *Inspector) ExecuteMaintenanceDemo(ctx context.Context, table, op, index string) (*MaintenanceDemoResult, error) {
+	fail := func(msg string) *MaintenanceDemoResult {
+		return &MaintenanceDemoResult{Success: false, Error: msg, Table: table, Operation: op}
+	}
+
+	// the names end up in the command text, so both must be known relations
+	tables, err := i.ListTables(ctx)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	if !slices.ContainsFunc(tables, func(t TableInfo) bool { return t.Name == table }) {
+		return fail(fmt.Sprintf("table %s not found", table)), nil
+	}
+	indexes, err := i.GetTableIndexes(ctx, table)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+
+	var cmd string
+	switch op {
+	case "vacuum":
+		cmd = fmt.Sprintf("VACUUM %s", table)
+	case "freeze":
+		cmd = fmt.Sprintf("VACUUM (FREEZE) %s", table)
+	case "full":
+		cmd = fmt.Sprintf("VACUUM FULL %s", table)
+	case "cluster":
+		if index == "" {
+			index = clusterIndex(indexes)
+		}
+		if index == "" {
+			return fail("CLUSTER needs a btree index on the table"), nil
+		}
+		if !slices.ContainsFunc(indexes, func(idx IndexInfo) bool { return idx.Name == index && idx.IndexType == "btree" }) {
+			return fail(fmt.Sprintf("%s is not a btree index on %s", index, table)), nil
+		}
+		cmd = fmt.Sprintf("CLUSTER %s USING %s", table, index)
+	default:
+		return fail("operation must be vacuum, freeze, full or cluster"), nil
+	}
+
+	before, err := i.storageSnapshot(ctx, table, indexes)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	start := time.Now()
+	if _, err := i.pool.Exec(ctx, cmd); err != nil {
+		return fail(fmt.Sprintf("%s: %v", cmd, err)), nil
+	}
+	took := time.Since(start)
+	after, err := i.storageSnapshot(ctx, table, indexes)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+
+	res := &MaintenanceDemoResult{
+		Success:    true,
+		Table:      table,
+		Operation:  op,
+		Command:    cmd,
+		Index:      index,
+		DurationMs: took.Milliseconds(),
+		Before:     *before,
+		After:      *after,
+		Diff:       diffStorage(before, after),
+	}
+	res.Explanation = maintenanceExplanation(res)
+	return res, nil
+}
+
+// clusterIndex picks the primary key, or else the first btree index
+func clusterIndex(indexes []IndexInfo) string {
+	name := ""
+	for _, idx := range indexes {
+		if idx.IndexType != "btree" {
+			continue
+		}
+		if idx.IsPrimary {
+			return idx.Name
+		}
+		if name == "" {
+			name = idx.Name
+		}
+	}
+	return name
+}
+
+func (i *Inspector) storageSnapshot(ctx context.Context, table string, indexes []IndexInfo) (*StorageSnapshot, error) {
+	s := &StorageSnapshot{}
+	err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("table-storage-info").Query(), table).Scan(
+		&s.RelFilenode, &s.RelPages, &s.RelAllVisible, &s.RelFrozenXID, &s.FrozenXIDAge,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("storage info %s: %w", table, err)
+	}
+
+	pageMap, err := i.GetHeapPageMap(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	s.Pages = len(pageMap.Pages)
+	for _, p := range pageMap.Pages {
+		if p.LiveTuples < 0 {
+			continue
+		}
+		sp := StoragePage{BlockNo: p.BlockNo, LiveTuples: p.LiveTuples, DeadTuples: p.DeadTuples, FreeSpace: p.FreeSpace, AllFrozen: p.AllFrozen}
+		if hdr, err := i.heapPageHeader(ctx, table, p.BlockNo); err == nil {
+			sp.AllVisible = hdr.flags&pdAllVisible != 0
+		}
+		s.LiveTuples += sp.LiveTuples
+		s.DeadTuples += sp.DeadTuples
+		s.FreeSpace += sp.FreeSpace
+		if sp.AllVisible {
+			s.AllVisiblePages++
+		}
+		if sp.AllFrozen {
+			s.AllFrozenPages++
+		}
+		s.PageMap = append(s.PageMap, sp)
+	}
+
+	for _, idx := range indexes {
+		is := IndexSnapshot{Name: idx.Name}
+		if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("relation-filenode").Query(), idx.Name).Scan(&is.RelFilenode, &is.Size); err != nil {
+			return nil, fmt.Errorf("index storage %s: %w", idx.Name, err)
+		}
+		if idx.IndexType == "btree" {
+			if st, err := i.GetIndexStats(ctx, idx.Name); err == nil {
+				is.Stats = st
+			}
+		}
+		s.Indexes = append(s.Indexes, is)
+	}
+	return s, nil
+}
+
+func diffStorage(before, after *StorageSnapshot) StorageDiff {
+	d := StorageDiff{
+		Rewritten:         before.RelFilenode != after.RelFilenode,
+		PagesDelta:        after.Pages - before.Pages,
+		LiveTuplesDelta:   after.LiveTuples - before.LiveTuples,
+		DeadTuplesDelta:   after.DeadTuples - before.DeadTuples,
+		FreeSpaceDelta:    after.FreeSpace - before.FreeSpace,
+		AllVisibleDelta:   after.AllVisiblePages - before.AllVisiblePages,
+		AllFrozenDelta:    after.AllFrozenPages - before.AllFrozenPages,
+		FrozenXIDAgeDelta: after.FrozenXIDAge - before.FrozenXIDAge,
+		Pages:             []StoragePageDiff{},
+	}
+
+	byBlock := make(map[int]StoragePage, len(after.PageMap))
+	for _, p := range after.PageMap {
+		byBlock[p.BlockNo] = p
+	}
+	seen := map[int]bool{}
+	for _, b := range before.PageMap {
+		seen[b.BlockNo] = true
+		if a, ok := byBlock[b.BlockNo]; !ok {
+			d.Pages = append(d.Pages, StoragePageDiff{BlockNo: b.BlockNo, Before: &b, Change: "removed"})
+		} else if a != b {
+			d.Pages = append(d.Pages, StoragePageDiff{BlockNo: b.BlockNo, Before: &b, After: &a, Change: "changed"})
+		}
+	}
+	for _, a := range after.PageMap {
+		if !seen[a.BlockNo] {
+			d.Pages = append(d.Pages, StoragePageDiff{BlockNo: a.BlockNo, After: &a, Change: "added"})
+		}
+	}
+
+	afterIdx := make(map[string]IndexSnapshot, len(after.Indexes))
+	for _, idx := range after.Indexes {
+		afterIdx[idx.Name] = idx
+	}
+	for _, b := range before.Indexes {
+		a := afterIdx[b.Name]
+		id := IndexDiff{
+			Name:              b.Name,
+			RelFilenodeBefore: b.RelFilenode,
+			RelFilenodeAfter:  a.RelFilenode,
+			Rebuilt:           b.RelFilenode != a.RelFilenode,
+			SizeBefore:        b.Size,
+			SizeAfter:         a.Size,
+		}
+		if b.Stats != nil && a.Stats != nil {
+			id.LeafDensityBefore, id.LeafDensityAfter = b.Stats.AvgLeafDensity, a.Stats.AvgLeafDensity
+			id.DeletedPagesBefore, id.DeletedPagesAfter = b.Stats.DeletedPages, a.Stats.DeletedPages
+		}
+		d.Indexes = append(d.Indexes, id)
+	}
+	return d
+}
+
+func maintenanceExplanation(res *MaintenanceDemoResult) string {
+	b, a, d := res.Before, res.After, res.Diff
+	switch res.Operation {
+	case "vacuum":
+		return fmt.Sprintf(
+			"🧹 VACUUM: cleaned the table in place (relfilenode %d unchanged). Dead tuples %d→%d, free space %+d bytes, table size %d→%d pages. "+
+				"Freed space stays in the table for new rows; only empty pages at the end get truncated. %d→%d pages are now marked all-visible, so index-only scans can skip them.",
+			a.RelFilenode, b.DeadTuples, a.DeadTuples, d.FreeSpaceDelta, b.Pages, a.Pages, b.AllVisiblePages, a.AllVisiblePages)
+	case "freeze":
+		return fmt.Sprintf(
+			"🧊 VACUUM (FREEZE): vacuumed with freeze_min_age 0, so every visible tuple got HEAP_XMIN_FROZEN. All-frozen pages %d→%d, age(relfrozenxid) %d→%d. "+
+				"Frozen pages never need to be visited again for wraparound, until they are modified.",
+			b.AllFrozenPages, a.AllFrozenPages, b.FrozenXIDAge, a.FrozenXIDAge)
+	case "full":
+		return fmt.Sprintf(
+			"🏗️ VACUUM FULL: copied the live tuples into a new file (relfilenode %d→%d) and rebuilt %d indexes, holding an ACCESS EXCLUSIVE lock the whole time. "+
+				"Size %d→%d pages, dead tuples %d→%d. The old file is removed, so the space goes back to the operating system.",
+			b.RelFilenode, a.RelFilenode, rebuiltIndexes(d), b.Pages, a.Pages, b.DeadTuples, a.DeadTuples)
+	case "cluster":
+		return fmt.Sprintf(
+			"🗂️ CLUSTER USING %s: rewrote the table in %s order into relfilenode %d→%d and rebuilt %d indexes. Size %d→%d pages. "+
+				"Rows with neighbouring keys now sit on the same pages, but the order is not maintained as new rows arrive.",
+			res.Index, res.Index, b.RelFilenode, a.RelFilenode, rebuiltIndexes(d), b.Pages, a.Pages)
+	}
+	return ""
+}
+
+func rebuiltIndexes(d StorageDiff) int {
+	n := 0
+	for _, idx := range d.Indexes {
+		if idx.Rebuilt {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,267 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+const (
+	pruneDemoMaxUpdates = 300
+
+	pdPageFull = 0x0002
+)
+
+// ExecuteDemoPrune fills a row's page with dead HOT versions of it, then
+// runs a plain SELECT over the page and shows what heap_page_prune_opt did
+// to it on the way.
+func (i *Inspector) ExecuteDemoPrune(ctx context.Context, table, pk, column string) (*DemoPruneResult, error) {
+	fail := func(msg string) *DemoPruneResult {
+		return &DemoPruneResult{Success: false, Error: msg, Table: table, PK: pk, Column: column}
+	}
+
+	info, err := i.GetIndexedColumns(ctx, table)
+	if err != nil {
+		return fail(fmt.Sprintf("table info: %v", err)), nil
+	}
+	if info.PKColumn == "" {
+		return fail("table has no primary key"), nil
+	}
+	cats, err := i.columnCategories(ctx, table)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	if column == "" {
+		for _, col := range info.NonIndexed {
+			if _, ok := updateExpr(col, cats[col]); ok {
+				column = col
+				break
+			}
+		}
+		if column == "" {
+			return fail("no non-indexed column with a type the demo can change"), nil
+		}
+	}
+	if _, ok := cats[column]; !ok {
+		return fail(fmt.Sprintf("column %s not found", column)), nil
+	}
+	if slices.Contains(info.IndexedColumns, column) {
+		return fail(fmt.Sprintf("column %s is indexed, so updating it is never HOT", column)), nil
+	}
+	expr, ok := updateExpr(column, cats[column])
+	if !ok {
+		return fail(fmt.Sprintf("column %s has a type the demo can't change", column)), nil
+	}
+
+	loc, err := i.FindRowByPK(ctx, table, pk)
+	if err != nil || !loc.Found {
+		return fail(fmt.Sprintf("row pk=%s not found", pk)), nil
+	}
+	page, err := i.GetHeapPageDetail(ctx, table, loc.Page)
+	if err != nil {
+		return fail(fmt.Sprintf("page detail: %v", err)), nil
+	}
+	t := findTuple(page.Tuples, loc.Item)
+	if t == nil {
+		return fail("tuple not on page"), nil
+	}
+	hdr, err := i.heapPageHeader(ctx, table, loc.Page)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	ff, err := i.tableFillFactor(ctx, table)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+
+	res := &DemoPruneResult{
+		Success:    true,
+		Table:      table,
+		PK:         pk,
+		Column:     column,
+		BlockNo:    loc.Page,
+		TupleSize:  maxAlign(t.ItemLen),
+		FillFactor: ff,
+		Threshold:  pruneOnReadThreshold(ff),
+		Predicted:  []LinePointerChange{},
+		Changes:    []LinePointerChange{},
+	}
+	// as many versions as still fit, so the last update stays on the page
+	res.Updates = min(heapFreeSpace(hdr.free, page.Tuples)/(res.TupleSize+lpSize), maxHeapTuplesPerPage-len(page.Tuples), pruneDemoMaxUpdates)
+	if res.Updates < 2 {
+		return fail(fmt.Sprintf("page %d has no room for more versions of this row; VACUUM the table and try again", loc.Page)), nil
+	}
+
+	// the last update writes the original value back, so the row keeps its
+	// data once the demo is done
+	orig, err := i.saveColumn(ctx, table, info.PKColumn, pk, column)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+
+	// in one transaction the versions can't be pruned while they pile up:
+	// their xmax is still running
+	tx, err := i.pool.Begin(ctx)
+	if err != nil {
+		return fail(fmt.Sprintf("begin: %v", err)), nil
+	}
+	defer tx.Rollback(context.Background())
+	if err := tx.QueryRow(ctx, i.qs.MustHaveQuery("current-xact-id").Query()).Scan(&res.Xid); err != nil {
+		return fail(fmt.Sprintf("xid: %v", err)), nil
+	}
+	updateQ := fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s = $1", table, column, expr, info.PKColumn)
+	for range res.Updates - 1 {
+		if _, err := tx.Exec(ctx, updateQ, pk); err != nil {
+			return fail(fmt.Sprintf("update: %v", err)), nil
+		}
+	}
+	if _, err := tx.Exec(ctx, orig.restoreQuery(table, info.PKColumn), pk, orig.value); err != nil {
+		return fail(fmt.Sprintf("restore %s: %v", column, err)), nil
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fail(fmt.Sprintf("commit: %v", err)), nil
+	}
+
+	// only pageinspect may look at the page until the SELECT: any regular
+	// read of it could prune it first
+	var before []HeapTuple
+	if res.Before, before, err = i.pruneState(ctx, table, loc.Page); err != nil {
+		return fail(err.Error()), nil
+	}
+	c, err := i.newXactCache(ctx)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	h, err := i.cleanupHorizon(ctx, c)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	res.Horizon, res.HeldBy = h.Horizon, h.HeldBy
+	p, err := i.planPrune(ctx, c, table, loc.Page, h.Horizon)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	res.Predicted = p.pred.Changes
+
+	// heap_page_prune_opt
+	res.FreeSpace = heapFreeSpace(res.Before.Free, before)
+	prunable := res.Before.PruneXid != 0 && c.ref.fullXid(res.Before.PruneXid) < h.Horizon
+	switch {
+	case prunable && res.Before.PageFull:
+		res.Trigger = "page_full"
+	case prunable && res.FreeSpace < res.Threshold:
+		res.Trigger = "low_free"
+	default:
+		res.Trigger = "none"
+	}
+
+	res.Query = fmt.Sprintf("SELECT count(*) FROM %s WHERE ctid >= '(%d,0)' AND ctid < '(%d,0)'", table, loc.Page, loc.Page+1)
+	if _, err := i.pool.Exec(ctx, res.Query); err != nil {
+		return fail(fmt.Sprintf("select: %v", err)), nil
+	}
+
+	var after []HeapTuple
+	if res.After, after, err = i.pruneState(ctx, table, loc.Page); err != nil {
+		return fail(err.Error()), nil
+	}
+	for _, b := range before {
+		a := findTuple(after, b.LP)
+		if a == nil || a.LPFlags == b.LPFlags && (a.LPFlags != lpRedirect || a.LPOffset == b.LPOffset) {
+			continue
+		}
+		ch := LinePointerChange{LP: b.LP, From: b.LPFlagsStr, To: a.LPFlagsStr}
+		if a.LPFlags == lpRedirect {
+			ch.RedirectTo = a.LPOffset
+		}
+		for _, pc := range res.Predicted {
+			if pc.LP == b.LP {
+				ch.Reason = pc.Reason
+			}
+		}
+		res.Changes = append(res.Changes, ch)
+	}
+	res.Pruned = len(res.Changes) > 0 || res.After.PruneXid != res.Before.PruneXid
+	res.Explanation = pruneExplanation(res)
+	return res, nil
+}
+
+func (i *Inspector) pruneState(ctx context.Context, table string, blk int) (PagePruneState, []HeapTuple, error) {
+	var s PagePruneState
+	page, err := i.GetHeapPageDetail(ctx, table, blk)
+	if err != nil {
+		return s, nil, fmt.Errorf("page detail: %w", err)
+	}
+	hdr, err := i.heapPageHeader(ctx, table, blk)
+	if err != nil {
+		return s, nil, err
+	}
+	chains, err := i.GetHotChains(ctx, table, blk, nil)
+	if err != nil {
+		return s, nil, err
+	}
+	s.Free, s.PruneXid, s.PageFull, s.Chains = hdr.free, hdr.pruneXid, hdr.flags&pdPageFull != 0, chains.Chains
+	for _, t := range page.Tuples {
+		switch t.LPFlags {
+		case lpNormal:
+			s.Normal++
+		case lpRedirect:
+			s.Redirect++
+		case lpDead:
+			s.Dead++
+		default:
+			s.Unused++
+		}
+	}
+	return s, page.Tuples, nil
+}
+
+func pruneExplanation(res *DemoPruneResult) string {
+	b, a := res.Before, res.After
+	out := fmt.Sprintf(
+		"🧹 %d HOT updates of %s in one transaction (xid %d), the last one putting the original value back, left %d dead versions of the row on page %d. "+
+			"Before the read the page had %d normal, %d redirect and %d dead line pointers, %d bytes free and pd_prune_xid %d.",
+		res.Updates, res.Column, res.Xid, res.Updates, res.BlockNo, b.Normal, b.Redirect, b.Dead, b.Free, b.PruneXid)
+
+	out += " Every heap page read runs heap_page_prune_opt, which prunes when pd_prune_xid is older than the cleanup horizon and the page is full or nearly so."
+	switch {
+	case b.PruneXid == 0:
+		out += " pd_prune_xid was 0, so nothing on the page was known to be prunable."
+	case res.Trigger == "none" && b.PruneXid != 0 && res.FreeSpace >= res.Threshold:
+		out += fmt.Sprintf(" The page still had %d bytes free for a new tuple, at least the %d-byte threshold (fillfactor %d reserve, or a tenth of the page), so the read left it alone.",
+			res.FreeSpace, res.Threshold, res.FillFactor)
+	case res.Trigger == "none":
+		out += fmt.Sprintf(" pd_prune_xid %d isn't older than the cleanup horizon %d", b.PruneXid, res.Horizon)
+		if res.HeldBy != nil {
+			out += fmt.Sprintf(", held back by %s %s", res.HeldBy.Kind, res.HeldBy.Name)
+		}
+		out += ", so some snapshot might still need the dead versions."
+	case res.Trigger == "page_full":
+		out += " PD_PAGE_FULL was set (an update found no room here), which is enough on its own."
+	default:
+		out += fmt.Sprintf(" Only %d bytes were free for a new tuple, below the %d-byte threshold (the fillfactor %d reserve, but at least a tenth of the page).",
+			res.FreeSpace, res.Threshold, res.FillFactor)
+	}
+
+	if !res.Pruned {
+		if res.Trigger != "none" {
+			out += " Yet the page wasn't pruned: pruning needs a cleanup lock and gives up at once if another backend holds a pin on the buffer."
+		}
+		return out
+	}
+	var redirects, dead, freed int
+	for _, ch := range res.Changes {
+		switch ch.To {
+		case lpFlagsStr(lpRedirect):
+			redirects++
+		case lpFlagsStr(lpDead):
+			dead++
+		case lpFlagsStr(lpUnused):
+			freed++
+		}
+	}
+	out += fmt.Sprintf(" The SELECT pruned the page: %d line pointer(s) became LP_REDIRECT, %d LP_DEAD and %d LP_UNUSED, and free space went %d → %d bytes. "+
+		"pd_prune_xid went %d → %d (0 means nothing left that a later prune could remove).",
+		redirects, dead, freed, b.Free, a.Free, b.PruneXid, a.PruneXid)
+	out += " Chain roots stay behind as redirects because index entries still point at them; the heap-only versions had no index entries and are gone for good, without any VACUUM."
+	return out
+}
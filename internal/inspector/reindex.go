@@ -0,0 +1,358 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// nbtree build constants (nbtree.h, nbtsort.c)
+const (
+	btSpecialSize       = 16
+	btTupleHeader       = 8
+	btTupleHeaderNulls  = 16
+	btHeapTIDSize       = 6
+	btDefaultFillfactor = 90
+	btNonLeafFillfactor = 70
+
+	// blocks read per REINDEX simulation; bigger indexes are sampled evenly
+	// and the result scaled up
+	reindexSamplePages = 2000
+
+	// MAXALIGN_DOWN(BLCKSZ * 10 / 100) - sizeof(ItemIdData)
+	btMaxPostingSize = (pageSize*10/100)&^7 - lpSize
+)
+
+type btreeTuple struct {
+	size  int // on-page size, MAXALIGNed
+	pivot int // size of the truncated pivot separating on this key
+}
+
+type btreeShape struct {
+	LeafPages     int64
+	InternalPages int64
+	Levels        int
+	LeafTuples    int64
+}
+
+// total pages including the metapage
+func (s btreeShape) totalPages() int64 {
+	return 1 + s.LeafPages + s.InternalPages
+}
+
+func maxAlign(n int) int {
+	return (n + 7) &^ 7
+}
+
+// packBTree replays a sorted index build: leaf tuples are packed left to
+// right, each finished page adds a downlink to its parent and levels are
+// built until a single root page remains.
+func packBTree(leaf func() (btreeTuple, bool), fillfactor int) btreeShape {
+	return packSampledBTree(leaf, fillfactor, 1)
+}
+
+// packSampledBTree packs leaf tuples that sample a bigger index: the leaf
+// level is scaled up by scale and the sample's downlinks are repeated to
+// stand in for the missing pages when the upper levels are built.
+func packSampledBTree(leaf func() (btreeTuple, bool), fillfactor int, scale float64) btreeShape {
+	pages, tuples, downlinks := packLevel(leaf, fillfactor, false)
+	if scale > 1 && pages > 0 {
+		pages = int64(math.Round(float64(pages) * scale))
+		tuples = int64(math.Round(float64(tuples) * scale))
+		sample := downlinks
+		downlinks = make([]int, pages)
+		for j := range downlinks {
+			downlinks[j] = sample[j%len(sample)]
+		}
+	}
+	shape := btreeShape{LeafPages: pages, LeafTuples: tuples}
+	if pages > 0 {
+		shape.Levels = 1
+	}
+
+	for len(downlinks) > 1 {
+		links := downlinks
+		idx := 0
+		next := func() (btreeTuple, bool) {
+			if idx >= len(links) {
+				return btreeTuple{}, false
+			}
+			sz := links[idx]
+			idx++
+			return btreeTuple{size: sz, pivot: sz}, true
+		}
+		pages, _, downlinks = packLevel(next, btNonLeafFillfactor, true)
+		shape.InternalPages += pages
+		shape.Levels++
+	}
+	return shape
+}
+
+// packLevel mirrors _bt_buildadd: a page is finished once the next tuple plus
+// room for the high key no longer fits, or once free space has dropped below
+// the fillfactor reserve. Returns pages, tuples and downlink sizes for the
+// parent level (the leftmost downlink is the "minus infinity" item).
+func packLevel(next func() (btreeTuple, bool), fillfactor int, internal bool) (int64, int64, []int) {
+	usable := pageSize - pageHeader - btSpecialSize
+	reserve := pageSize * (100 - fillfactor) / 100
+
+	var pages, tuples int64
+	var downlinks []int
+	free, items := 0, 0
+
+	for {
+		t, ok := next()
+		if !ok {
+			break
+		}
+		tuples++
+
+		need := t.size + lpSize
+		full := items > 0 && (free < need+t.pivot+lpSize || (items > 1 && free < reserve))
+		if pages == 0 || full {
+			pages++
+			if pages == 1 {
+				downlinks = append(downlinks, btTupleHeader)
+			} else {
+				downlinks = append(downlinks, t.pivot)
+			}
+			free, items = usable, 0
+		}
+
+		// first item on an internal page is truncated to minus infinity
+		if internal && items == 0 {
+			need = btTupleHeader + lpSize
+		}
+		free -= need
+		items++
+	}
+	return pages, tuples, downlinks
+}
+
+// postingCapacity returns how many heap TIDs fit into one posting list tuple
+// with the given key size, or 1 when deduplication cannot help.
+func postingCapacity(base int) int {
+	n := (btMaxPostingSize - base) / btHeapTIDSize
+	for n > 1 && maxAlign(base+n*btHeapTIDSize) > btMaxPostingSize {
+		n--
+	}
+	if n < 2 {
+		return 1
+	}
+	return n
+}
+
+// postingTuples splits ntids TIDs sharing one key into the tuples a sorted
+// build forms: posting lists up to btMaxPostingSize, a lone TID stays plain.
+func postingTuples(base int, ntids int64, dedup bool, emit func(size int)) {
+	capacity := int64(1)
+	if dedup {
+		capacity = int64(postingCapacity(base))
+	}
+	for ntids > 0 {
+		n := ntids
+		if n > capacity {
+			n = capacity
+		}
+		if n == 1 {
+			emit(base)
+		} else {
+			emit(maxAlign(base + int(n)*btHeapTIDSize))
+		}
+		ntids -= n
+	}
+}
+
+type leafKey struct {
+	data  string
+	base  int
+	ntids int64
+}
+
+func (i *Inspector) SimulateReindex(ctx context.Context, indexName string, currentSize int64) (*ReindexEstimate, error) {
+	var fillfactor int
+	var dedupItems, unique, include bool
+	err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("index-build-options").Query(), indexName).Scan(
+		&fillfactor, &dedupItems, &unique, &include,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("index options %s: %w", indexName, err)
+	}
+
+	meta, err := i.GetMeta(ctx, indexName)
+	if err != nil {
+		return nil, err
+	}
+
+	// same rule as _bt_load: unique and INCLUDE indexes are never deduplicated
+	dedup := dedupItems && meta.AllequalImage && !unique && !include
+
+	blocks := sampleBlocks(currentSize/pageSize, reindexSamplePages)
+	keys, err := i.leafKeys(ctx, indexName, blocks)
+	if err != nil {
+		return nil, err
+	}
+	scale := 1.0
+	if n := currentSize/pageSize - 1; n > int64(len(blocks)) {
+		scale = float64(n) / float64(len(blocks))
+	}
+
+	// merge equal keys into TID groups, then expand into build tuples
+	var groups []leafKey
+	var heapTids int64
+	for _, k := range keys {
+		heapTids += k.ntids
+		if n := len(groups); n > 0 && groups[n-1].data == k.data && groups[n-1].base == k.base {
+			groups[n-1].ntids += k.ntids
+			continue
+		}
+		groups = append(groups, k)
+	}
+
+	var pending []int
+	idx := 0
+	next := func() (btreeTuple, bool) {
+		for len(pending) == 0 {
+			if idx >= len(groups) {
+				return btreeTuple{}, false
+			}
+			g := groups[idx]
+			idx++
+			postingTuples(g.base, g.ntids, dedup, func(size int) {
+				pending = append(pending, size)
+			})
+		}
+		g := groups[idx-1]
+		size := pending[0]
+		pending = pending[1:]
+		return btreeTuple{size: size, pivot: g.base}, true
+	}
+
+	shape := packSampledBTree(next, fillfactor, scale)
+	total := shape.totalPages()
+	level := shape.Levels - 1
+	if level < 0 {
+		level = 0
+	}
+
+	return &ReindexEstimate{
+		Fillfactor:    fillfactor,
+		Deduplicate:   dedup,
+		HeapTids:      int64(math.Round(float64(heapTids) * scale)),
+		LeafTuples:    shape.LeafTuples,
+		LeafPages:     shape.LeafPages,
+		InternalPages: shape.InternalPages,
+		TreeLevel:     level,
+		TotalPages:    total,
+		Size:          total * pageSize,
+		PagesDiff:     currentSize/pageSize - total,
+		BytesDiff:     currentSize - total*pageSize,
+		SampledPages:  len(blocks),
+		Sampled:       scale > 1,
+	}, nil
+}
+
+// sampleBlocks spreads at most limit block numbers evenly over the blocks
+// after the metapage
+func sampleBlocks(numPages int64, limit int) []int {
+	n := int(numPages - 1)
+	if n <= 0 {
+		return nil
+	}
+	if n <= limit {
+		out := make([]int, n)
+		for j := range out {
+			out[j] = j + 1
+		}
+		return out
+	}
+	out := make([]int, limit)
+	for j := range out {
+		out[j] = 1 + j*n/limit
+	}
+	return out
+}
+
+// leafKeys returns the non-dead leaf tuples on blocks in key order by walking
+// the leaf level through its sibling links. A sample of blocks breaks the
+// chain, and keys come back in block order instead.
+func (i *Inspector) leafKeys(ctx context.Context, indexName string, blocks []int) ([]leafKey, error) {
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+
+	rows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("bt-leaf-items").Query(), indexName, blocks)
+	if err != nil {
+		return nil, fmt.Errorf("leaf items %s: %w", indexName, err)
+	}
+	defer rows.Close()
+
+	type leafPage struct {
+		prev, next int
+		keys       []leafKey
+	}
+	pages := make(map[int]*leafPage)
+	var order []int
+
+	for rows.Next() {
+		var blk, prev, next, itemLen, ntids int
+		var nulls, dead bool
+		var data string
+		if err := rows.Scan(&blk, &prev, &next, &itemLen, &nulls, &data, &dead, &ntids); err != nil {
+			return nil, fmt.Errorf("scan leaf item: %w", err)
+		}
+		p, ok := pages[blk]
+		if !ok {
+			p = &leafPage{prev: prev, next: next}
+			pages[blk] = p
+			order = append(order, blk)
+		}
+		if dead {
+			continue
+		}
+
+		k := leafKey{data: data, base: itemLen, ntids: 1}
+		if ntids > 0 {
+			hdr := btTupleHeader
+			if nulls {
+				hdr = btTupleHeaderNulls
+			}
+			k.base = maxAlign(hdr + len(strings.Fields(data)))
+			k.ntids = int64(ntids)
+		}
+		p.keys = append(p.keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// follow btpo_next from the leftmost leaf; fall back to block order
+	// if the chain does not cover every page (concurrent split)
+	chain := make([]int, 0, len(order))
+	seen := make(map[int]bool, len(order))
+	for _, blk := range order {
+		if pages[blk].prev != 0 {
+			continue
+		}
+		for b := blk; b != 0 && !seen[b]; {
+			p, ok := pages[b]
+			if !ok {
+				break
+			}
+			seen[b] = true
+			chain = append(chain, b)
+			b = p.next
+		}
+		break
+	}
+	if len(chain) != len(order) {
+		chain = order
+	}
+
+	var out []leafKey
+	for _, blk := range chain {
+		out = append(out, pages[blk].keys...)
+	}
+	return out, nil
+}
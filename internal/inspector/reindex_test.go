@@ -0,0 +1,52 @@
+package inspector
+
+import "testing"
+
+// int4 keys: 8-byte IndexTupleData plus the key, MAXALIGNed. With
+// fillfactor 90 a leaf page takes 367 of them and an internal page
+// (fillfactor 70) 286 downlinks.
+func int4Keys(n int) func() (btreeTuple, bool) {
+	return func() (btreeTuple, bool) {
+		if n == 0 {
+			return btreeTuple{}, false
+		}
+		n--
+		return btreeTuple{size: 16, pivot: 16}, true
+	}
+}
+
+func TestPackBTree(t *testing.T) {
+	tests := []struct {
+		name       string
+		tuples     int
+		fillfactor int
+		scale      float64
+		want       btreeShape
+	}{
+		{name: "empty", tuples: 0, fillfactor: 90, want: btreeShape{}},
+		{name: "single tuple", tuples: 1, fillfactor: 90, want: btreeShape{LeafPages: 1, Levels: 1, LeafTuples: 1}},
+		{name: "one full leaf", tuples: 367, fillfactor: 90, want: btreeShape{LeafPages: 1, Levels: 1, LeafTuples: 367}},
+		{name: "second leaf adds a root", tuples: 368, fillfactor: 90, want: btreeShape{LeafPages: 2, InternalPages: 1, Levels: 2, LeafTuples: 368}},
+		{name: "fillfactor 100", tuples: 406, fillfactor: 100, want: btreeShape{LeafPages: 1, Levels: 1, LeafTuples: 406}},
+		{name: "fillfactor 100 overflow", tuples: 407, fillfactor: 100, want: btreeShape{LeafPages: 2, InternalPages: 1, Levels: 2, LeafTuples: 407}},
+		{name: "full root", tuples: 367 * 286, fillfactor: 90, want: btreeShape{LeafPages: 286, InternalPages: 1, Levels: 2, LeafTuples: 367 * 286}},
+		{name: "root splits", tuples: 367*286 + 1, fillfactor: 90, want: btreeShape{LeafPages: 287, InternalPages: 3, Levels: 3, LeafTuples: 367*286 + 1}},
+		{name: "sample scaled up", tuples: 367 * 2, fillfactor: 90, scale: 200, want: btreeShape{LeafPages: 400, InternalPages: 3, Levels: 3, LeafTuples: 367 * 400}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got btreeShape
+			if tt.scale == 0 {
+				got = packBTree(int4Keys(tt.tuples), tt.fillfactor)
+			} else {
+				got = packSampledBTree(int4Keys(tt.tuples), tt.fillfactor, tt.scale)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if got.totalPages() != 1+tt.want.LeafPages+tt.want.InternalPages {
+				t.Errorf("totalPages = %d", got.totalPages())
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// executeRollbackUpdate runs the demo update in a transaction, looks at the
+// row from inside and outside it, rolls back and then follows what happens
+// to the aborted version.
+func (i *Inspector) executeRollbackUpdate(ctx context.Context, table, column, oldVal, newVal, pk string, info *IndexedColumnsInfo, loc *RowLocation, before DemoTupleState, reason *HotReason) (*DemoUpdateResult, error) {
+	fail := func(msg string) *DemoUpdateResult {
+		return &DemoUpdateResult{Success: false, Error: msg}
+	}
+
+	tx, err := i.pool.Begin(ctx)
+	if err != nil {
+		return fail(fmt.Sprintf("begin: %v", err)), nil
+	}
+	defer tx.Rollback(context.Background())
+
+	var ctid string
+	updateQ := fmt.Sprintf("UPDATE %s SET %s = $1 WHERE %s = $2 RETURNING ctid::text", table, column, info.PKColumn)
+	if err := tx.QueryRow(ctx, updateQ, newVal, pk).Scan(&ctid); err != nil {
+		return fail(fmt.Sprintf("update: %v", err)), nil
+	}
+	blk, item, _ := parseCtid(ctid)
+	newLoc := &RowLocation{Found: true, TID: ctid, Page: blk, Item: item}
+
+	res := &DemoUpdateResult{
+		Success:         true,
+		RolledBack:      true,
+		Column:          column,
+		IsColumnIndexed: slices.Contains(info.IndexedColumns, column),
+		OldValue:        oldVal,
+		NewValue:        newVal,
+		Before:          before,
+		SamePage:        loc.Page == newLoc.Page,
+	}
+	if err := tx.QueryRow(ctx, i.qs.MustHaveQuery("current-xact-id").Query()).Scan(&res.Xid); err != nil {
+		return fail(fmt.Sprintf("xact id: %v", err)), nil
+	}
+	var snapStr string
+	if err := tx.QueryRow(ctx, i.qs.MustHaveQuery("current-snapshot").Query()).Scan(&snapStr); err != nil {
+		return fail(fmt.Sprintf("snapshot: %v", err)), nil
+	}
+	inside, err := ParseSnapshot(snapStr)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	inside.Current = res.Xid
+
+	// the uncommitted versions are already on the shared page; only the
+	// snapshot decides who sees which
+	if res.Inside, err = i.transactionView(ctx, table, loc, newLoc, inside); err != nil {
+		return fail(err.Error()), nil
+	}
+	if res.Outside, err = i.transactionView(ctx, table, loc, newLoc, nil); err != nil {
+		return fail(err.Error()), nil
+	}
+	oldT, err := i.tupleAt(ctx, table, loc, nil)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	newT, err := i.tupleAt(ctx, table, newLoc, nil)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	res.After = tupleState(loc, oldT)
+	newState := tupleState(newLoc, newT)
+	res.NewTuple = &newState
+
+	if err := tx.Rollback(ctx); err != nil {
+		return fail(fmt.Sprintf("rollback: %v", err)), nil
+	}
+	if res.AfterRollback, err = i.rollbackStage(ctx, table, loc, newLoc); err != nil {
+		return fail(err.Error()), nil
+	}
+	if h, err := i.GetCleanupHorizon(ctx, table, newLoc.Page); err == nil {
+		for _, t := range h.Tuples {
+			if t.LP == newLoc.Item {
+				res.AbortedVacuumState = fmt.Sprintf("%s: %s", t.State, t.Reason)
+			}
+		}
+	}
+
+	// the next reader checks pg_xact once and records the outcome in hint
+	// bits, and may prune the page while it is there
+	readQ := fmt.Sprintf("SELECT 1 FROM %s WHERE %s = $1", table, info.PKColumn)
+	if _, err := i.pool.Exec(ctx, readQ, pk); err != nil {
+		return fail(fmt.Sprintf("read: %v", err)), nil
+	}
+	if res.AfterRead, err = i.rollbackStage(ctx, table, loc, newLoc); err != nil {
+		return fail(err.Error()), nil
+	}
+
+	isHot := res.SamePage && hasFlag(res.After.InfoMask, "HEAP_HOT_UPDATED")
+	res.UpdateType = "regular"
+	if isHot {
+		res.UpdateType = "hot"
+	}
+	reason.resolve(isHot, newT)
+	res.HotReason = reason
+	res.Explanation = "↩️ ROLLED BACK. " + buildExplanation(isHot, res.SamePage, loc, newLoc, reason)
+	res.Cleanup = rollbackCleanup(res)
+	return res, nil
+}
+
+func (i *Inspector) tupleAt(ctx context.Context, table string, loc *RowLocation, snap *Snapshot) (*HeapTuple, error) {
+	page, err := i.GetHeapPageAt(ctx, table, loc.Page, snap)
+	if err != nil {
+		return nil, fmt.Errorf("page detail: %w", err)
+	}
+	t := findTuple(page.Tuples, loc.Item)
+	if t == nil {
+		return nil, fmt.Errorf("tuple %s not on page", loc.TID)
+	}
+	return t, nil
+}
+
+// transactionView judges both versions against snap, or against a fresh
+// snapshot from outside the transaction when snap is nil
+func (i *Inspector) transactionView(ctx context.Context, table string, oldLoc, newLoc *RowLocation, snap *Snapshot) (*TransactionView, error) {
+	if snap == nil {
+		var err error
+		if snap, err = i.currentSnapshot(ctx); err != nil {
+			return nil, err
+		}
+	}
+	oldT, err := i.tupleAt(ctx, table, oldLoc, snap)
+	if err != nil {
+		return nil, err
+	}
+	newT, err := i.tupleAt(ctx, table, newLoc, snap)
+	if err != nil {
+		return nil, err
+	}
+	v := &TransactionView{Snapshot: snap, OldVisible: oldT.IsLive, NewVisible: newT.IsLive}
+	if oldT.Visibility != nil && len(oldT.Visibility.Steps) > 0 {
+		v.OldReason = oldT.Visibility.Steps[len(oldT.Visibility.Steps)-1]
+	}
+	if newT.Visibility != nil && len(newT.Visibility.Steps) > 0 {
+		v.NewReason = newT.Visibility.Steps[len(newT.Visibility.Steps)-1]
+	}
+	return v, nil
+}
+
+// rollbackStage captures both versions; a version that was pruned away has
+// no state
+func (i *Inspector) rollbackStage(ctx context.Context, table string, oldLoc, newLoc *RowLocation) (*RollbackStage, error) {
+	st := &RollbackStage{}
+	oldT, err := i.tupleAt(ctx, table, oldLoc, nil)
+	if err != nil {
+		return nil, err
+	}
+	if oldT.LPFlags == lpNormal {
+		s := tupleState(oldLoc, oldT)
+		st.Old = &s
+	}
+	st.OldLP = oldT.LPFlagsStr
+	newT, err := i.tupleAt(ctx, table, newLoc, nil)
+	if err != nil {
+		return nil, err
+	}
+	if newT.LPFlags == lpNormal {
+		s := tupleState(newLoc, newT)
+		st.New = &s
+	}
+	st.NewLP = newT.LPFlagsStr
+	return st, nil
+}
+
+func rollbackCleanup(res *DemoUpdateResult) string {
+	out := fmt.Sprintf(
+		"🧽 Transaction %d rolled back, but nothing on the page was undone: the new version at %s still has xmin %d and the old one still has xmax %d. "+
+			"Both are judged through pg_xact, which now says %d aborted, so the old version is visible again and the new one is dead.",
+		res.Xid, res.NewTuple.TID, res.Xid, res.Xid, res.Xid)
+	read := res.AfterRead
+	switch {
+	case read.New == nil:
+		out += fmt.Sprintf(" The next read pruned the page: the aborted version's line pointer is now %s.", read.NewLP)
+	case hasFlag(read.New.InfoMask, "HEAP_XMIN_INVALID"):
+		out += " The next read set HEAP_XMIN_INVALID on the aborted version, so later readers skip the pg_xact lookup."
+	}
+	if read.Old != nil && hasFlag(read.Old.InfoMask, "HEAP_XMAX_INVALID") {
+		out += " The old version got HEAP_XMAX_INVALID for the same reason."
+	}
+	if read.New != nil {
+		out += " An aborted insert is dead regardless of the cleanup horizon, so the next page prune or VACUUM reclaims its space."
+	}
+	return out
+}
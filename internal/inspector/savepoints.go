@@ -0,0 +1,348 @@
+package inspector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	savepointSession  = "savepoint-demo"
+	savepointChainMax = 16
+
+	heapComboCid = 0x0020 // t_infomask
+
+	// subxids a backend advertises in PGPROC before its snapshots overflow
+	// to pg_subtrans (PGPROC_MAX_CACHED_SUBXIDS)
+	maxCachedSubxids = 64
+)
+
+// RunSavepointScenario updates a row once at top level and once in each of
+// two savepoints inside a held session, rolls back to the second savepoint
+// and shows the row's versions after every step. finish is "hold" (leave
+// the transaction open in the session), "commit" or "rollback".
+func (i *Inspector) RunSavepointScenario(ctx context.Context, table, pk, column, finish string) (*SavepointScenarioResult, error) {
+	fail := func(msg string) *SavepointScenarioResult {
+		return &SavepointScenarioResult{Success: false, Error: msg, Table: table, PK: pk, Column: column, Finish: finish}
+	}
+	if finish == "" {
+		finish = "hold"
+	}
+	if !slices.Contains([]string{"hold", "commit", "rollback"}, finish) {
+		return fail("finish must be hold, commit or rollback"), nil
+	}
+
+	info, err := i.GetIndexedColumns(ctx, table)
+	if err != nil {
+		return fail(fmt.Sprintf("table info: %v", err)), nil
+	}
+	if info.PKColumn == "" {
+		return fail("table has no primary key"), nil
+	}
+	cats, err := i.columnCategories(ctx, table)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	if column == "" {
+		for _, col := range slices.Concat(info.NonIndexed, info.IndexedColumns) {
+			if _, ok := updateExpr(col, cats[col]); ok && col != info.PKColumn {
+				column = col
+				break
+			}
+		}
+		if column == "" {
+			return fail("no column with a type the scenario can change"), nil
+		}
+	}
+	if column == info.PKColumn {
+		return fail("the primary key can't be updated by the scenario"), nil
+	}
+	if _, ok := cats[column]; !ok {
+		return fail(fmt.Sprintf("column %s not found", column)), nil
+	}
+	expr, ok := updateExpr(column, cats[column])
+	if !ok {
+		return fail(fmt.Sprintf("column %s has a type the scenario can't change", column)), nil
+	}
+	loc, err := i.FindRowByPK(ctx, table, pk)
+	if err != nil || !loc.Found {
+		return fail(fmt.Sprintf("row pk=%s not found", pk)), nil
+	}
+	attrs, err := i.heapAttrs(ctx, table)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+
+	lit := quoteLiteral(pk)
+	update := fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s = %s", table, column, expr, info.PKColumn, lit)
+	probe := fmt.Sprintf("SELECT ctid, xmin, pg_current_xact_id() FROM %s WHERE %s = %s", table, info.PKColumn, lit)
+
+	// a committed scenario leaves the scratch values behind; this puts the
+	// original back afterwards
+	orig, err := i.saveColumn(ctx, table, info.PKColumn, pk, column)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+
+	if _, err := i.OpenSession(ctx, savepointSession, ""); err != nil {
+		if errors.Is(err, ErrSessionExists) {
+			return fail(fmt.Sprintf("session %s is still open from an earlier run; close it first", savepointSession)), nil
+		}
+		return fail(err.Error()), nil
+	}
+	keep := false
+	defer func() {
+		if !keep {
+			i.CloseSession(savepointSession)
+		}
+	}()
+
+	steps := []struct{ label, sql, savepoint string }{
+		{"top-level UPDATE", "BEGIN; " + update, ""},
+		{"UPDATE in savepoint s1", "SAVEPOINT s1; " + update, "s1"},
+		{"UPDATE in savepoint s2", "SAVEPOINT s2; " + update, "s2"},
+		{"ROLLBACK TO SAVEPOINT s2", "ROLLBACK TO SAVEPOINT s2", ""},
+	}
+	switch finish {
+	case "commit":
+		steps = append(steps, struct{ label, sql, savepoint string }{"COMMIT", "COMMIT", ""})
+	case "rollback":
+		steps = append(steps, struct{ label, sql, savepoint string }{"ROLLBACK", "ROLLBACK", ""})
+	}
+
+	res := &SavepointScenarioResult{Success: true, Table: table, PK: pk, Column: column, Finish: finish, SubXacts: []SubXact{}, Stages: []SavepointStage{}}
+	owners := map[int64]string{} // 32-bit on-page XID -> who wrote it
+	var prev []SavepointVersion
+	for _, st := range steps {
+		r, err := i.ExecSession(ctx, savepointSession, st.sql)
+		if err != nil {
+			return fail(err.Error()), nil
+		}
+		if !r.Success {
+			return fail(fmt.Sprintf("%s: %s", st.sql, r.Error)), nil
+		}
+		stage := SavepointStage{Label: st.label, SQL: st.sql}
+
+		// read the page before the probe, whose reads could set hint bits
+		if stage.Versions, err = i.savepointVersions(ctx, table, loc, attrs, column); err != nil {
+			return fail(err.Error()), nil
+		}
+		if r.Session.TxStatus == "in transaction" {
+			p, err := i.ExecSession(ctx, savepointSession, probe)
+			if err != nil {
+				return fail(err.Error()), nil
+			}
+			if !p.Success || len(p.Results) == 0 || len(p.Results[0].Rows) != 1 {
+				return fail(fmt.Sprintf("row pk=%s not visible inside the session", pk)), nil
+			}
+			row := p.Results[0].Rows[0]
+			stage.InsideTID = *row[0]
+			xmin, _ := strconv.ParseInt(*row[1], 10, 64)
+			top, _ := strconv.ParseInt(*row[2], 10, 64)
+			if res.TopXid == 0 {
+				res.TopXid = top
+				owners[top%(1<<32)] = "top-level"
+			}
+			if st.savepoint != "" {
+				owners[xmin] = st.savepoint
+				res.SubXacts = append(res.SubXacts, SubXact{Savepoint: st.savepoint, Xid: xmin, Parent: top})
+			}
+		}
+		for j := range stage.Versions {
+			v := &stage.Versions[j]
+			v.XminOwner, v.XmaxOwner = owners[v.Xmin], owners[v.Xmax]
+			v.VisibleInside = v.TID == stage.InsideTID
+		}
+		stage.PageChanged = prev != nil && versionsChanged(prev, stage.Versions)
+		stage.Explanation = savepointStageExplanation(res, &stage, st.savepoint)
+		prev = stage.Versions
+		res.Stages = append(res.Stages, stage)
+	}
+
+	c, err := i.newXactCache(ctx)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	var xids []int64
+	for j := range res.SubXacts {
+		res.SubXacts[j].Xid = c.ref.fullXid(res.SubXacts[j].Xid)
+		xids = append(xids, res.SubXacts[j].Xid)
+	}
+	if err := i.loadXactStatus(ctx, c, xids); err != nil {
+		return fail(err.Error()), nil
+	}
+	for j := range res.SubXacts {
+		res.SubXacts[j].Status = c.status[res.SubXacts[j].Xid]
+	}
+
+	if finish == "hold" {
+		// pg_current_snapshot() leaves out the session's own subxids; without
+		// them the versions s1 wrote would look like someone else's
+		var live []int64
+		for _, sx := range res.SubXacts {
+			if sx.Status != xactAborted {
+				live = append(live, sx.Xid)
+			}
+		}
+		if err := i.setSessionSubxids(savepointSession, live); err != nil {
+			return fail(err.Error()), nil
+		}
+		keep = true
+		res.Session = savepointSession
+	}
+	if finish == "commit" {
+		if _, err := i.pool.Exec(ctx, orig.restoreQuery(table, info.PKColumn), pk, orig.value); err != nil {
+			return fail(fmt.Sprintf("restore %s: %v", column, err)), nil
+		}
+		res.Restored = true
+	}
+	res.Explanation = savepointExplanation(res)
+	return res, nil
+}
+
+// savepointVersions follows the row's update chain from loc, across pages
+// if need be
+func (i *Inspector) savepointVersions(ctx context.Context, table string, loc *RowLocation, attrs []heapAttr, column string) ([]SavepointVersion, error) {
+	pages := map[int]*HeapPageDetail{}
+	cids := map[int]map[int]int{}
+	values := map[int]map[int]map[string]*string{}
+	out := []SavepointVersion{}
+
+	blk, item := loc.Page, loc.Item
+	var prev *HeapTuple
+	for range savepointChainMax {
+		page, ok := pages[blk]
+		if !ok {
+			var err error
+			if page, err = i.GetHeapPageAt(ctx, table, blk, nil); err != nil {
+				return nil, err
+			}
+			if cids[blk], err = i.pageCids(ctx, table, blk); err != nil {
+				return nil, err
+			}
+			if values[blk], err = i.pageAttrValues(ctx, table, blk, attrs); err != nil {
+				return nil, err
+			}
+			pages[blk] = page
+		}
+		t := findTuple(page.Tuples, item)
+		if t == nil || t.LPFlags != lpNormal || prev != nil && t.Xmin != prev.Xmax {
+			break
+		}
+		out = append(out, SavepointVersion{
+			TID:            fmt.Sprintf("(%d,%d)", blk, item),
+			Xmin:           t.Xmin,
+			Xmax:           t.Xmax,
+			XminStatus:     t.XminStatus,
+			XmaxStatus:     t.XmaxStatus,
+			Ctid:           t.Ctid,
+			InfoMask:       t.InfoMask,
+			ComboCid:       t.RawInfoMask&heapComboCid != 0,
+			Cid:            cids[blk][item],
+			Value:          values[blk][item][column],
+			VisibleOutside: t.IsLive,
+		})
+		nb, ni, ok := parseCtid(t.Ctid)
+		if !ok || nb == blk && ni == item {
+			break
+		}
+		prev, blk, item = t, nb, ni
+	}
+	return out, nil
+}
+
+func (i *Inspector) pageCids(ctx context.Context, table string, blk int) (map[int]int, error) {
+	rows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("heap-page-cids").Query(), table, blk)
+	if err != nil {
+		return nil, fmt.Errorf("t_cid %s blk %d: %w", table, blk, err)
+	}
+	defer rows.Close()
+	out := map[int]int{}
+	for rows.Next() {
+		var lp, cid int
+		if err := rows.Scan(&lp, &cid); err != nil {
+			return nil, fmt.Errorf("scan t_cid: %w", err)
+		}
+		out[lp] = cid
+	}
+	return out, rows.Err()
+}
+
+func (i *Inspector) pageAttrValues(ctx context.Context, table string, blk int, attrs []heapAttr) (map[int]map[string]*string, error) {
+	rows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("heap-range-item-attrs").Query(), table, blk, blk)
+	if err != nil {
+		return nil, fmt.Errorf("heap_page_item_attrs %s blk %d: %w", table, blk, err)
+	}
+	defer rows.Close()
+	out := map[int]map[string]*string{}
+	for rows.Next() {
+		var b, lp int
+		var raw [][]byte
+		if err := rows.Scan(&b, &lp, &raw); err != nil {
+			return nil, fmt.Errorf("scan tuple attrs: %w", err)
+		}
+		out[lp] = decodeHeapAttrs(attrs, raw)
+	}
+	return out, rows.Err()
+}
+
+// versionsChanged reports whether anything on disk differs, statuses aside
+func versionsChanged(a, b []SavepointVersion) bool {
+	if len(a) != len(b) {
+		return true
+	}
+	for j := range a {
+		if a[j].TID != b[j].TID || a[j].Xmin != b[j].Xmin || a[j].Xmax != b[j].Xmax || a[j].Cid != b[j].Cid ||
+			!slices.Equal(a[j].InfoMask, b[j].InfoMask) {
+			return true
+		}
+	}
+	return false
+}
+
+func savepointStageExplanation(res *SavepointScenarioResult, st *SavepointStage, savepoint string) string {
+	top := res.TopXid % (1 << 32)
+	switch {
+	case st.Label == "top-level UPDATE":
+		return fmt.Sprintf("The first write assigned the top-level XID %d: the old version got xmax %d and the new one xmin %d.", top, top, top)
+	case savepoint != "":
+		sub := res.SubXacts[len(res.SubXacts)-1]
+		out := fmt.Sprintf("SAVEPOINT %s started a subtransaction and its first write assigned it XID %d, a child of %d. "+
+			"The new version's xmin is %d, not %d: nothing on the page links the two, only pg_subtrans records the parent.",
+			savepoint, sub.Xid, top, sub.Xid, top)
+		for _, v := range st.Versions {
+			if v.ComboCid && v.XmaxOwner == savepoint {
+				out += fmt.Sprintf(" Version %s was written and now deleted by the same transaction, so it needs both cmin and cmax and only has room for one: "+
+					"HEAP_COMBOCID is set and t_cid holds combo CID %d, an index into a (cmin, cmax) array that lives only in this backend's memory.", v.TID, v.Cid)
+			}
+		}
+		return out
+	case strings.HasPrefix(st.Label, "ROLLBACK TO"):
+		out := "ROLLBACK TO SAVEPOINT s2 marked s2's XID aborted in pg_xact"
+		if !st.PageChanged {
+			out += " and didn't touch the page at all"
+		}
+		out += fmt.Sprintf(". The version s2 wrote is now dead to everyone, and the version it replaced has an aborted xmax, so it counts as live again: the session sees the row at %s.", st.InsideTID)
+		return out
+	case st.Label == "COMMIT":
+		return "COMMIT marked the top-level XID and s1 committed at once; s2 stays aborted. Hint bits get set later by whoever reads these tuples next."
+	default:
+		return "ROLLBACK aborted the top-level XID and every subtransaction with it; the original version's xmax is aborted, so it is live again."
+	}
+}
+
+func savepointExplanation(res *SavepointScenarioResult) string {
+	out := fmt.Sprintf("🪆 Each savepoint that writes gets its own XID, so the versions of row %s carry %d different xmins from one transaction. "+
+		"Visibility checks have to map a subxid to its parent: the backend advertises up to %d subxids in PGPROC, and past that every snapshot overflows and looks them up in pg_subtrans, which is why thousands of savepoints slow a whole cluster down.",
+		res.PK, len(res.SubXacts)+1, maxCachedSubxids)
+	if res.Restored {
+		out += fmt.Sprintf(" After the COMMIT one more UPDATE wrote the original %s back, so the row keeps its data but gains one more version.", res.Column)
+	}
+	if res.Session != "" {
+		out += fmt.Sprintf(" The transaction is still open in session %s: view the page with its snapshot, run more statements in it, or close it to roll everything back.", res.Session)
+	}
+	return out
+}
@@ -0,0 +1,463 @@
+package inspector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	sessionIdleTimeout = 10 * time.Minute
+	sessionStmtTimeout = 20 * time.Second
+	sessionMaxRows     = 100
+
+	// hard limit for one ExecSession call, however many statements it runs
+	sessionExecTimeout   = 60 * time.Second
+	sessionCancelTimeout = 5 * time.Second
+
+	// connections left in the pool for the inspector itself
+	sessionPoolReserve = 2
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionExists   = errors.New("session already exists")
+	ErrTooManySessions = errors.New("too many open sessions")
+)
+
+// session is a pooled connection pinned to a name, so a transaction can
+// span several HTTP requests.
+type session struct {
+	run    sync.Mutex // serializes statements on conn
+	closed bool       // guarded by run
+	conn   *pgxpool.Conn
+	timer  *time.Timer
+	info   SessionInfo // guarded by sessionManager.mu
+
+	// subtransaction XIDs of the open transaction, when known; guarded by
+	// sessionManager.mu
+	subxids []int64
+}
+
+type sessionManager struct {
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+func newSessionManager() *sessionManager {
+	return &sessionManager{sessions: make(map[string]*session)}
+}
+
+// OpenSession pins a pool connection under name. isolation sets the
+// session's default transaction isolation level.
+func (i *Inspector) OpenSession(ctx context.Context, name, isolation string) (*SessionInfo, error) {
+	m := i.sessions
+	m.mu.Lock()
+	if _, ok := m.sessions[name]; ok {
+		m.mu.Unlock()
+		return nil, ErrSessionExists
+	}
+	if len(m.sessions) >= max(1, int(i.pool.Config().MaxConns)-sessionPoolReserve) {
+		m.mu.Unlock()
+		return nil, ErrTooManySessions
+	}
+	// reserve the name while connecting
+	s := &session{info: SessionInfo{Name: name}}
+	m.sessions[name] = s
+	m.mu.Unlock()
+
+	drop := func() {
+		m.mu.Lock()
+		delete(m.sessions, name)
+		m.mu.Unlock()
+	}
+	conn, err := i.pool.Acquire(ctx)
+	if err != nil {
+		drop()
+		return nil, fmt.Errorf("acquire connection: %w", err)
+	}
+	setup := fmt.Sprintf("SET application_name = 'pgstoviz session %s'; SET statement_timeout = %d", name, sessionStmtTimeout.Milliseconds())
+	if isolation != "" {
+		setup += fmt.Sprintf("; SET default_transaction_isolation = '%s'", isolation)
+	}
+	if _, err := conn.Exec(ctx, setup); err != nil {
+		conn.Release()
+		drop()
+		return nil, fmt.Errorf("session setup: %w", err)
+	}
+
+	now := time.Now()
+	info := SessionInfo{Name: name, TxStatus: "idle", OpenedAt: now, LastUsed: now, ExpiresAt: now.Add(sessionIdleTimeout)}
+	if err := conn.QueryRow(ctx, i.qs.MustHaveQuery("session-state").Query()).Scan(&info.Pid, &info.Xid, &info.Isolation); err != nil {
+		conn.Release()
+		drop()
+		return nil, fmt.Errorf("session state: %w", err)
+	}
+	s.timer = time.AfterFunc(sessionIdleTimeout, func() { i.CloseSession(name) })
+
+	m.mu.Lock()
+	s.conn, s.info = conn, info
+	m.mu.Unlock()
+	return &info, nil
+}
+
+// ListSessions returns the open sessions ordered by name
+func (i *Inspector) ListSessions() []SessionInfo {
+	m := i.sessions
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]SessionInfo, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		if s.conn != nil {
+			out = append(out, s.info)
+		}
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Name < out[b].Name })
+	return out
+}
+
+// CloseSession rolls back whatever the session has open and returns its
+// connection to the pool.
+func (i *Inspector) CloseSession(name string) error {
+	m := i.sessions
+	m.mu.Lock()
+	s, ok := m.sessions[name]
+	if !ok || s.conn == nil {
+		m.mu.Unlock()
+		return ErrSessionNotFound
+	}
+	delete(m.sessions, name)
+	m.mu.Unlock()
+
+	s.timer.Stop()
+	// a statement still running holds run; cancel it rather than wait for
+	// statement_timeout
+	if !s.run.TryLock() {
+		cctx, cancel := context.WithTimeout(context.Background(), sessionCancelTimeout)
+		s.conn.Conn().PgConn().CancelRequest(cctx)
+		cancel()
+		s.run.Lock()
+	}
+	defer s.run.Unlock()
+	s.closed = true
+	ctx := context.Background()
+	if s.conn.Conn().PgConn().TxStatus() != 'I' {
+		s.conn.Exec(ctx, "ROLLBACK")
+	}
+	s.conn.Exec(ctx, "RESET ALL")
+	s.conn.Release()
+	return nil
+}
+
+// CloseAllSessions closes every open session
+func (i *Inspector) CloseAllSessions() {
+	for _, s := range i.ListSessions() {
+		i.CloseSession(s.Name)
+	}
+}
+
+// holdSession makes the session expire after d instead of the idle timeout
+func (i *Inspector) holdSession(name string, d time.Duration) error {
+	s, err := i.session(name)
+	if err != nil {
+		return err
+	}
+	s.run.Lock()
+	defer s.run.Unlock()
+	if s.closed {
+		return ErrSessionNotFound
+	}
+	s.timer.Reset(d)
+	i.sessions.mu.Lock()
+	s.info.ExpiresAt = time.Now().Add(d)
+	i.sessions.mu.Unlock()
+	return nil
+}
+
+// ExecSession runs sql (one or more statements) on the session's
+// connection. SQL errors are reported in the result, like the demos do.
+func (i *Inspector) ExecSession(ctx context.Context, name, sql string) (*SessionExecResult, error) {
+	s, err := i.session(name)
+	if err != nil {
+		return nil, err
+	}
+	s.run.Lock()
+	if s.closed {
+		s.run.Unlock()
+		return nil, ErrSessionNotFound
+	}
+	s.timer.Reset(sessionIdleTimeout)
+
+	res := &SessionExecResult{Success: true, Statement: sql, Results: []StatementResult{}}
+	// statement_timeout bounds each statement and the deadline the whole
+	// batch; don't let a closed browser tab cancel the statement and take
+	// the connection down with it
+	ectx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sessionExecTimeout)
+	defer cancel()
+	start := time.Now()
+	results, err := s.conn.Conn().PgConn().Exec(ectx, sql).ReadAll()
+	res.DurationMs = time.Since(start).Milliseconds()
+	for _, r := range results {
+		out := StatementResult{CommandTag: r.CommandTag.String(), Rows: [][]*string{}}
+		for _, f := range r.FieldDescriptions {
+			out.Columns = append(out.Columns, f.Name)
+		}
+		for _, row := range r.Rows {
+			if len(out.Rows) == sessionMaxRows {
+				out.Truncated = true
+				break
+			}
+			vals := make([]*string, len(row))
+			for j, v := range row {
+				if v != nil {
+					str := string(v)
+					vals[j] = &str
+				}
+			}
+			out.Rows = append(out.Rows, vals)
+		}
+		res.Results = append(res.Results, out)
+	}
+	if err != nil {
+		res.Success = false
+		res.Error = err.Error()
+	}
+	if s.conn.Conn().IsClosed() {
+		s.run.Unlock()
+		i.CloseSession(name)
+		res.Success = false
+		res.Error = "connection lost: " + res.Error
+		return res, nil
+	}
+
+	// the state queries run on the same connection, so they get the same
+	// protection; if only they fail, the statement's results still stand
+	info, err := i.sessionState(ectx, s, sql)
+	if err != nil {
+		i.sessions.mu.Lock()
+		res.Session = s.info
+		i.sessions.mu.Unlock()
+		res.StateError = err.Error()
+	} else {
+		res.Session = *info
+	}
+	lost := s.conn.Conn().IsClosed()
+	s.run.Unlock()
+	if lost {
+		i.CloseSession(name)
+	}
+	return res, nil
+}
+
+// SessionSnapshot is the snapshot the session's transaction reads with, or
+// nil when it has none yet.
+func (i *Inspector) SessionSnapshot(name string) (*Snapshot, error) {
+	s, err := i.session(name)
+	if err != nil {
+		return nil, err
+	}
+	i.sessions.mu.Lock()
+	defer i.sessions.mu.Unlock()
+	return s.info.Snapshot, nil
+}
+
+func (i *Inspector) session(name string) (*session, error) {
+	m := i.sessions
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[name]
+	if !ok || s.conn == nil {
+		return nil, ErrSessionNotFound
+	}
+	return s, nil
+}
+
+// sessionState refreshes the session's transaction state after sql ran.
+// The snapshot is only read once the transaction has taken one: asking for
+// it right after BEGIN would fix a REPEATABLE READ snapshot too early.
+func (i *Inspector) sessionState(ctx context.Context, s *session, sql string) (*SessionInfo, error) {
+	i.sessions.mu.Lock()
+	info, subxids := s.info, s.subxids
+	i.sessions.mu.Unlock()
+
+	now := time.Now()
+	info.LastUsed, info.ExpiresAt = now, now.Add(sessionIdleTimeout)
+	info.Statements++
+	info.LastStatement = sql
+
+	switch s.conn.Conn().PgConn().TxStatus() {
+	case 'I':
+		info.TxStatus, info.Xid, info.Snapshot, subxids = "idle", 0, nil, nil
+	case 'E':
+		info.TxStatus = "failed"
+	default:
+		info.TxStatus = "in transaction"
+		// any query, including the ones below, would take the snapshot
+		if !takesSnapshot(sql) {
+			break
+		}
+		var snap string
+		if err := s.conn.QueryRow(ctx, i.qs.MustHaveQuery("session-state").Query()).Scan(&info.Pid, &info.Xid, &info.Isolation); err != nil {
+			return nil, fmt.Errorf("session state: %w", err)
+		}
+		// under read committed this is the snapshot the next statement gets
+		if err := s.conn.QueryRow(ctx, i.qs.MustHaveQuery("current-snapshot").Query()).Scan(&snap); err != nil {
+			return nil, fmt.Errorf("session snapshot: %w", err)
+		}
+		parsed, err := ParseSnapshot(snap)
+		if err != nil {
+			return nil, err
+		}
+		parsed.Current, parsed.Subxids = info.Xid, subxids
+		info.Snapshot = parsed
+	}
+
+	i.sessions.mu.Lock()
+	s.info, s.subxids = info, subxids
+	i.sessions.mu.Unlock()
+	return &info, nil
+}
+
+// setSessionSubxids tells the session's snapshot which XIDs belong to its
+// open subtransactions, so its own writes under a savepoint count as its
+// own. They are forgotten when the transaction ends.
+func (i *Inspector) setSessionSubxids(name string, xids []int64) error {
+	s, err := i.session(name)
+	if err != nil {
+		return err
+	}
+	i.sessions.mu.Lock()
+	defer i.sessions.mu.Unlock()
+	s.subxids = xids
+	if s.info.Snapshot != nil {
+		snap := *s.info.Snapshot
+		snap.Subxids = xids
+		s.info.Snapshot = &snap
+	}
+	return nil
+}
+
+// takesSnapshot reports whether any statement in sql needs a snapshot, as
+// opposed to transaction control and SET
+func takesSnapshot(sql string) bool {
+	for _, stmt := range splitStatements(sql) {
+		f := strings.Fields(stmt)
+		if len(f) == 0 {
+			continue
+		}
+		switch strings.ToUpper(f[0]) {
+		case "BEGIN", "START", "SET", "SAVEPOINT", "RELEASE", "COMMIT", "END", "ROLLBACK", "ABORT":
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// quoteLiteral quotes s for the simple-protocol SQL sessions run. The E''
+// form reads backslashes as escapes whatever standard_conforming_strings
+// says, so both they and quotes are doubled.
+func quoteLiteral(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "E'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// splitStatements splits sql on the semicolons outside string literals,
+// quoted identifiers, dollar quotes and comments, and drops the comments
+func splitStatements(sql string) []string {
+	var out []string
+	var cur strings.Builder
+	for j := 0; j < len(sql); j++ {
+		c := sql[j]
+		switch {
+		case c == ';':
+			out = append(out, cur.String())
+			cur.Reset()
+			continue
+		case c == '-' && strings.HasPrefix(sql[j:], "--"):
+			end := strings.IndexByte(sql[j:], '\n')
+			if end < 0 {
+				j = len(sql)
+			} else {
+				j += end
+			}
+			cur.WriteByte(' ')
+			continue
+		case c == '/' && strings.HasPrefix(sql[j:], "/*"):
+			// block comments nest in PostgreSQL
+			depth := 0
+			for ; j < len(sql); j++ {
+				if strings.HasPrefix(sql[j:], "/*") {
+					depth++
+					j++
+				} else if strings.HasPrefix(sql[j:], "*/") {
+					depth--
+					j++
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			cur.WriteByte(' ')
+			continue
+		case c == '\'' || c == '"':
+			end := j + 1
+			for end < len(sql) {
+				if sql[end] == c {
+					// a doubled quote is an escaped one
+					if end+1 < len(sql) && sql[end+1] == c {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			end = min(end, len(sql)-1)
+			cur.WriteString(sql[j : end+1])
+			j = end
+			continue
+		case c == '$' && (j == 0 || !identChar(sql[j-1])):
+			if tag := dollarTag(sql[j:]); tag != "" {
+				end := strings.Index(sql[j+len(tag):], tag)
+				if end < 0 {
+					end = len(sql) - j - len(tag)
+				} else {
+					end += len(tag)
+				}
+				end = min(j+len(tag)+end, len(sql))
+				cur.WriteString(sql[j:end])
+				j = end - 1
+				continue
+			}
+		}
+		cur.WriteByte(c)
+	}
+	return append(out, cur.String())
+}
+
+// dollarTag returns the opening $tag$ of a dollar-quoted string at the
+// start of s, or "" if there is none
+func dollarTag(s string) string {
+	for k := 1; k < len(s); k++ {
+		c := s[k]
+		switch {
+		case c == '$':
+			return s[:k+1]
+		case identChar(c) && (k > 1 || c < '0' || c > '9'):
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+func identChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
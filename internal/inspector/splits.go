@@ -0,0 +1,334 @@
+package inspector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const splitDemoMaxKeys = 10000
+
+type treeSnapshot struct {
+	meta      *BTreeMeta
+	pages     map[int]PageStats
+	highKeys  map[int]string
+	leafPages int
+	leafFill  float64
+}
+
+// ExecuteSplitDemo inserts a batch of keys into a scratch copy of the
+// indexed table and reports how the copy of the B-tree split to make room
+// for them.
+func (i *Inspector) ExecuteSplitDemo(ctx context.Context, indexName, mode string, count int, keys []string) (*SplitDemoResult, error) {
+	fail := func(msg string) *SplitDemoResult {
+		return &SplitDemoResult{Success: false, Error: msg, Mode: mode}
+	}
+	if mode == "list" {
+		count = len(keys)
+	}
+	if count <= 0 {
+		return fail("no keys to insert"), nil
+	}
+	if count > splitDemoMaxKeys {
+		return fail(fmt.Sprintf("at most %d keys per batch", splitDemoMaxKeys)), nil
+	}
+
+	idx, err := i.indexByName(ctx, indexName)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	if len(idx.KeyColumns) == 0 || slices.Contains(idx.Expressions, idx.KeyColumns[0]) {
+		return fail("leading index column must be a plain table column"), nil
+	}
+	res := &SplitDemoResult{
+		Success: true,
+		Index:   indexName,
+		Table:   idx.TableName,
+		Column:  idx.KeyColumns[0],
+		Mode:    mode,
+	}
+
+	var typ, category string
+	if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("column-type").Query(), res.Table, res.Column).Scan(&typ, &category); err != nil {
+		return fail(fmt.Sprintf("column %s not found", res.Column)), nil
+	}
+	var dedup, unique, include bool
+	if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("index-build-options").Query(), indexName).Scan(&res.Fillfactor, &dedup, &unique, &include); err != nil {
+		return fail(fmt.Sprintf("index options %s: %v", indexName, err)), nil
+	}
+
+	scratch := fmt.Sprintf("pgstoviz_exp_%d", time.Now().UnixNano())
+	defer i.pool.Exec(context.WithoutCancel(ctx), fmt.Sprintf("DROP TABLE IF EXISTS %s", scratch))
+	if err := i.splitScratch(ctx, res, scratch); err != nil {
+		return fail(err.Error()), nil
+	}
+
+	var q string
+	args := []any{count}
+	switch {
+	case mode == "sequential" && category == "N":
+		q = fmt.Sprintf("INSERT INTO %[1]s (%[2]s) OVERRIDING SYSTEM VALUE SELECT (COALESCE((SELECT max(%[2]s) FROM %[1]s), 0) + g)::%[3]s FROM generate_series(1, $1) g ON CONFLICT DO NOTHING",
+			res.Scratch, res.Column, typ)
+	case mode == "sequential":
+		return fail("sequential keys need a numeric column"), nil
+	case mode == "random" && typ == "uuid":
+		q = fmt.Sprintf("INSERT INTO %s (%s) OVERRIDING SYSTEM VALUE SELECT gen_random_uuid() FROM generate_series(1, $1) ON CONFLICT DO NOTHING",
+			res.Scratch, res.Column)
+	case mode == "random" && category == "N":
+		q = fmt.Sprintf("INSERT INTO %[1]s (%[2]s) OVERRIDING SYSTEM VALUE SELECT (b.lo + random() * greatest(b.hi - b.lo, $1))::%[3]s FROM (SELECT COALESCE(min(%[2]s), 0) lo, COALESCE(max(%[2]s), 0) hi FROM %[1]s) b, generate_series(1, $1) ON CONFLICT DO NOTHING",
+			res.Scratch, res.Column, typ)
+	case mode == "random" && category == "S":
+		q = fmt.Sprintf("INSERT INTO %s (%s) OVERRIDING SYSTEM VALUE SELECT md5(random()::text)::%s FROM generate_series(1, $1) ON CONFLICT DO NOTHING",
+			res.Scratch, res.Column, typ)
+	case mode == "random":
+		return fail(fmt.Sprintf("random keys are not supported for %s", typ)), nil
+	case mode == "list":
+		q = fmt.Sprintf("INSERT INTO %s (%s) OVERRIDING SYSTEM VALUE SELECT k::%s FROM unnest($1::text[]) k ON CONFLICT DO NOTHING",
+			res.Scratch, res.Column, typ)
+		args = []any{keys}
+	default:
+		return fail("mode must be sequential, random or list"), nil
+	}
+
+	before, err := i.snapshotTree(ctx, res.ScratchIndex)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := i.pool.Exec(ctx, q, args...)
+	if err != nil {
+		return fail(fmt.Sprintf("insert failed: %v", err)), nil
+	}
+	res.Inserted = int(tag.RowsAffected())
+	after, err := i.snapshotTree(ctx, res.ScratchIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	diffTrees(before, after, res)
+	res.Explanation = splitExplanation(res)
+	return res, nil
+}
+
+// splitScratch copies the table with its indexes into scratch and relaxes
+// it so rows holding just the index key can go in: the primary key goes
+// unless it is the demo's index, and NOT NULL with it
+func (i *Inspector) splitScratch(ctx context.Context, res *SplitDemoResult, scratch string) error {
+	create := fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING ALL) WITH (autovacuum_enabled = false)", scratch, res.Table)
+	if _, err := i.pool.Exec(ctx, create); err != nil {
+		return fmt.Errorf("create scratch table: %w", err)
+	}
+	if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("scratch-index-twin").Query(), res.Index, scratch).Scan(&res.ScratchIndex); err != nil {
+		return fmt.Errorf("find copy of %s: %w", res.Index, err)
+	}
+	res.Scratch = scratch
+
+	var pkey string
+	err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("scratch-other-pkey").Query(), scratch, res.ScratchIndex).Scan(&pkey)
+	if err == nil {
+		if _, err := i.pool.Exec(ctx, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", scratch, pkey)); err != nil {
+			return fmt.Errorf("drop primary key: %w", err)
+		}
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("primary key of %s: %w", scratch, err)
+	}
+	rows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("scratch-not-null-columns").Query(), scratch)
+	if err != nil {
+		return fmt.Errorf("not null columns: %w", err)
+	}
+	cols, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return fmt.Errorf("not null columns: %w", err)
+	}
+	for _, c := range cols {
+		if _, err := i.pool.Exec(ctx, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP NOT NULL", scratch, c)); err != nil {
+			return fmt.Errorf("drop not null %s: %w", c, err)
+		}
+	}
+
+	copyQ := fmt.Sprintf("INSERT INTO %s OVERRIDING SYSTEM VALUE SELECT * FROM %s ORDER BY %s LIMIT %d", scratch, res.Table, res.Column, experimentMaxRows)
+	tag, err := i.pool.Exec(ctx, copyQ)
+	if err != nil {
+		return fmt.Errorf("copy rows: %w", err)
+	}
+	res.CopiedRows = int(tag.RowsAffected())
+	return nil
+}
+
+func (i *Inspector) snapshotTree(ctx context.Context, indexName string) (*treeSnapshot, error) {
+	meta, err := i.GetMeta(ctx, indexName)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := i.GetAllPageStats(ctx, indexName)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := i.getIndexAttrTypes(ctx, indexName)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &treeSnapshot{meta: meta, pages: make(map[int]PageStats, len(stats)), highKeys: make(map[int]string)}
+	var fill float64
+	for _, s := range stats {
+		snap.pages[s.BlockNo] = s
+		if s.BtpoLevel == 0 && (s.Type == "l" || s.Type == "r") {
+			snap.leafPages++
+			fill += pageFill(s)
+		}
+	}
+	if snap.leafPages > 0 {
+		snap.leafFill = fill / float64(snap.leafPages)
+	}
+
+	rows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("bt-high-keys").Query(), indexName, len(stats)+1)
+	if err != nil {
+		return nil, fmt.Errorf("high keys %s: %w", indexName, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var blk int
+		var data string
+		var nulls bool
+		if err := rows.Scan(&blk, &data, &nulls); err != nil {
+			return nil, fmt.Errorf("scan high key: %w", err)
+		}
+		if k := decodeIndexKey(cols, data, nulls); len(k) > 0 {
+			snap.highKeys[blk] = strings.Join(k, ", ")
+		} else {
+			snap.highKeys[blk] = data
+		}
+	}
+	return snap, rows.Err()
+}
+
+// pageFill is the percentage of usable page space taken by items
+func pageFill(s PageStats) float64 {
+	usable := s.PageSize - pageHeader - btSpecialSize
+	if usable <= 0 {
+		return 0
+	}
+	return 100 * float64(usable-s.FreeSize) / float64(usable)
+}
+
+// rightmostFill is the left page fill above which a split of the rightmost
+// page counts as nbtree's ascending-insert split: nbtsplitloc.c leaves the
+// left page fillfactor full there (BTREE_NONLEAF_FILLFACTOR above the
+// leaves) instead of splitting 50/50, so halfway between the two tells them
+// apart.
+func rightmostFill(level, leafFillfactor int) float64 {
+	ff := leafFillfactor
+	if level > 0 {
+		ff = btNonLeafFillfactor
+	}
+	return float64(50+ff) / 2
+}
+
+func diffTrees(before, after *treeSnapshot, res *SplitDemoResult) {
+	res.PagesBefore, res.PagesAfter = len(before.pages)+1, len(after.pages)+1
+	res.LeafPagesBefore, res.LeafPagesAfter = before.leafPages, after.leafPages
+	res.LevelsBefore, res.LevelsAfter = before.meta.Level+1, after.meta.Level+1
+	res.AvgLeafFillBefore, res.AvgLeafFillAfter = before.leafFill, after.leafFill
+
+	isNew := func(blk int) bool {
+		old, ok := before.pages[blk]
+		return !ok || old.Type == "d"
+	}
+	for blk, s := range after.pages {
+		if isNew(blk) && s.Type != "d" {
+			res.NewPages = append(res.NewPages, blk)
+		}
+	}
+	sort.Ints(res.NewPages)
+
+	blocks := make([]int, 0, len(before.pages))
+	for blk := range before.pages {
+		blocks = append(blocks, blk)
+	}
+	sort.Ints(blocks)
+
+	for _, blk := range blocks {
+		old, now := before.pages[blk], after.pages[blk]
+		if old.Type == "d" {
+			continue
+		}
+		if before.highKeys[blk] != after.highKeys[blk] {
+			res.HighKeyChanges = append(res.HighKeyChanges, HighKeyChange{
+				BlockNo: blk,
+				Before:  before.highKeys[blk],
+				After:   after.highKeys[blk],
+			})
+		}
+		if now.BtpoNext == old.BtpoNext {
+			continue
+		}
+
+		// every new page between this one and its old right sibling was
+		// carved out of it by a split
+		sp := PageSplit{
+			BlockNo:      blk,
+			Level:        old.BtpoLevel,
+			WasRightmost: old.BtpoNext == 0,
+			ItemsBefore:  old.LiveItems + old.DeadItems,
+			ItemsAfter:   now.LiveItems + now.DeadItems,
+		}
+		fills := []float64{pageFill(now)}
+		for next := now.BtpoNext; next != 0 && next != old.BtpoNext && isNew(next); next = after.pages[next].BtpoNext {
+			sp.NewSiblings = append(sp.NewSiblings, next)
+			fills = append(fills, pageFill(after.pages[next]))
+		}
+		if len(sp.NewSiblings) == 0 {
+			continue
+		}
+		// the last page of the chain is still receiving inserts, only the
+		// left halves show where the split point was placed
+		var sum float64
+		for _, f := range fills[:len(fills)-1] {
+			sum += f
+		}
+		sp.LeftFill = sum / float64(len(fills)-1)
+		sp.RightmostOpt = sp.WasRightmost && sp.LeftFill >= rightmostFill(sp.Level, res.Fillfactor)
+		if sp.RightmostOpt {
+			res.RightmostSplits += len(sp.NewSiblings)
+		}
+		res.Splits = append(res.Splits, sp)
+	}
+}
+
+func splitExplanation(res *SplitDemoResult) string {
+	splits := 0
+	for _, s := range res.Splits {
+		splits += len(s.NewSiblings)
+	}
+	copied := fmt.Sprintf(" This ran on a scratch copy of %s holding its first %d rows by %s, dropped afterwards.", res.Table, res.CopiedRows, res.Column)
+	if splits == 0 {
+		return fmt.Sprintf("📥 %d keys fit into the existing pages, no page had to split.", res.Inserted) + copied
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "✂️ Inserting %d keys caused %d page splits; the index grew from %d to %d pages. ",
+		res.Inserted, splits, res.PagesBefore, res.PagesAfter)
+	if res.LevelsAfter > res.LevelsBefore {
+		fmt.Fprintf(&b, "The root split too, adding a level (%d → %d). ", res.LevelsBefore, res.LevelsAfter)
+	}
+	if res.RightmostSplits > 0 {
+		fmt.Fprintf(&b, "%d splits hit the rightmost page of their level: nbtree assumes ascending inserts there and leaves the left page at fillfactor (%d%% on leaves) instead of splitting 50/50. ",
+			res.RightmostSplits, res.Fillfactor)
+	}
+	if splits > res.RightmostSplits {
+		fmt.Fprintf(&b, "%d splits landed in the middle of the key space and divided the page roughly in half, leaving both halves with free space that only future keys in the same range can use. ",
+			splits-res.RightmostSplits)
+	}
+	fmt.Fprintf(&b, "Average leaf fill went from %.1f%% to %.1f%%. ", res.AvgLeafFillBefore, res.AvgLeafFillAfter)
+	if res.Mode == "random" {
+		b.WriteString("Random keys (like UUIDv4) spread inserts over all leaves, so splits are 50/50 and the index settles around 70% full, while sequential ids keep pages packed.")
+	}
+	b.WriteString(copied)
+	return strings.TrimSpace(b.String())
+}
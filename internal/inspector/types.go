@@ -1,5 +1,7 @@
 package inspector
 
+import "time"
+
 type BTreeMeta struct {
 	Magic                int   `json:"magic"`
 	Version              int   `json:"version"`
@@ -27,20 +29,22 @@ type PageStats struct {
 }
 
 type PageItem struct {
-	ItemOffset int    `json:"itemOffset"`
-	Ctid       string `json:"ctid"`
-	ItemLen    int    `json:"itemLen"`
-	Nulls      bool   `json:"nulls"`
-	Vars       bool   `json:"vars"`
-	Data       string `json:"data"`
-	Dead       bool   `json:"dead"`
-	Htid       string `json:"htid"`
-	Tids       string `json:"tids"`
+	ItemOffset int      `json:"itemOffset"`
+	Ctid       string   `json:"ctid"`
+	ItemLen    int      `json:"itemLen"`
+	Nulls      bool     `json:"nulls"`
+	Vars       bool     `json:"vars"`
+	Data       string   `json:"data"`
+	Keys       []string `json:"keys,omitempty"`
+	Dead       bool     `json:"dead"`
+	Htid       string   `json:"htid"`
+	Tids       string   `json:"tids"`
 }
 
 type PageDetail struct {
-	Stats PageStats  `json:"stats"`
-	Items []PageItem `json:"items"`
+	Stats   PageStats  `json:"stats"`
+	Columns []string   `json:"columns,omitempty"`
+	Items   []PageItem `json:"items"`
 }
 
 type IndexStats struct {
@@ -57,14 +61,23 @@ type IndexStats struct {
 }
 
 type IndexInfo struct {
-	Name      string   `json:"name"`
-	TableName string   `json:"tableName"`
-	IndexType string   `json:"indexType"`
-	Size      int64    `json:"size"`
-	NumPages  int64    `json:"numPages"`
-	IsUnique  bool     `json:"isUnique"`
-	IsPrimary bool     `json:"isPrimary"`
-	Columns   []string `json:"columns"`
+	Name           string   `json:"name"`
+	TableName      string   `json:"tableName"`
+	IndexType      string   `json:"indexType"`
+	Size           int64    `json:"size"`
+	NumPages       int64    `json:"numPages"`
+	IsUnique       bool     `json:"isUnique"`
+	IsPrimary      bool     `json:"isPrimary"`
+	Columns        []string `json:"columns"`            // plain table columns only
+	Elements       []string `json:"elements,omitempty"` // every index column, expressions as text
+	Definition     string   `json:"definition,omitempty"`
+	KeyColumns     []string `json:"keyColumns,omitempty"`
+	IncludeColumns []string `json:"includeColumns,omitempty"`
+	Expressions    []string `json:"expressions,omitempty"`
+	Predicate      string   `json:"predicate,omitempty"`
+	Opclasses      []string `json:"opclasses,omitempty"`
+	Collations     []string `json:"collations,omitempty"`
+	SortOrder      []string `json:"sortOrder,omitempty"`
 }
 
 type RowLocation struct {
@@ -105,6 +118,174 @@ type DemoUpdateResult struct {
 	NewTuple        *DemoTupleState `json:"newTuple"`
 	SamePage        bool            `json:"samePage"`
 	Explanation     string          `json:"explanation"`
+
+	RolledBack         bool             `json:"rolledBack,omitempty"`
+	Xid                int64            `json:"xid,omitempty"`
+	Inside             *TransactionView `json:"inside,omitempty"`
+	Outside            *TransactionView `json:"outside,omitempty"`
+	AfterRollback      *RollbackStage   `json:"afterRollback,omitempty"`
+	AfterRead          *RollbackStage   `json:"afterRead,omitempty"`
+	AbortedVacuumState string           `json:"abortedVacuumState,omitempty"`
+	Cleanup            string           `json:"cleanup,omitempty"`
+
+	HotReason *HotReason `json:"hotReason,omitempty"`
+}
+
+// HotReason is why heap_update did or did not do a HOT update, with the
+// numbers it went by
+type HotReason struct {
+	Code               string   `json:"code"`
+	Hot                bool     `json:"hot"`
+	Detail             string   `json:"detail"`
+	Column             string   `json:"column"`
+	ValueChanged       bool     `json:"valueChanged"`
+	BlockingIndexes    []string `json:"blockingIndexes"`
+	ExpressionIndexes  []string `json:"expressionIndexes"`
+	SummarizingIndexes []string `json:"summarizingIndexes"`
+	PageFree           int      `json:"pageFree"`      // HeapPageStats.FreeSpace before the update
+	HeapFreeSpace      int      `json:"heapFreeSpace"` // PageGetHeapFreeSpace before the update
+	OldTupleSize       int      `json:"oldTupleSize"`
+	NewTupleSize       int      `json:"newTupleSize"` // MAXALIGNed
+	LpCount            int      `json:"lpCount"`
+	MaxLps             int      `json:"maxLps"`
+	FillFactor         int      `json:"fillFactor"`
+	ReservedSpace      int      `json:"reservedSpace"`
+	Toasted            bool     `json:"toasted"`
+	ToastThreshold     int      `json:"toastThreshold"`
+	ServerVersion      int      `json:"serverVersion"`
+}
+
+type TransactionView struct {
+	Snapshot   *Snapshot `json:"snapshot"`
+	OldVisible bool      `json:"oldVisible"`
+	NewVisible bool      `json:"newVisible"`
+	OldReason  string    `json:"oldReason"`
+	NewReason  string    `json:"newReason"`
+}
+
+type RollbackStage struct {
+	Old   *DemoTupleState `json:"old,omitempty"`
+	OldLP string          `json:"oldLp"`
+	New   *DemoTupleState `json:"new,omitempty"`
+	NewLP string          `json:"newLp"`
+}
+
+type DemoInsertResult struct {
+	Success            bool              `json:"success"`
+	Error              string            `json:"error,omitempty"`
+	Table              string            `json:"table"`
+	Values             map[string]string `json:"values"`
+	PK                 string            `json:"pk,omitempty"`
+	After              DemoTupleState    `json:"after"`
+	Placement          string            `json:"placement"` // fsm, last_page or new_page
+	PagesBefore        int               `json:"pagesBefore"`
+	PagesAfter         int               `json:"pagesAfter"`
+	TupleSize          int               `json:"tupleSize"`
+	SpaceNeeded        int               `json:"spaceNeeded"`
+	ReusedLP           bool              `json:"reusedLp"`
+	FillFactor         int               `json:"fillFactor"`
+	ReservedSpace      int               `json:"reservedSpace"`
+	TargetFreeBefore   int               `json:"targetFreeBefore"`
+	TargetFreeAfter    int               `json:"targetFreeAfter"`
+	LastPageFreeBefore int               `json:"lastPageFreeBefore"`
+	FSMAvail           *int              `json:"fsmAvail,omitempty"`
+	Explanation        string            `json:"explanation"`
+}
+
+type DemoDeleteResult struct {
+	Success         bool               `json:"success"`
+	Error           string             `json:"error,omitempty"`
+	Table           string             `json:"table"`
+	PK              string             `json:"pk"`
+	Before          DemoTupleState     `json:"before"`
+	After           DemoTupleState     `json:"after"`
+	InfoMaskSet     []string           `json:"infoMaskSet"`
+	InfoMaskCleared []string           `json:"infoMaskCleared"`
+	PruneXidBefore  int64              `json:"pruneXidBefore"`
+	PruneXidAfter   int64              `json:"pruneXidAfter"`
+	PageFree        int                `json:"pageFree"`
+	PruneThreshold  int                `json:"pruneThreshold"`
+	VacuumState     string             `json:"vacuumState"`
+	Prune           *LinePointerChange `json:"prune,omitempty"` // what pruning the page now does to the line pointer
+	Explanation     string             `json:"explanation"`
+}
+
+type IndexItemChange struct {
+	BlockNo    int    `json:"blockNo"`
+	ItemOffset int    `json:"itemOffset"`
+	Htid       string `json:"htid"`
+	Key        string `json:"key"`
+	WasDead    bool   `json:"wasDead"`
+}
+
+type KillBitsDemoResult struct {
+	Success         bool              `json:"success"`
+	Error           string            `json:"error,omitempty"`
+	Step            string            `json:"step"`
+	Index           string            `json:"index"`
+	Table           string            `json:"table"`
+	Scratch         string            `json:"scratch"`
+	ScratchIndex    string            `json:"scratchIndex"`
+	Column          string            `json:"column"`
+	KeyFrom         string            `json:"keyFrom"`
+	KeyTo           string            `json:"keyTo"`
+	LeafPages       []int             `json:"leafPages"`
+	DeadVersions    int               `json:"deadVersions"`
+	DeadItemsBefore int               `json:"deadItemsBefore"`
+	DeadItemsAfter  int               `json:"deadItemsAfter"`
+	NewlyDead       []IndexItemChange `json:"newlyDead"`
+	Removed         []IndexItemChange `json:"removed"`
+	Rounds          int               `json:"rounds,omitempty"`
+	PageSplit       bool              `json:"pageSplit"`
+	ChurnXid        int64             `json:"churnXid"`
+	Horizon         int64             `json:"horizon"`
+	HeldBy          *HorizonSource    `json:"heldBy,omitempty"`
+	Killable        bool              `json:"killable"`
+	Explanation     string            `json:"explanation"`
+}
+
+type PageSplit struct {
+	BlockNo      int     `json:"blockNo"`
+	Level        int     `json:"level"`
+	NewSiblings  []int   `json:"newSiblings"`
+	WasRightmost bool    `json:"wasRightmost"`
+	RightmostOpt bool    `json:"rightmostOptimization"`
+	ItemsBefore  int     `json:"itemsBefore"`
+	ItemsAfter   int     `json:"itemsAfter"`
+	LeftFill     float64 `json:"leftFill"`
+}
+
+type HighKeyChange struct {
+	BlockNo int    `json:"blockNo"`
+	Before  string `json:"before"`
+	After   string `json:"after"`
+}
+
+type SplitDemoResult struct {
+	Success           bool            `json:"success"`
+	Error             string          `json:"error,omitempty"`
+	Index             string          `json:"index"`
+	Table             string          `json:"table"`
+	Scratch           string          `json:"scratch"`
+	ScratchIndex      string          `json:"scratchIndex"`
+	CopiedRows        int             `json:"copiedRows"`
+	Column            string          `json:"column"`
+	Fillfactor        int             `json:"fillfactor"`
+	Mode              string          `json:"mode"`
+	Inserted          int             `json:"inserted"`
+	PagesBefore       int             `json:"pagesBefore"`
+	PagesAfter        int             `json:"pagesAfter"`
+	LeafPagesBefore   int             `json:"leafPagesBefore"`
+	LeafPagesAfter    int             `json:"leafPagesAfter"`
+	LevelsBefore      int             `json:"levelsBefore"`
+	LevelsAfter       int             `json:"levelsAfter"`
+	AvgLeafFillBefore float64         `json:"avgLeafFillBefore"`
+	AvgLeafFillAfter  float64         `json:"avgLeafFillAfter"`
+	Splits            []PageSplit     `json:"splits"`
+	NewPages          []int           `json:"newPages"`
+	HighKeyChanges    []HighKeyChange `json:"highKeyChanges"`
+	RightmostSplits   int             `json:"rightmostSplits"`
+	Explanation       string          `json:"explanation"`
 }
 
 type TreeNode struct {
@@ -121,14 +302,101 @@ type TreeNode struct {
 }
 
 type BloatInfo struct {
-	IndexName       string  `json:"indexName"`
-	TotalPages      int64   `json:"totalPages"`
-	EmptyPages      int64   `json:"emptyPages"`
-	DeletedPages    int64   `json:"deletedPages"`
-	AvgDensity      float64 `json:"avgDensity"`
-	EstimatedBloat  float64 `json:"estimatedBloat"`
-	WastedBytes     int64   `json:"wastedBytes"`
-	RecommendAction string  `json:"recommendAction"`
+	IndexName       string           `json:"indexName"`
+	TotalPages      int64            `json:"totalPages"`
+	EmptyPages      int64            `json:"emptyPages"`
+	DeletedPages    int64            `json:"deletedPages"`
+	AvgDensity      float64          `json:"avgDensity"`
+	EstimatedBloat  float64          `json:"estimatedBloat"`
+	WastedBytes     int64            `json:"wastedBytes"`
+	RecommendAction string           `json:"recommendAction"`
+	Reindex         *ReindexEstimate `json:"reindex,omitempty"`
+}
+
+// ReindexEstimate is the shape a fresh REINDEX would produce, simulated from
+// the live leaf tuples packed with the index's own build settings.
+type ReindexEstimate struct {
+	Fillfactor    int   `json:"fillfactor"`
+	Deduplicate   bool  `json:"deduplicate"`
+	HeapTids      int64 `json:"heapTids"`
+	LeafTuples    int64 `json:"leafTuples"`
+	LeafPages     int64 `json:"leafPages"`
+	InternalPages int64 `json:"internalPages"`
+	TreeLevel     int   `json:"treeLevel"`
+	TotalPages    int64 `json:"totalPages"`
+	Size          int64 `json:"size"`
+	PagesDiff     int64 `json:"pagesDiff"`
+	BytesDiff     int64 `json:"bytesDiff"`
+	SampledPages  int   `json:"sampledPages"`
+	Sampled       bool  `json:"sampled"`
+}
+
+type HypotheticalIndexSpec struct {
+	Columns    []string `json:"columns"`
+	Include    []string `json:"include"`
+	Predicate  string   `json:"predicate"`
+	Fillfactor int      `json:"fillfactor"`
+	Unique     bool     `json:"unique"`
+}
+
+type HypotheticalIndex struct {
+	TableName     string   `json:"tableName"`
+	Definition    string   `json:"definition"`
+	TableRows     int64    `json:"tableRows"`
+	IndexedRows   int64    `json:"indexedRows"`
+	Selectivity   float64  `json:"selectivity"`
+	SampleRows    int      `json:"sampleRows"`
+	AvgTupleSize  float64  `json:"avgTupleSize"`
+	AvgKeySize    float64  `json:"avgKeySize"`
+	DistinctKeys  int64    `json:"distinctKeys"`
+	Deduplicate   bool     `json:"deduplicate"`
+	Fillfactor    int      `json:"fillfactor"`
+	LeafTuples    int64    `json:"leafTuples"`
+	LeafPages     int64    `json:"leafPages"`
+	InternalPages int64    `json:"internalPages"`
+	Height        int      `json:"height"`
+	TreeLevel     int      `json:"treeLevel"`
+	TotalPages    int64    `json:"totalPages"`
+	Size          int64    `json:"size"`
+	Warnings      []string `json:"warnings,omitempty"`
+}
+
+type IndexAdvice struct {
+	Scope            string         `json:"scope"`
+	StatsReset       string         `json:"statsReset,omitempty"`
+	Indexes          []IndexUsage   `json:"indexes"`
+	Findings         []IndexFinding `json:"findings"`
+	TotalWastedBytes int64          `json:"totalWastedBytes"`
+	BloatSkipped     int            `json:"bloatSkipped"`
+}
+
+type IndexUsage struct {
+	Name           string  `json:"name"`
+	TableName      string  `json:"tableName"`
+	IndexType      string  `json:"indexType"`
+	Definition     string  `json:"definition"`
+	Size           int64   `json:"size"`
+	IsUnique       bool    `json:"isUnique"`
+	IsPrimary      bool    `json:"isPrimary"`
+	IdxScan        int64   `json:"idxScan"`
+	IdxTupRead     int64   `json:"idxTupRead"`
+	IdxTupFetch    int64   `json:"idxTupFetch"`
+	EstimatedBloat float64 `json:"estimatedBloat"`
+	BloatBytes     int64   `json:"bloatBytes"`
+	BloatChecked   bool    `json:"bloatChecked"`
+	BloatError     string  `json:"bloatError,omitempty"`
+}
+
+// IndexFinding is one advisory flag. Kind is one of unused, duplicate,
+// overlapping or bloated; RelatedIndex names the index that makes this one
+// redundant.
+type IndexFinding struct {
+	Kind         string `json:"kind"`
+	Index        string `json:"index"`
+	TableName    string `json:"tableName"`
+	RelatedIndex string `json:"relatedIndex,omitempty"`
+	WastedBytes  int64  `json:"wastedBytes"`
+	Detail       string `json:"detail"`
 }
 
 type PageDensityMap struct {
@@ -194,24 +462,96 @@ type HeapPageStats struct {
 }
 
 type HeapTuple struct {
-	LP         int               `json:"lp"`
-	LPOffset   int               `json:"lpOffset"`
-	LPFlags    int               `json:"lpFlags"`
-	LPFlagsStr string            `json:"lpFlagsStr"`
-	ItemLen    int               `json:"itemLen"`
-	Xmin       int64             `json:"xmin"`
-	Xmax       int64             `json:"xmax"`
-	Ctid       string            `json:"ctid"`
-	InfoMask   []string          `json:"infoMask"`
-	IsLive     bool              `json:"isLive"`
-	IsHot      bool              `json:"isHot"`
-	IsUpdated  bool              `json:"isUpdated"`
-	Attrs      map[string]string `json:"attrs"`
+	LP           int               `json:"lp"`
+	LPOffset     int               `json:"lpOffset"`
+	LPFlags      int               `json:"lpFlags"`
+	LPFlagsStr   string            `json:"lpFlagsStr"`
+	ItemLen      int               `json:"itemLen"`
+	Xmin         int64             `json:"xmin"`
+	Xmax         int64             `json:"xmax"`
+	Ctid         string            `json:"ctid"`
+	InfoMask     []string          `json:"infoMask"`
+	RawInfoMask  int               `json:"rawInfoMask"`
+	RawInfoMask2 int               `json:"rawInfoMask2"`
+	IsLive       bool              `json:"isLive"`
+	IsHot        bool              `json:"isHot"`
+	IsUpdated    bool              `json:"isUpdated"`
+	XminStatus   string            `json:"xminStatus,omitempty"`
+	XmaxStatus   string            `json:"xmaxStatus,omitempty"`
+	XminCommitTs *time.Time        `json:"xminCommitTs,omitempty"`
+	XmaxCommitTs *time.Time        `json:"xmaxCommitTs,omitempty"`
+	XmaxMembers  []MultiXactMember `json:"xmaxMembers,omitempty"`
+	LockState    string            `json:"lockState,omitempty"`
+	Visibility   *TupleVisibility  `json:"visibility,omitempty"`
+	Attrs        map[string]string `json:"attrs"`
+}
+
+type TupleVisibility struct {
+	Visible    bool     `json:"visible"`
+	XminStatus string   `json:"xminStatus"`
+	XmaxStatus string   `json:"xmaxStatus,omitempty"`
+	UpdateXid  int64    `json:"updateXid,omitempty"`
+	Steps      []string `json:"steps"`
+}
+
+type MultiXactMember struct {
+	Xid    int64  `json:"xid"`
+	Mode   string `json:"mode"`
+	Lock   string `json:"lock"`
+	Status string `json:"status,omitempty"`
+}
+
+type RowLock struct {
+	Tid    string   `json:"tid"`
+	Page   int      `json:"page"`
+	Item   int      `json:"item"`
+	Locker int64    `json:"locker"`
+	Multi  bool     `json:"multi"`
+	Xids   []int64  `json:"xids"`
+	Modes  []string `json:"modes"`
+	Pids   []int    `json:"pids"`
+}
+
+type RowLocks struct {
+	Table     string    `json:"table"`
+	Available bool      `json:"available"`
+	Hint      string    `json:"hint,omitempty"`
+	Locks     []RowLock `json:"locks"`
+}
+
+type LockDemoResult struct {
+	Success     bool           `json:"success"`
+	Error       string         `json:"error,omitempty"`
+	Table       string         `json:"table"`
+	PK          string         `json:"pk"`
+	Session     string         `json:"session"`
+	Locker      int64          `json:"locker"`
+	Before      DemoTupleState `json:"before"`
+	During      DemoTupleState `json:"during"`
+	LockState   string         `json:"lockState"`
+	RowLocks    []RowLock      `json:"rowLocks,omitempty"`
+	HeldFor     int            `json:"heldFor"`
+	ReleaseAt   time.Time      `json:"releaseAt"`
+	Explanation string         `json:"explanation"`
+}
+
+type MultiXactDemoResult struct {
+	Success     bool              `json:"success"`
+	Error       string            `json:"error,omitempty"`
+	Table       string            `json:"table"`
+	PK          string            `json:"pk"`
+	Lockers     []int64           `json:"lockers"`
+	Before      DemoTupleState    `json:"before"`
+	During      DemoTupleState    `json:"during"`
+	Members     []MultiXactMember `json:"members"`
+	After       DemoTupleState    `json:"after"`
+	Explanation string            `json:"explanation"`
 }
 
 type HeapPageDetail struct {
-	Stats  HeapPageStats `json:"stats"`
-	Tuples []HeapTuple   `json:"tuples"`
+	Stats    HeapPageStats `json:"stats"`
+	Snapshot *Snapshot     `json:"snapshot,omitempty"`
+	Tuples   []HeapTuple   `json:"tuples"`
 }
 
 type HeapPageMap struct {
@@ -220,11 +560,34 @@ type HeapPageMap struct {
 }
 
 type HeapPageInfo struct {
-	BlockNo    int     `json:"blockNo"`
-	LiveTuples int     `json:"liveTuples"`
-	DeadTuples int     `json:"deadTuples"`
-	FreeSpace  int     `json:"freeSpace"`
-	Density    float64 `json:"density"`
+	BlockNo            int     `json:"blockNo"`
+	LiveTuples         int     `json:"liveTuples"`
+	DeadTuples         int     `json:"deadTuples"`
+	FreeSpace          int     `json:"freeSpace"`
+	Density            float64 `json:"density"`
+	UnfrozenTuples     int     `json:"unfrozenTuples"`
+	OldestUnfrozenXmin int64   `json:"oldestUnfrozenXmin,omitempty"`
+	OldestXminAge      int64   `json:"oldestXminAge"`
+	AllFrozen          bool    `json:"allFrozen"`
+}
+
+type FreezeInfo struct {
+	Table                 string  `json:"table"`
+	RelFrozenXID          int64   `json:"relfrozenxid"`
+	FrozenXIDAge          int64   `json:"frozenXidAge"`
+	RelMinMXID            int64   `json:"relminmxid"`
+	MinMXIDAge            int64   `json:"minMxidAge"`
+	FreezeMaxAge          int64   `json:"freezeMaxAge"`
+	MultixactFreezeMaxAge int64   `json:"multixactFreezeMaxAge"`
+	FreezeMinAge          int64   `json:"freezeMinAge"`
+	FreezeTableAge        int64   `json:"freezeTableAge"`
+	FailsafeAge           *int64  `json:"failsafeAge"`
+	XIDsUntilForced       int64   `json:"xidsUntilForced"`
+	MXIDsUntilForced      int64   `json:"mxidsUntilForced"`
+	ForcedPct             float64 `json:"forcedPct"`
+	NextVacuumAggressive  bool    `json:"nextVacuumAggressive"`
+	Status                string  `json:"status"`
+	Explanation           string  `json:"explanation"`
 }
 
 type IndexedColumnsInfo struct {
@@ -235,10 +598,460 @@ type IndexedColumnsInfo struct {
 	IndexDetails   map[string]string `json:"indexDetails"`
 }
 
+type HorizonSource struct {
+	Kind          string     `json:"kind"`
+	Name          string     `json:"name"`
+	Field         string     `json:"field"`
+	Xid           int64      `json:"xid"`
+	Age           int64      `json:"age"`
+	AffectsTables bool       `json:"affectsTables"`
+	Since         *time.Time `json:"since,omitempty"`
+	Detail        string     `json:"detail,omitempty"`
+	IsOldest      bool       `json:"isOldest"`
+}
+
+type HorizonTuple struct {
+	LP     int    `json:"lp"`
+	Xmin   int64  `json:"xmin"`
+	Xmax   int64  `json:"xmax"`
+	State  string `json:"state"`
+	Reason string `json:"reason"`
+}
+
+type CleanupHorizon struct {
+	NextXID     int64           `json:"nextXid"`
+	Horizon     int64           `json:"horizon"`
+	HorizonAge  int64           `json:"horizonAge"`
+	HeldBy      *HorizonSource  `json:"heldBy,omitempty"`
+	Sources     []HorizonSource `json:"sources"`
+	Table       string          `json:"table,omitempty"`
+	BlockNo     int             `json:"blockNo,omitempty"`
+	Tuples      []HorizonTuple  `json:"tuples,omitempty"`
+	Explanation string          `json:"explanation"`
+}
+
 type MVCCInfo struct {
-	CurrentXID      int64   `json:"currentXid"`
-	CurrentSnapshot string  `json:"currentSnapshot"`
-	XIDMin          int64   `json:"xidMin"`
-	XIDMax          int64   `json:"xidMax"`
-	ActiveXIDs      []int64 `json:"activeXids"`
+	// Deprecated: always 0, reading the snapshot never assigns an XID. Use
+	// NextXID for the next XID to be handed out.
+	CurrentXID          int64   `json:"currentXid"`
+	CurrentSnapshot     string  `json:"currentSnapshot"`
+	XIDMin              int64   `json:"xidMin"`
+	XIDMax              int64   `json:"xidMax"`
+	ActiveXIDs          []int64 `json:"activeXids"`
+	Epoch               int64   `json:"epoch"`
+	NextXID             int64   `json:"nextXid"`
+	FrozenXIDAge        int64   `json:"frozenXidAge"`
+	FrozenMXIDAge       int64   `json:"frozenMxidAge"`
+	WraparoundRemaining int64   `json:"wraparoundRemaining"`
+	WraparoundPct       float64 `json:"wraparoundPct"`
+}
+
+type LinePointerChange struct {
+	LP         int    `json:"lp"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	RedirectTo int    `json:"redirectTo,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+type VacuumPagePrediction struct {
+	BlockNo          int                 `json:"blockNo"`
+	DeadTuples       int                 `json:"deadTuples"`
+	RecentlyDead     int                 `json:"recentlyDead"`
+	Changes          []LinePointerChange `json:"changes"`
+	FreedBytes       int                 `json:"freedBytes"`
+	TruncatedLPs     int                 `json:"truncatedLps"`
+	FreeBefore       int                 `json:"freeBefore"`
+	FreeAfter        int                 `json:"freeAfter"`
+	AllVisibleBefore bool                `json:"allVisibleBefore"`
+	AllVisible       bool                `json:"allVisible"`
+	AllVisibleReason string              `json:"allVisibleReason"`
+	Actual           *VacuumPageActual   `json:"actual,omitempty"`
+}
+
+type VacuumPageActual struct {
+	LpCount    int      `json:"lpCount"`
+	FreeAfter  int      `json:"freeAfter"`
+	AllVisible bool     `json:"allVisible"`
+	Matches    bool     `json:"matches"`
+	Mismatches []string `json:"mismatches,omitempty"`
+}
+
+type VacuumPrediction struct {
+	Table           string                 `json:"table"`
+	Horizon         int64                  `json:"horizon"`
+	HeldBy          *HorizonSource         `json:"heldBy,omitempty"`
+	TotalPages      int                    `json:"totalPages"`
+	Truncated       bool                   `json:"truncated"`
+	HasIndexes      bool                   `json:"hasIndexes"`
+	IndexVacuum     bool                   `json:"indexVacuum"`
+	DeadTuples      int                    `json:"deadTuples"`
+	FreedBytes      int                    `json:"freedBytes"`
+	AllVisiblePages int                    `json:"allVisiblePages"`
+	Pages           []VacuumPagePrediction `json:"pages"`
+	Ran             bool                   `json:"ran"`
+	MatchingPages   int                    `json:"matchingPages"`
+	Explanation     string                 `json:"explanation"`
+}
+
+type StoragePage struct {
+	BlockNo    int  `json:"blockNo"`
+	LiveTuples int  `json:"liveTuples"`
+	DeadTuples int  `json:"deadTuples"`
+	FreeSpace  int  `json:"freeSpace"`
+	AllVisible bool `json:"allVisible"`
+	AllFrozen  bool `json:"allFrozen"`
+}
+
+type IndexSnapshot struct {
+	Name        string      `json:"name"`
+	RelFilenode int64       `json:"relfilenode"`
+	Size        int64       `json:"size"`
+	Stats       *IndexStats `json:"stats,omitempty"`
+}
+
+type StorageSnapshot struct {
+	RelFilenode     int64           `json:"relfilenode"`
+	RelPages        int             `json:"relpages"`
+	RelAllVisible   int             `json:"relallvisible"`
+	RelFrozenXID    int64           `json:"relfrozenxid"`
+	FrozenXIDAge    int64           `json:"frozenXidAge"`
+	Pages           int             `json:"pages"`
+	LiveTuples      int             `json:"liveTuples"`
+	DeadTuples      int             `json:"deadTuples"`
+	FreeSpace       int             `json:"freeSpace"`
+	AllVisiblePages int             `json:"allVisiblePages"`
+	AllFrozenPages  int             `json:"allFrozenPages"`
+	PageMap         []StoragePage   `json:"pageMap"`
+	Indexes         []IndexSnapshot `json:"indexes"`
+}
+
+type StoragePageDiff struct {
+	BlockNo int          `json:"blockNo"`
+	Change  string       `json:"change"`
+	Before  *StoragePage `json:"before,omitempty"`
+	After   *StoragePage `json:"after,omitempty"`
+}
+
+type IndexDiff struct {
+	Name               string  `json:"name"`
+	RelFilenodeBefore  int64   `json:"relfilenodeBefore"`
+	RelFilenodeAfter   int64   `json:"relfilenodeAfter"`
+	Rebuilt            bool    `json:"rebuilt"`
+	SizeBefore         int64   `json:"sizeBefore"`
+	SizeAfter          int64   `json:"sizeAfter"`
+	LeafDensityBefore  float64 `json:"leafDensityBefore"`
+	LeafDensityAfter   float64 `json:"leafDensityAfter"`
+	DeletedPagesBefore int64   `json:"deletedPagesBefore"`
+	DeletedPagesAfter  int64   `json:"deletedPagesAfter"`
+}
+
+type StorageDiff struct {
+	Rewritten         bool              `json:"rewritten"`
+	PagesDelta        int               `json:"pagesDelta"`
+	LiveTuplesDelta   int               `json:"liveTuplesDelta"`
+	DeadTuplesDelta   int               `json:"deadTuplesDelta"`
+	FreeSpaceDelta    int               `json:"freeSpaceDelta"`
+	AllVisibleDelta   int               `json:"allVisibleDelta"`
+	AllFrozenDelta    int               `json:"allFrozenDelta"`
+	FrozenXIDAgeDelta int64             `json:"frozenXidAgeDelta"`
+	Pages             []StoragePageDiff `json:"pages"`
+	Indexes           []IndexDiff       `json:"indexes"`
+}
+
+type MaintenanceDemoResult struct {
+	Success     bool            `json:"success"`
+	Error       string          `json:"error,omitempty"`
+	Table       string          `json:"table"`
+	Operation   string          `json:"operation"`
+	Command     string          `json:"command,omitempty"`
+	Index       string          `json:"index,omitempty"`
+	DurationMs  int64           `json:"durationMs"`
+	Before      StorageSnapshot `json:"before"`
+	After       StorageSnapshot `json:"after"`
+	Diff        StorageDiff     `json:"diff"`
+	Explanation string          `json:"explanation"`
+}
+
+type SessionInfo struct {
+	Name          string    `json:"name"`
+	Pid           int       `json:"pid"`
+	Isolation     string    `json:"isolation"`
+	TxStatus      string    `json:"txStatus"`
+	Xid           int64     `json:"xid,omitempty"`
+	Snapshot      *Snapshot `json:"snapshot,omitempty"`
+	Statements    int       `json:"statements"`
+	LastStatement string    `json:"lastStatement,omitempty"`
+	OpenedAt      time.Time `json:"openedAt"`
+	LastUsed      time.Time `json:"lastUsed"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+}
+
+type StatementResult struct {
+	CommandTag string      `json:"commandTag"`
+	Columns    []string    `json:"columns"`
+	Rows       [][]*string `json:"rows"`
+	Truncated  bool        `json:"truncated"`
+}
+
+type SessionExecResult struct {
+	Success    bool              `json:"success"`
+	Error      string            `json:"error,omitempty"`
+	Statement  string            `json:"statement"`
+	Results    []StatementResult `json:"results"`
+	DurationMs int64             `json:"durationMs"`
+	Session    SessionInfo       `json:"session"`
+	StateError string            `json:"stateError,omitempty"` // Session is stale: refreshing it failed
+}
+
+type UpdateExperimentConfig struct {
+	Updates      int      `json:"updates"`
+	Steps        int      `json:"steps"`
+	Distribution string   `json:"distribution"` // uniform, skewed or sequential
+	Columns      []string `json:"columns"`
+	FillFactor   int      `json:"fillFactor"` // 0 keeps the table's own
+	Seed         uint64   `json:"seed"`
+}
+
+type UpdateExperimentStep struct {
+	Step          int     `json:"step"`
+	Updates       int     `json:"updates"`
+	HotUpdates    int     `json:"hotUpdates"`
+	NonHotUpdates int     `json:"nonHotUpdates"`
+	HotRatio      float64 `json:"hotRatio"`
+	Pages         int     `json:"pages"`
+	LinePointers  int     `json:"linePointers"`
+	NormalLPs     int     `json:"normalLps"`
+	Redirects     int     `json:"redirects"`
+	DeadLPs       int     `json:"deadLps"`
+	UnusedLPs     int     `json:"unusedLps"`
+	FreeSpace     int     `json:"freeSpace"`
+	AvgPageFree   int     `json:"avgPageFree"`
+	MinPageFree   int     `json:"minPageFree"`
+}
+
+type UpdateExperimentResult struct {
+	Success     bool                   `json:"success"`
+	Error       string                 `json:"error,omitempty"`
+	Table       string                 `json:"table"`
+	Config      UpdateExperimentConfig `json:"config"`
+	Rows        int                    `json:"rows"`
+	RowsCapped  bool                   `json:"rowsCapped"`
+	Start       UpdateExperimentStep   `json:"start"`
+	Series      []UpdateExperimentStep `json:"series"`
+	HotUpdates  int                    `json:"hotUpdates"`
+	HotRatio    float64                `json:"hotRatio"`
+	DurationMs  int64                  `json:"durationMs"`
+	Explanation string                 `json:"explanation"`
+}
+
+type HotChainMember struct {
+	LP         int    `json:"lp"`
+	Xmin       int64  `json:"xmin"`
+	Xmax       int64  `json:"xmax"`
+	XminStatus string `json:"xminStatus"`
+	Ctid       string `json:"ctid"`
+	HeapOnly   bool   `json:"heapOnly"`
+	HotUpdated bool   `json:"hotUpdated"`
+	Visible    bool   `json:"visible"`
+	Aborted    bool   `json:"aborted"`
+}
+
+// HotChain is one HOT chain on a page, starting at the line pointer that
+// index entries point to
+type HotChain struct {
+	BlockNo     int              `json:"blockNo"`
+	Root        int              `json:"root"`
+	RootFlags   string           `json:"rootFlags"`
+	RedirectTo  int              `json:"redirectTo,omitempty"`
+	Members     []HotChainMember `json:"members"`
+	VisibleLP   int              `json:"visibleLp,omitempty"`
+	Break       string           `json:"break,omitempty"`
+	BreakLP     int              `json:"breakLp,omitempty"`
+	ContinuesAt string           `json:"continuesAt,omitempty"`
+}
+
+type PageHotChains struct {
+	Table    string     `json:"table"`
+	BlockNo  int        `json:"blockNo"`
+	Snapshot *Snapshot  `json:"snapshot"`
+	Chains   []HotChain `json:"chains"`
+	Orphans  []int      `json:"orphans"`
+}
+
+type RowHotChain struct {
+	Table string    `json:"table"`
+	Found bool      `json:"found"`
+	TID   string    `json:"tid,omitempty"`
+	Chain *HotChain `json:"chain,omitempty"`
+}
+
+type ColumnChange struct {
+	Column string  `json:"column"`
+	Old    *string `json:"old"`
+	New    *string `json:"new"`
+}
+
+type RowVersion struct {
+	TID         string             `json:"tid"`
+	Page        int                `json:"page"`
+	Item        int                `json:"item"`
+	Xmin        int64              `json:"xmin"`
+	Xmax        int64              `json:"xmax"`
+	XminStatus  string             `json:"xminStatus"`
+	XmaxStatus  string             `json:"xmaxStatus,omitempty"`
+	Ctid        string             `json:"ctid"`
+	InfoMask    []string           `json:"infoMask"`
+	HeapOnly    bool               `json:"heapOnly"`
+	Visible     bool               `json:"visible"`
+	State       string             `json:"state"` // live, dead, aborted or in progress
+	VacuumState string             `json:"vacuumState"`
+	Removable   bool               `json:"removable"`
+	Previous    string             `json:"previous,omitempty"` // TID whose t_ctid leads here
+	Next        string             `json:"next,omitempty"`
+	Attrs       map[string]*string `json:"attrs"`
+	Changes     []ColumnChange     `json:"changes"`
+}
+
+type RowVersionHistory struct {
+	Table       string       `json:"table"`
+	PKColumn    string       `json:"pkColumn"`
+	PK          string       `json:"pk"`
+	TotalPages  int          `json:"totalPages"`
+	FromBlock   int          `json:"fromBlock"`
+	ToBlock     int          `json:"toBlock"`
+	NextBlock   int          `json:"nextBlock,omitempty"` // where to continue when the scan stopped early
+	Horizon     int64        `json:"horizon"`
+	Versions    []RowVersion `json:"versions"`
+	Explanation string       `json:"explanation"`
+}
+
+// DeadTupleFilter narrows a dead tuple listing. XID bounds are inclusive,
+// compare against the 32-bit on-page values and are ignored when zero.
+type DeadTupleFilter struct {
+	XminFrom  int64    `json:"xminFrom,omitempty"`
+	XminTo    int64    `json:"xminTo,omitempty"`
+	XmaxFrom  int64    `json:"xmaxFrom,omitempty"`
+	XmaxTo    int64    `json:"xmaxTo,omitempty"`
+	LPStates  []string `json:"lpStates"`
+	Removable *bool    `json:"removable,omitempty"`
+	Limit     int      `json:"limit"`
+	FromBlock int      `json:"-"` // cursor position: start here, after AfterItem
+	AfterItem int      `json:"-"`
+}
+
+type DeadTuple struct {
+	TID         string             `json:"tid"`
+	Page        int                `json:"page"`
+	Item        int                `json:"item"`
+	LPFlags     string             `json:"lpFlags"`
+	LPLen       int                `json:"lpLen"`
+	RedirectTo  int                `json:"redirectTo,omitempty"`
+	Xmin        int64              `json:"xmin,omitempty"`
+	Xmax        int64              `json:"xmax,omitempty"`
+	XminStatus  string             `json:"xminStatus,omitempty"`
+	XmaxStatus  string             `json:"xmaxStatus,omitempty"`
+	Ctid        string             `json:"ctid,omitempty"`
+	InfoMask    []string           `json:"infoMask,omitempty"`
+	VacuumState string             `json:"vacuumState,omitempty"`
+	Removable   bool               `json:"removable"`
+	Reason      string             `json:"reason"`
+	Attrs       map[string]*string `json:"attrs,omitempty"`
+}
+
+type DeadTupleList struct {
+	Table       string          `json:"table"`
+	TotalPages  int             `json:"totalPages"`
+	FromBlock   int             `json:"fromBlock"`
+	ToBlock     int             `json:"toBlock"`
+	Horizon     int64           `json:"horizon"`
+	Filter      DeadTupleFilter `json:"filter"`
+	Tuples      []DeadTuple     `json:"tuples"`
+	NextCursor  string          `json:"nextCursor,omitempty"` // TID to pass as ?cursor= for the next page
+	Explanation string          `json:"explanation"`
+}
+
+// PagePruneState is a heap page as the prune-on-read demo sees it
+type PagePruneState struct {
+	Normal   int        `json:"normal"`
+	Redirect int        `json:"redirect"`
+	Dead     int        `json:"dead"`
+	Unused   int        `json:"unused"`
+	Free     int        `json:"free"`
+	PruneXid int64      `json:"pruneXid"`
+	PageFull bool       `json:"pageFull"`
+	Chains   []HotChain `json:"chains"`
+}
+
+type DemoPruneResult struct {
+	Success     bool                `json:"success"`
+	Error       string              `json:"error,omitempty"`
+	Table       string              `json:"table"`
+	PK          string              `json:"pk"`
+	Column      string              `json:"column"`
+	BlockNo     int                 `json:"blockNo"`
+	Updates     int                 `json:"updates"`
+	Xid         int64               `json:"xid"`
+	TupleSize   int                 `json:"tupleSize"`
+	Query       string              `json:"query"`
+	FillFactor  int                 `json:"fillFactor"`
+	Threshold   int                 `json:"threshold"`
+	FreeSpace   int                 `json:"freeSpace"` // PageGetHeapFreeSpace before the read, compared with Threshold
+	Horizon     int64               `json:"horizon"`
+	HeldBy      *HorizonSource      `json:"heldBy,omitempty"`
+	Trigger     string              `json:"trigger"` // page_full, low_free or none
+	Before      PagePruneState      `json:"before"`
+	After       PagePruneState      `json:"after"`
+	Predicted   []LinePointerChange `json:"predicted"`
+	Changes     []LinePointerChange `json:"changes"`
+	Pruned      bool                `json:"pruned"`
+	Explanation string              `json:"explanation"`
+}
+
+type SubXact struct {
+	Savepoint string `json:"savepoint"`
+	Xid       int64  `json:"xid"`
+	Parent    int64  `json:"parent"`
+	Status    string `json:"status"`
+}
+
+type SavepointVersion struct {
+	TID            string   `json:"tid"`
+	Xmin           int64    `json:"xmin"`
+	Xmax           int64    `json:"xmax"`
+	XminOwner      string   `json:"xminOwner,omitempty"` // "top-level" or the savepoint that wrote it
+	XmaxOwner      string   `json:"xmaxOwner,omitempty"`
+	XminStatus     string   `json:"xminStatus"`
+	XmaxStatus     string   `json:"xmaxStatus,omitempty"`
+	Ctid           string   `json:"ctid"`
+	InfoMask       []string `json:"infoMask"`
+	ComboCid       bool     `json:"comboCid"`
+	Cid            int      `json:"cid"` // t_cid: cmin, cmax or a combo CID
+	Value          *string  `json:"value"`
+	VisibleInside  bool     `json:"visibleInside"`
+	VisibleOutside bool     `json:"visibleOutside"`
+}
+
+type SavepointStage struct {
+	Label       string             `json:"label"`
+	SQL         string             `json:"sql"`
+	InsideTID   string             `json:"insideTid,omitempty"` // the version the session's next statement sees
+	Versions    []SavepointVersion `json:"versions"`
+	PageChanged bool               `json:"pageChanged"`
+	Explanation string             `json:"explanation"`
+}
+
+type SavepointScenarioResult struct {
+	Success     bool             `json:"success"`
+	Error       string           `json:"error,omitempty"`
+	Table       string           `json:"table"`
+	PK          string           `json:"pk"`
+	Column      string           `json:"column"`
+	Session     string           `json:"session,omitempty"` // still open when the scenario ends with the transaction held
+	Finish      string           `json:"finish"`
+	Restored    bool             `json:"restored"`
+	TopXid      int64            `json:"topXid"`
+	SubXacts    []SubXact        `json:"subXacts"`
+	Stages      []SavepointStage `json:"stages"`
+	Explanation string           `json:"explanation"`
 }
@@ -0,0 +1,409 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+	vacuumPredictMaxPages = 200
+
+	// pages with LP_DEAD items below this fraction of the table make VACUUM
+	// skip index vacuuming (BYPASS_THRESHOLD_PAGES in vacuumlazy.c)
+	vacuumBypassThreshold = 0.02
+
+	heapHotUpdated = 0x4000 // t_infomask2
+	heapOnlyTuple  = 0x8000 // t_infomask2
+
+	pdAllVisible = 0x0004
+)
+
+// PredictVacuum works out what VACUUM would do to each heap page with the
+// current cleanup horizon, without running it.
+func (i *Inspector) PredictVacuum(ctx context.Context, table string) (*VacuumPrediction, error) {
+	c, err := i.newXactCache(ctx)
+	if err != nil {
+		return nil, err
+	}
+	h, err := i.cleanupHorizon(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks int
+	if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("table-block-count").Query(), table).Scan(&blocks); err != nil {
+		return nil, fmt.Errorf("block count %s: %w", table, err)
+	}
+	indexes, err := i.GetTableIndexes(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &VacuumPrediction{
+		Table:      table,
+		Horizon:    h.Horizon,
+		HeldBy:     h.HeldBy,
+		TotalPages: blocks,
+		HasIndexes: len(indexes) > 0,
+		Pages:      []VacuumPagePrediction{},
+	}
+	n := min(blocks, vacuumPredictMaxPages)
+	out.Truncated = n < blocks
+
+	pages := make([]*prunePlan, 0, n)
+	deadPages := 0
+	for blk := range n {
+		p, err := i.planPrune(ctx, c, table, blk, h.Horizon)
+		if err != nil {
+			return nil, err
+		}
+		if p.hasDead() {
+			deadPages++
+		}
+		pages = append(pages, p)
+	}
+
+	// without indexes LP_DEAD items are reclaimed right away; otherwise they
+	// wait for the index pass, which is skipped when there are very few. Only
+	// the first n pages were looked at, so they stand in for the whole table.
+	out.IndexVacuum = out.HasIndexes && deadPages > 0 && float64(deadPages) >= vacuumBypassThreshold*float64(n)
+	for _, p := range pages {
+		pred := p.finish(!out.HasIndexes || out.IndexVacuum)
+		out.DeadTuples += pred.DeadTuples
+		out.FreedBytes += pred.FreedBytes
+		if pred.AllVisible {
+			out.AllVisiblePages++
+		}
+		out.Pages = append(out.Pages, *pred)
+	}
+	out.Explanation = vacuumPredictionExplanation(out)
+	return out, nil
+}
+
+// RunVacuumPrediction predicts, runs VACUUM and then compares every page's
+// line pointers, free space and PD_ALL_VISIBLE with the prediction.
+func (i *Inspector) RunVacuumPrediction(ctx context.Context, table string) (*VacuumPrediction, error) {
+	out, err := i.PredictVacuum(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := i.pool.Exec(ctx, fmt.Sprintf("VACUUM %s", table)); err != nil {
+		return nil, fmt.Errorf("vacuum %s: %w", table, err)
+	}
+	out.Ran = true
+
+	for j := range out.Pages {
+		p := &out.Pages[j]
+		actual, err := i.vacuumActual(ctx, table, p)
+		if err != nil {
+			return nil, err
+		}
+		p.Actual = actual
+		if actual.Matches {
+			out.MatchingPages++
+		}
+	}
+	out.Explanation = vacuumPredictionExplanation(out)
+	return out, nil
+}
+
+// prunePlan is the outcome of heap_page_prune on one page before the
+// LP_DEAD items are dealt with
+type prunePlan struct {
+	pred   *VacuumPagePrediction
+	flags  map[int]int // line pointer state after pruning
+	target map[int]int // redirect targets after pruning
+	before map[int]int
+	order  []int
+}
+
+func (p *prunePlan) hasDead() bool {
+	for _, f := range p.flags {
+		if f == lpDead {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *Inspector) planPrune(ctx context.Context, c *xactCache, table string, blk int, horizon int64) (*prunePlan, error) {
+	page, err := i.heapPageWith(ctx, c, table, blk, c.ref)
+	if err != nil {
+		return nil, err
+	}
+	hdr, err := i.heapPageHeader(ctx, table, blk)
+	if err != nil {
+		return nil, err
+	}
+	states, err := i.vacuumStates(ctx, c, page.Tuples, horizon)
+	if err != nil {
+		return nil, err
+	}
+	state := make(map[int]HorizonTuple, len(states))
+	for _, s := range states {
+		state[s.LP] = s
+	}
+
+	pred := &VacuumPagePrediction{
+		BlockNo:          blk,
+		FreeBefore:       hdr.free,
+		AllVisibleBefore: hdr.flags&pdAllVisible != 0,
+		Changes:          []LinePointerChange{},
+	}
+	p := &prunePlan{pred: pred, flags: map[int]int{}, target: map[int]int{}, before: map[int]int{}}
+	byLP := make(map[int]*HeapTuple, len(page.Tuples))
+	for j := range page.Tuples {
+		t := &page.Tuples[j]
+		byLP[t.LP] = t
+		p.flags[t.LP], p.before[t.LP] = t.LPFlags, t.LPFlags
+		if t.LPFlags == lpRedirect {
+			p.target[t.LP] = t.LPOffset
+		}
+		p.order = append(p.order, t.LP)
+		if s, ok := state[t.LP]; ok && s.State == vacuumRecentlyDead {
+			pred.RecentlyDead++
+		}
+	}
+
+	reason := map[int]string{}
+	remove := func(t *HeapTuple, to int, why string) {
+		p.flags[t.LP] = to
+		reason[t.LP] = why
+		if t.LPFlags == lpNormal {
+			pred.DeadTuples++
+			pred.FreedBytes += maxAlign(t.ItemLen)
+		}
+	}
+
+	// heap_prune_chain: walk every HOT chain from its root and prune the
+	// members up to the last DEAD one
+	visited := map[int]bool{}
+	for _, lp := range p.order {
+		root := byLP[lp]
+		if root.LPFlags != lpRedirect && (root.LPFlags != lpNormal || root.RawInfoMask2&heapOnlyTuple != 0) {
+			continue
+		}
+		var chain []*HeapTuple
+		next := root
+		if root.LPFlags == lpRedirect {
+			next = byLP[root.LPOffset]
+		}
+		for next != nil && next.LPFlags == lpNormal && !visited[next.LP] {
+			if len(chain) > 0 && next.Xmin != chain[len(chain)-1].Xmax {
+				break
+			}
+			visited[next.LP] = true
+			chain = append(chain, next)
+			if next.RawInfoMask2&heapHotUpdated == 0 {
+				break
+			}
+			blkNo, item, ok := parseCtid(next.Ctid)
+			if !ok || blkNo != blk || item == next.LP {
+				break
+			}
+			next = byLP[item]
+		}
+
+		latestDead := -1
+		for k, t := range chain {
+			s := state[t.LP].State
+			if s == vacuumDead {
+				latestDead = k
+			} else if s != vacuumRecentlyDead {
+				break
+			}
+		}
+		if latestDead < 0 {
+			continue
+		}
+
+		for _, t := range chain[:latestDead+1] {
+			if t == root {
+				continue
+			}
+			remove(t, lpUnused, "HOT chain member pruned")
+		}
+		if latestDead == len(chain)-1 {
+			remove(root, lpDead, "whole chain is dead: the root stays as LP_DEAD until indexes stop pointing at it")
+			delete(p.target, root.LP)
+			continue
+		}
+		to := chain[latestDead+1].LP
+		if root.LPFlags == lpNormal {
+			remove(root, lpRedirect, fmt.Sprintf("root pruned, redirected to the first live version at lp %d", to))
+		} else {
+			p.flags[root.LP] = lpRedirect
+			reason[root.LP] = fmt.Sprintf("redirect moved to lp %d", to)
+		}
+		p.target[root.LP] = to
+	}
+
+	// dead heap-only tuples no chain reached have no index entries
+	for _, lp := range p.order {
+		t := byLP[lp]
+		if t.LPFlags == lpNormal && t.RawInfoMask2&heapOnlyTuple != 0 && !visited[lp] && state[lp].State == vacuumDead {
+			remove(t, lpUnused, "orphaned heap-only tuple")
+		}
+	}
+
+	for _, lp := range p.order {
+		if p.flags[lp] != p.before[lp] || p.target[lp] != byLP[lp].LPOffset && p.flags[lp] == lpRedirect {
+			pred.Changes = append(pred.Changes, LinePointerChange{
+				LP: lp, From: lpFlagsStr(p.before[lp]), To: lpFlagsStr(p.flags[lp]),
+				RedirectTo: p.target[lp], Reason: reason[lp],
+			})
+		}
+	}
+
+	pred.AllVisible, pred.AllVisibleReason = allVisibleAfter(c, page.Tuples, state, p.flags, horizon)
+	return p, nil
+}
+
+// finish applies the second heap pass: LP_DEAD items become LP_UNUSED when
+// their index entries are gone, and trailing unused line pointers are cut off.
+func (p *prunePlan) finish(reclaimDead bool) *VacuumPagePrediction {
+	pred := p.pred
+	reclaimed := false
+	if reclaimDead {
+		for j := range pred.Changes {
+			ch := &pred.Changes[j]
+			if ch.To == lpFlagsStr(lpDead) {
+				ch.To = lpFlagsStr(lpUnused)
+				ch.Reason += "; reclaimed after index vacuuming"
+				p.flags[ch.LP] = lpUnused
+				reclaimed = true
+			}
+		}
+		for _, lp := range p.order {
+			if p.before[lp] == lpDead {
+				p.flags[lp] = lpUnused
+				pred.Changes = append(pred.Changes, LinePointerChange{
+					LP: lp, From: lpFlagsStr(lpDead), To: lpFlagsStr(lpUnused),
+					Reason: "existing LP_DEAD reclaimed after index vacuuming",
+				})
+				reclaimed = true
+			}
+		}
+	} else if p.hasDead() {
+		pred.AllVisible = false
+		pred.AllVisibleReason = "LP_DEAD items stay behind because index vacuuming is bypassed"
+	}
+
+	if reclaimed {
+		for j := len(p.order) - 1; j >= 0 && p.flags[p.order[j]] == lpUnused; j-- {
+			pred.TruncatedLPs++
+		}
+	}
+	pred.FreeAfter = pred.FreeBefore + pred.FreedBytes + pred.TruncatedLPs*lpSize
+	return pred
+}
+
+// allVisibleAfter mirrors the all_visible tracking in lazy_scan_prune: every
+// remaining tuple must be LIVE with an xmin older than the horizon
+func allVisibleAfter(c *xactCache, tuples []HeapTuple, state map[int]HorizonTuple, flags map[int]int, horizon int64) (bool, string) {
+	for _, t := range tuples {
+		if flags[t.LP] != lpNormal {
+			continue
+		}
+		s := state[t.LP]
+		if s.State != vacuumLive {
+			return false, fmt.Sprintf("lp %d is %s: %s", t.LP, s.State, s.Reason)
+		}
+		if t.RawInfoMask&heapXminFrozen == heapXminFrozen {
+			continue
+		}
+		if xmin := c.ref.fullXid(t.Xmin); xmin >= horizon {
+			return false, fmt.Sprintf("lp %d was inserted by %d, not yet older than the horizon %d", t.LP, xmin, horizon)
+		}
+	}
+	return true, "every remaining tuple is visible to all transactions"
+}
+
+func (i *Inspector) vacuumActual(ctx context.Context, table string, p *VacuumPagePrediction) (*VacuumPageActual, error) {
+	page, err := i.GetHeapPageDetail(ctx, table, p.BlockNo)
+	if err != nil {
+		return nil, err
+	}
+	hdr, err := i.heapPageHeader(ctx, table, p.BlockNo)
+	if err != nil {
+		return nil, err
+	}
+	out := &VacuumPageActual{FreeAfter: hdr.free, AllVisible: hdr.flags&pdAllVisible != 0, LpCount: len(page.Tuples)}
+
+	predicted := map[int]LinePointerChange{}
+	for _, ch := range p.Changes {
+		predicted[ch.LP] = ch
+	}
+	for _, t := range page.Tuples {
+		ch, ok := predicted[t.LP]
+		if !ok {
+			continue
+		}
+		delete(predicted, t.LP)
+		if ch.To != t.LPFlagsStr {
+			out.Mismatches = append(out.Mismatches, fmt.Sprintf("lp %d: predicted %s, got %s", t.LP, ch.To, t.LPFlagsStr))
+		} else if t.LPFlags == lpRedirect && ch.RedirectTo != t.LPOffset {
+			out.Mismatches = append(out.Mismatches, fmt.Sprintf("lp %d: predicted redirect to %d, got %d", t.LP, ch.RedirectTo, t.LPOffset))
+		}
+	}
+	// line pointers missing after VACUUM were truncated off the array
+	for lp, ch := range predicted {
+		if ch.To != lpFlagsStr(lpUnused) {
+			out.Mismatches = append(out.Mismatches, fmt.Sprintf("lp %d: predicted %s, but it was truncated", lp, ch.To))
+		}
+	}
+	if out.AllVisible != p.AllVisible {
+		out.Mismatches = append(out.Mismatches, fmt.Sprintf("PD_ALL_VISIBLE: predicted %t, got %t", p.AllVisible, out.AllVisible))
+	}
+	if out.FreeAfter != p.FreeAfter {
+		out.Mismatches = append(out.Mismatches, fmt.Sprintf("free space: predicted %d bytes, got %d", p.FreeAfter, out.FreeAfter))
+	}
+	out.Matches = len(out.Mismatches) == 0
+	return out, nil
+}
+
+type pageHeaderInfo struct {
+	flags, free int
+	pruneXid    int64
+}
+
+func (i *Inspector) heapPageHeader(ctx context.Context, table string, blk int) (*pageHeaderInfo, error) {
+	var lower, upper int
+	var h pageHeaderInfo
+	err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("heap-page-header").Query(), table, blk).Scan(&h.flags, &lower, &upper, &h.pruneXid)
+	if err != nil {
+		return nil, fmt.Errorf("page header %s blk %d: %w", table, blk, err)
+	}
+	h.free = upper - lower
+	return &h, nil
+}
+
+func parseCtid(ctid string) (blk, item int, ok bool) {
+	n, _ := fmt.Sscanf(ctid, "(%d,%d)", &blk, &item)
+	return blk, item, n == 2
+}
+
+func vacuumPredictionExplanation(v *VacuumPrediction) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🧹 With the cleanup horizon at XID %d, VACUUM would remove %d dead tuples and free %d bytes across %d pages; %d pages would end up all-visible.",
+		v.Horizon, v.DeadTuples, v.FreedBytes, len(v.Pages), v.AllVisiblePages)
+	switch {
+	case !v.HasIndexes:
+		b.WriteString(" The table has no indexes, so dead line pointers become LP_UNUSED immediately.")
+	case v.IndexVacuum:
+		b.WriteString(" Dead line pointers are first marked LP_DEAD, then set LP_UNUSED once the index entries pointing at them are removed.")
+	default:
+		b.WriteString(" Fewer than 2% of pages have LP_DEAD items, so VACUUM skips index vacuuming and leaves them as LP_DEAD.")
+	}
+	if v.Truncated {
+		fmt.Fprintf(&b, " Only the first %d of %d pages were analyzed.", len(v.Pages), v.TotalPages)
+	}
+	if v.Ran {
+		fmt.Fprintf(&b, " After running VACUUM, %d of %d pages matched the prediction.", v.MatchingPages, len(v.Pages))
+		if v.MatchingPages < len(v.Pages) {
+			b.WriteString(" Differences usually mean the horizon moved, VACUUM skipped a page already marked all-visible or could not get a cleanup lock.")
+		}
+	}
+	return b.String()
+}
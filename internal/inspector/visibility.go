@@ -0,0 +1,463 @@
+package inspector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// t_infomask bits used by the visibility rules (htup_details.h)
+const (
+	heapXmaxKeyshrLock  = 0x0010
+	heapXmaxExclLock    = 0x0040
+	heapXmaxLockOnly    = 0x0080
+	heapXminCommitted   = 0x0100
+	heapXminInvalid     = 0x0200
+	heapXminFrozen      = heapXminCommitted | heapXminInvalid
+	heapXmaxCommitted   = 0x0400
+	heapXmaxInvalid     = 0x0800
+	heapXmaxIsMulti     = 0x1000
+	heapLockMask        = heapXmaxExclLock | heapXmaxKeyshrLock
+	frozenTransactionID = 2
+
+	// XIDs compare modulo 2^32, so at most 2^31 can be in use at once
+	xidWraparoundLimit = 1 << 31
+)
+
+const (
+	xactCommitted  = "committed"
+	xactAborted    = "aborted"
+	xactInProgress = "in progress"
+	xactUnknown    = "unknown"
+)
+
+// Snapshot is an MVCC snapshot in pg_current_snapshot() form. All XIDs are
+// 64-bit (epoch-qualified). Current is the XID of the transaction the
+// snapshot belongs to, if it has one, and Subxids those of its open
+// subtransactions, which pg_current_snapshot() leaves out.
+type Snapshot struct {
+	Xmin    int64   `json:"xmin"`
+	Xmax    int64   `json:"xmax"`
+	Xip     []int64 `json:"xip"`
+	Current int64   `json:"current,omitempty"`
+	Subxids []int64 `json:"subxids,omitempty"`
+}
+
+// ParseSnapshot parses "xmin:xmax:xip1,xip2,..."
+func ParseSnapshot(s string) (*Snapshot, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("snapshot must look like xmin:xmax:xip1,xip2")
+	}
+	var snap Snapshot
+	var err error
+	if snap.Xmin, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid snapshot xmin %q", parts[0])
+	}
+	if snap.Xmax, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid snapshot xmax %q", parts[1])
+	}
+	if snap.Xmin > snap.Xmax {
+		return nil, fmt.Errorf("snapshot xmin %d is after xmax %d", snap.Xmin, snap.Xmax)
+	}
+	for _, x := range strings.Split(parts[2], ",") {
+		if x = strings.TrimSpace(x); x == "" {
+			continue
+		}
+		xid, err := strconv.ParseInt(x, 10, 64)
+		if err != nil || xid < snap.Xmin || xid >= snap.Xmax {
+			return nil, fmt.Errorf("invalid in-progress xid %q", x)
+		}
+		snap.Xip = append(snap.Xip, xid)
+	}
+	return &snap, nil
+}
+
+func (s *Snapshot) String() string {
+	xip := make([]string, len(s.Xip))
+	for j, x := range s.Xip {
+		xip[j] = strconv.FormatInt(x, 10)
+	}
+	return fmt.Sprintf("%d:%d:%s", s.Xmin, s.Xmax, strings.Join(xip, ","))
+}
+
+// fullXid widens a 32-bit on-page XID using the snapshot's epoch; on-disk
+// XIDs are always within 2^31 of a current snapshot.
+func (s *Snapshot) fullXid(xid int64) int64 {
+	return s.Xmax + int64(int32(uint32(xid)-uint32(s.Xmax)))
+}
+
+// inProgress mirrors XidInMVCCSnapshot
+func (s *Snapshot) inProgress(xid int64) bool {
+	switch {
+	case xid < s.Xmin:
+		return false
+	case xid >= s.Xmax:
+		return true
+	}
+	return slices.Contains(s.Xip, xid)
+}
+
+// isCurrent mirrors TransactionIdIsCurrentTransactionId, as far as the
+// snapshot knows the transaction's subxids
+func (s *Snapshot) isCurrent(xid int64) bool {
+	return s.Current != 0 && xid == s.Current || slices.Contains(s.Subxids, xid)
+}
+
+// xactCache remembers transaction and multixact lookups for one request.
+// On-page XIDs are widened against ref, a snapshot taken for the request.
+type xactCache struct {
+	ref           *Snapshot
+	trackCommitTs bool
+	status        map[int64]string
+	commitTs      map[int64]time.Time
+	members       map[int64][]MultiXactMember
+}
+
+func (i *Inspector) newXactCache(ctx context.Context) (*xactCache, error) {
+	ref, err := i.currentSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c := &xactCache{
+		ref:      ref,
+		status:   make(map[int64]string),
+		commitTs: make(map[int64]time.Time),
+		members:  make(map[int64][]MultiXactMember),
+	}
+	var track string
+	if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("track-commit-timestamp").Query()).Scan(&track); err == nil {
+		c.trackCommitTs = track == "on"
+	}
+	return c, nil
+}
+
+func (i *Inspector) currentSnapshot(ctx context.Context) (*Snapshot, error) {
+	var s string
+	if err := i.pool.QueryRow(ctx, i.qs.MustHaveQuery("current-snapshot").Query()).Scan(&s); err != nil {
+		return nil, fmt.Errorf("current snapshot: %w", err)
+	}
+	return ParseSnapshot(s)
+}
+
+// loadXactStatus fetches the commit status (and commit time, when tracked)
+// of the given full XIDs in one round trip each.
+func (i *Inspector) loadXactStatus(ctx context.Context, c *xactCache, xids []int64) error {
+	var want []string
+	for _, x := range xids {
+		if _, ok := c.status[x]; ok {
+			continue
+		}
+		if x <= frozenTransactionID {
+			c.status[x] = xactCommitted
+			continue
+		}
+		// assigned after the reference snapshot was taken
+		if x >= c.ref.Xmax {
+			c.status[x] = xactInProgress
+			continue
+		}
+		c.status[x] = xactUnknown
+		want = append(want, strconv.FormatInt(x, 10))
+	}
+	if len(want) == 0 {
+		return nil
+	}
+
+	rows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("xact-status").Query(), want)
+	if err != nil {
+		return fmt.Errorf("xact status: %w", err)
+	}
+	defer rows.Close()
+
+	var committed []string
+	for rows.Next() {
+		var xid int64
+		var status *string
+		if err := rows.Scan(&xid, &status); err != nil {
+			return fmt.Errorf("scan xact status: %w", err)
+		}
+		// NULL means the commit log was truncated: the transaction is older
+		// than every running one and its tuples are frozen or hinted
+		if status == nil {
+			c.status[xid] = xactCommitted
+			continue
+		}
+		c.status[xid] = *status
+		if *status == xactCommitted {
+			committed = append(committed, strconv.FormatInt(xid, 10))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	if !c.trackCommitTs || len(committed) == 0 {
+		return nil
+	}
+	tsRows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("commit-timestamps").Query(), committed)
+	if err != nil {
+		return fmt.Errorf("commit timestamps: %w", err)
+	}
+	defer tsRows.Close()
+	for tsRows.Next() {
+		var xid int64
+		var ts *time.Time
+		if err := tsRows.Scan(&xid, &ts); err != nil {
+			return fmt.Errorf("scan commit timestamp: %w", err)
+		}
+		// committed before track_commit_timestamp was enabled
+		if ts != nil {
+			c.commitTs[xid] = *ts
+		}
+	}
+	return tsRows.Err()
+}
+
+func (i *Inspector) multiXactMembers(ctx context.Context, c *xactCache, multi int64) ([]MultiXactMember, error) {
+	if m, ok := c.members[multi]; ok {
+		return m, nil
+	}
+	rows, err := i.pool.Query(ctx, i.qs.MustHaveQuery("multixact-members").Query(), strconv.FormatInt(multi, 10))
+	if err != nil {
+		return nil, fmt.Errorf("multixact %d: %w", multi, err)
+	}
+	defer rows.Close()
+
+	var out []MultiXactMember
+	for rows.Next() {
+		var m MultiXactMember
+		if err := rows.Scan(&m.Xid, &m.Mode); err != nil {
+			return nil, fmt.Errorf("scan multixact member: %w", err)
+		}
+		m.Xid = c.ref.fullXid(m.Xid)
+		m.Lock = multiXactLockMode(m.Mode)
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	xids := make([]int64, len(out))
+	for j, m := range out {
+		xids[j] = m.Xid
+	}
+	if err := i.loadXactStatus(ctx, c, xids); err != nil {
+		return nil, err
+	}
+	for j := range out {
+		out[j].Status = c.status[out[j].Xid]
+	}
+	c.members[multi] = out
+	return out, nil
+}
+
+// multiXactLockMode names a pg_get_multixact_members mode
+func multiXactLockMode(mode string) string {
+	switch mode {
+	case "keysh":
+		return "FOR KEY SHARE"
+	case "sh":
+		return "FOR SHARE"
+	case "fornokeyupd":
+		return "FOR NO KEY UPDATE"
+	case "forupd":
+		return "FOR UPDATE"
+	case "nokeyupd":
+		return "NO KEY UPDATE (updater)"
+	case "upd":
+		return "UPDATE (updater)"
+	}
+	return mode
+}
+
+// xmaxLockedOnly mirrors HEAP_XMAX_IS_LOCKED_ONLY
+func xmaxLockedOnly(mask int) bool {
+	return mask&heapXmaxLockOnly != 0 ||
+		mask&(heapXmaxIsMulti|heapLockMask) == heapXmaxExclLock
+}
+
+// evaluateVisibility fills in the transaction status of every normal tuple
+// on the page and its visibility verdict against snap.
+func (i *Inspector) evaluateVisibility(ctx context.Context, c *xactCache, snap *Snapshot, tuples []HeapTuple) error {
+	var xids []int64
+	for _, t := range tuples {
+		if t.LPFlags != lpNormal {
+			continue
+		}
+		xids = append(xids, c.ref.fullXid(t.Xmin))
+		if t.Xmax != 0 && t.RawInfoMask&heapXmaxIsMulti == 0 {
+			xids = append(xids, c.ref.fullXid(t.Xmax))
+		}
+	}
+	if err := i.loadXactStatus(ctx, c, xids); err != nil {
+		return err
+	}
+
+	for idx := range tuples {
+		t := &tuples[idx]
+		if t.LPFlags != lpNormal {
+			continue
+		}
+		if t.Xmax != 0 && t.RawInfoMask&heapXmaxIsMulti != 0 && t.RawInfoMask&heapXmaxInvalid == 0 {
+			// members of a multixact older than the oldest one kept are gone
+			t.XmaxMembers, _ = i.multiXactMembers(ctx, c, t.Xmax)
+		}
+		v, err := i.satisfiesMVCC(ctx, c, snap, t)
+		if err != nil {
+			return err
+		}
+		t.Visibility = v
+		t.IsLive = v.Visible
+		annotateXacts(c, t)
+	}
+	return nil
+}
+
+// annotateXacts records what pg_xact says about xmin and xmax, regardless
+// of any snapshot
+func annotateXacts(c *xactCache, t *HeapTuple) {
+	xmin := c.ref.fullXid(t.Xmin)
+	if t.RawInfoMask&heapXminFrozen == heapXminFrozen {
+		t.XminStatus = "frozen"
+	} else {
+		t.XminStatus = c.status[xmin]
+	}
+	if ts, ok := c.commitTs[xmin]; ok {
+		t.XminCommitTs = &ts
+	}
+
+	if t.Xmax == 0 {
+		return
+	}
+	xmax := c.ref.fullXid(t.Xmax)
+	if t.RawInfoMask&heapXmaxIsMulti != 0 {
+		if t.Visibility == nil || t.Visibility.UpdateXid == 0 {
+			t.XmaxStatus = "multixact"
+			return
+		}
+		xmax = t.Visibility.UpdateXid
+	}
+	t.XmaxStatus = c.status[xmax]
+	if ts, ok := c.commitTs[xmax]; ok {
+		t.XmaxCommitTs = &ts
+	}
+}
+
+// satisfiesMVCC follows HeapTupleSatisfiesMVCC, ignoring command ids, and
+// records each decision taken.
+func (i *Inspector) satisfiesMVCC(ctx context.Context, c *xactCache, snap *Snapshot, t *HeapTuple) (*TupleVisibility, error) {
+	mask := t.RawInfoMask
+	v := &TupleVisibility{}
+	step := func(format string, args ...any) {
+		v.Steps = append(v.Steps, fmt.Sprintf(format, args...))
+	}
+	verdict := func(visible bool, format string, args ...any) (*TupleVisibility, error) {
+		step(format, args...)
+		v.Visible = visible
+		return v, nil
+	}
+	// a subtransaction rolled back to its savepoint is no longer part of
+	// the transaction
+	current := func(xid int64) bool {
+		return snap.isCurrent(xid) && (xid == snap.Current || c.status[xid] != xactAborted)
+	}
+
+	xmin := c.ref.fullXid(t.Xmin)
+	switch {
+	case mask&heapXminFrozen == heapXminFrozen || t.Xmin == frozenTransactionID:
+		v.XminStatus = "frozen"
+		step("xmin %d is frozen: visible to every snapshot", t.Xmin)
+	case mask&heapXminInvalid != 0:
+		v.XminStatus = xactAborted
+		return verdict(false, "HEAP_XMIN_INVALID: the inserting transaction %d aborted", t.Xmin)
+	case mask&heapXminCommitted != 0:
+		v.XminStatus = xactCommitted
+		if snap.inProgress(xmin) {
+			return verdict(false, "xmin %d committed (hint bit) but was still running when the snapshot was taken", xmin)
+		}
+		step("xmin %d committed (HEAP_XMIN_COMMITTED hint) before the snapshot", xmin)
+	case current(xmin):
+		v.XminStatus = xactInProgress
+		if t.Xmax == 0 || mask&heapXmaxInvalid != 0 || xmaxLockedOnly(mask) {
+			return verdict(true, "xmin %d is the snapshot's own transaction: its inserts are visible to it", xmin)
+		}
+		if current(c.ref.fullXid(t.Xmax)) {
+			v.XmaxStatus = xactInProgress
+			return verdict(false, "inserted and then deleted by the snapshot's own transaction %d", xmin)
+		}
+		return verdict(true, "xmin %d is the snapshot's own transaction: its inserts are visible to it", xmin)
+	default:
+		v.XminStatus = c.status[xmin]
+		if snap.inProgress(xmin) {
+			return verdict(false, "xmin %d is in progress for this snapshot: the insert is not visible yet", xmin)
+		}
+		switch v.XminStatus {
+		case xactCommitted:
+			step("no hint bits; pg_xact says xmin %d committed before the snapshot", xmin)
+		case xactAborted:
+			return verdict(false, "no hint bits; pg_xact says xmin %d aborted: the tuple never existed", xmin)
+		case xactInProgress:
+			return verdict(false, "xmin %d is still running (it is missing from the snapshot's xip list)", xmin)
+		default:
+			return verdict(false, "status of xmin %d could not be determined", xmin)
+		}
+	}
+
+	if mask&heapXmaxInvalid != 0 || t.Xmax == 0 {
+		return verdict(true, "xmax is invalid: the tuple was never deleted or locked")
+	}
+	if xmaxLockedOnly(mask) {
+		v.XmaxStatus = "locked"
+		return verdict(true, "xmax %d only locked the row (no update or delete): it doesn't affect visibility", t.Xmax)
+	}
+
+	xmax := c.ref.fullXid(t.Xmax)
+	if mask&heapXmaxIsMulti != 0 {
+		members, err := i.multiXactMembers(ctx, c, t.Xmax)
+		if err != nil {
+			return verdict(true, "xmax is multixact %d whose members are no longer available; treating it as a lock", t.Xmax)
+		}
+		xmax = 0
+		for _, m := range members {
+			if m.Mode == "upd" || m.Mode == "nokeyupd" {
+				xmax = m.Xid
+			}
+		}
+		if xmax == 0 {
+			v.XmaxStatus = "locked"
+			return verdict(true, "xmax is multixact %d holding only row locks", t.Xmax)
+		}
+		if err := i.loadXactStatus(ctx, c, []int64{xmax}); err != nil {
+			return nil, err
+		}
+		v.UpdateXid = xmax
+		step("xmax is multixact %d; its updating member is %d", t.Xmax, xmax)
+	}
+
+	if current(xmax) {
+		v.XmaxStatus = xactInProgress
+		return verdict(false, "xmax %d is the snapshot's own transaction: it already deleted or updated the tuple", xmax)
+	}
+	if snap.inProgress(xmax) {
+		v.XmaxStatus = xactInProgress
+		return verdict(true, "deleting transaction %d is in progress for this snapshot: the old version is still visible", xmax)
+	}
+	if mask&heapXmaxCommitted != 0 && mask&heapXmaxIsMulti == 0 {
+		v.XmaxStatus = xactCommitted
+		return verdict(false, "xmax %d committed (HEAP_XMAX_COMMITTED hint) before the snapshot: the tuple is deleted", xmax)
+	}
+	v.XmaxStatus = c.status[xmax]
+	switch v.XmaxStatus {
+	case xactCommitted:
+		return verdict(false, "pg_xact says xmax %d committed before the snapshot: the tuple is deleted", xmax)
+	case xactAborted:
+		return verdict(true, "pg_xact says xmax %d aborted: the delete/update never happened", xmax)
+	}
+	return verdict(true, "xmax %d did not commit (status: %s): the tuple is still visible", xmax, v.XmaxStatus)
+}
@@ -0,0 +1,125 @@
+package inspector
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestParseSnapshot(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    Snapshot
+		wantErr bool
+	}{
+		{name: "no xip", in: "100:100:", want: Snapshot{Xmin: 100, Xmax: 100}},
+		{name: "xip list", in: "100:110:101,105", want: Snapshot{Xmin: 100, Xmax: 110, Xip: []int64{101, 105}}},
+		{name: "spaces and empty entries", in: " 100:110: 101, ,105 ", want: Snapshot{Xmin: 100, Xmax: 110, Xip: []int64{101, 105}}},
+		{name: "too few parts", in: "100:110", wantErr: true},
+		{name: "too many parts", in: "100:110:101:102", wantErr: true},
+		{name: "bad xmin", in: "x:110:", wantErr: true},
+		{name: "bad xmax", in: "100:x:", wantErr: true},
+		{name: "xmin after xmax", in: "110:100:", wantErr: true},
+		{name: "xip below xmin", in: "100:110:99", wantErr: true},
+		{name: "xip at xmax", in: "100:110:110", wantErr: true},
+		{name: "bad xip", in: "100:110:10x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSnapshot(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseSnapshot(%q) = %v, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSnapshot(%q): %v", tt.in, err)
+			}
+			if got.Xmin != tt.want.Xmin || got.Xmax != tt.want.Xmax || !slices.Equal(got.Xip, tt.want.Xip) {
+				t.Errorf("ParseSnapshot(%q) = %+v, want %+v", tt.in, *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSatisfiesMVCC(t *testing.T) {
+	ref := &Snapshot{Xmin: 100, Xmax: 110, Xip: []int64{105}}
+	newCache := func() *xactCache {
+		return &xactCache{
+			ref: ref,
+			status: map[int64]string{
+				90:  xactCommitted,
+				91:  xactAborted,
+				95:  xactCommitted,
+				96:  xactAborted,
+				105: xactInProgress,
+				106: xactInProgress,
+				107: xactInProgress,
+				108: xactAborted,
+			},
+			members: map[int64][]MultiXactMember{
+				7: {{Xid: 105, Mode: "sh"}, {Xid: 95, Mode: "upd"}},
+				8: {{Xid: 105, Mode: "sh"}, {Xid: 107, Mode: "keysh"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		xmin, xmax int64
+		mask       int
+		current    int64
+		subxids    []int64
+		visible    bool
+		xminStatus string
+		xmaxStatus string
+		updateXid  int64
+	}{
+		{name: "frozen xmin", xmin: 90, mask: heapXminFrozen | heapXmaxInvalid, visible: true, xminStatus: "frozen"},
+		{name: "frozen xid", xmin: frozenTransactionID, mask: heapXmaxInvalid, visible: true, xminStatus: "frozen"},
+		{name: "xmin invalid hint", xmin: 90, mask: heapXminInvalid, xminStatus: xactAborted},
+		{name: "xmin committed hint", xmin: 90, mask: heapXminCommitted | heapXmaxInvalid, visible: true, xminStatus: xactCommitted},
+		{name: "xmin committed hint but in snapshot xip", xmin: 105, mask: heapXminCommitted, xminStatus: xactCommitted},
+		{name: "xmin committed in pg_xact", xmin: 90, visible: true, xminStatus: xactCommitted},
+		{name: "xmin aborted in pg_xact", xmin: 91, xminStatus: xactAborted},
+		{name: "xmin in snapshot xip", xmin: 105, xminStatus: xactInProgress},
+		{name: "xmin after snapshot xmax", xmin: 112},
+		{name: "xmin running but missing from xip", xmin: 107, xminStatus: xactInProgress},
+		{name: "own insert", xmin: 107, current: 107, visible: true, xminStatus: xactInProgress},
+		{name: "own insert, locked by self", xmin: 107, xmax: 107, mask: heapXmaxLockOnly, current: 107, visible: true, xminStatus: xactInProgress},
+		{name: "own insert and delete", xmin: 107, xmax: 107, current: 107, xminStatus: xactInProgress, xmaxStatus: xactInProgress},
+		{name: "xmax invalid hint", xmin: 90, xmax: 95, mask: heapXmaxInvalid, visible: true, xminStatus: xactCommitted},
+		{name: "xmax lock only", xmin: 90, xmax: 95, mask: heapXmaxLockOnly, visible: true, xminStatus: xactCommitted, xmaxStatus: "locked"},
+		{name: "xmax exclusive lock", xmin: 90, xmax: 95, mask: heapXmaxExclLock, visible: true, xminStatus: xactCommitted, xmaxStatus: "locked"},
+		{name: "xmax committed hint", xmin: 90, xmax: 95, mask: heapXmaxCommitted, xminStatus: xactCommitted, xmaxStatus: xactCommitted},
+		{name: "xmax committed in pg_xact", xmin: 90, xmax: 95, xminStatus: xactCommitted, xmaxStatus: xactCommitted},
+		{name: "xmax aborted in pg_xact", xmin: 90, xmax: 96, visible: true, xminStatus: xactCommitted, xmaxStatus: xactAborted},
+		{name: "xmax in snapshot xip", xmin: 90, xmax: 105, visible: true, xminStatus: xactCommitted, xmaxStatus: xactInProgress},
+		{name: "own subtransaction's insert", xmin: 106, current: 107, subxids: []int64{106, 108}, visible: true, xminStatus: xactInProgress},
+		{name: "insert rolled back to savepoint", xmin: 108, current: 107, subxids: []int64{106, 108}, xminStatus: xactAborted},
+		{name: "own subtransaction's delete", xmin: 90, xmax: 106, current: 107, subxids: []int64{106}, xminStatus: xactCommitted, xmaxStatus: xactInProgress},
+		{name: "xmax is own transaction", xmin: 90, xmax: 107, current: 107, xminStatus: xactCommitted, xmaxStatus: xactInProgress},
+		{name: "multixact with committed updater", xmin: 90, xmax: 7, mask: heapXmaxIsMulti, xminStatus: xactCommitted, xmaxStatus: xactCommitted, updateXid: 95},
+		{name: "multixact of lockers only", xmin: 90, xmax: 8, mask: heapXmaxIsMulti, visible: true, xminStatus: xactCommitted, xmaxStatus: "locked"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap := *ref
+			snap.Current, snap.Subxids = tt.current, tt.subxids
+			tup := &HeapTuple{LPFlags: lpNormal, Xmin: tt.xmin, Xmax: tt.xmax, RawInfoMask: tt.mask}
+			v, err := new(Inspector).satisfiesMVCC(context.Background(), newCache(), &snap, tup)
+			if err != nil {
+				t.Fatalf("satisfiesMVCC: %v", err)
+			}
+			if v.Visible != tt.visible || v.XminStatus != tt.xminStatus || v.XmaxStatus != tt.xmaxStatus || v.UpdateXid != tt.updateXid {
+				t.Errorf("got visible=%v xmin=%q xmax=%q update=%d, want visible=%v xmin=%q xmax=%q update=%d (steps: %q)",
+					v.Visible, v.XminStatus, v.XmaxStatus, v.UpdateXid, tt.visible, tt.xminStatus, tt.xmaxStatus, tt.updateXid, v.Steps)
+			}
+			if len(v.Steps) == 0 {
+				t.Error("no steps recorded")
+			}
+		})
+	}
+}
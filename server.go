@@ -82,5 +82,8 @@ func Run(cfg Config) error {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer shutdownCancel()
 
-	return server.Shutdown(shutdownCtx)
+	err = server.Shutdown(shutdownCtx)
+	// pinned session connections would keep the pool from closing
+	insp.CloseAllSessions()
+	return err
 }
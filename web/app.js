@@ -337,10 +337,10 @@ function renderBloatTab(stats, bloat, densityMap) {
     const totalDeadItems = leafPages.reduce((sum, p) => sum + p.deadItems, 0);
     const totalLiveItems = leafPages.reduce((sum, p) => sum + p.liveItems, 0);
 
-    // Calculate what a perfectly packed index would look like
-    const avgItemsPerFullPage = 400; // Rough estimate for integer keys
-    const optimalPages = Math.ceil(totalLiveItems / avgItemsPerFullPage);
-    const potentialSavings = Math.max(0, stats.leafPages - optimalPages);
+    // Leaf pages a fresh REINDEX would produce (simulated server-side)
+    const reindex = bloat.reindex || { leafPages: stats.leafPages, pagesDiff: 0, fillfactor: 90, deduplicate: false };
+    const optimalPages = reindex.leafPages;
+    const potentialSavings = Math.max(0, reindex.pagesDiff);
 
     return `
         <div class="stats-grid animate-in">
@@ -408,7 +408,7 @@ function renderBloatTab(stats, bloat, densityMap) {
                         ${optimalPages > 50 ? `<div style="padding: 4px 8px; font-size: 0.7rem; color: var(--text-muted);">+${optimalPages - 50}</div>` : ''}
                     </div>
                     <div style="font-family: 'IBM Plex Mono', monospace; font-size: 1.5rem; font-weight: 700; color: var(--green-400);">~${optimalPages} pages</div>
-                    <div style="font-size: 0.8rem; color: var(--text-muted);">~${formatBytes(optimalPages * 8192)} (estimated)</div>
+                    <div style="font-size: 0.8rem; color: var(--text-muted);">~${formatBytes(optimalPages * 8192)} • fillfactor ${reindex.fillfactor}${reindex.deduplicate ? ' • deduplicated' : ''}${reindex.sampled ? ` • extrapolated from ${reindex.sampledPages} sampled pages` : ''}</div>
                 </div>
             </div>
 
@@ -418,7 +418,7 @@ function renderBloatTab(stats, bloat, densityMap) {
                 <div style="font-size: 2rem;">💾</div>
                 <div>
                     <div style="font-weight: 700; color: var(--green-400);">Potential savings: ~${potentialSavings} pages (${formatBytes(potentialSavings * 8192)})</div>
-                    <div style="font-size: 0.85rem; color: var(--text-muted);">REINDEX could reduce this index by approximately ${((potentialSavings / stats.leafPages) * 100).toFixed(0)}%</div>
+                    <div style="font-size: 0.85rem; color: var(--text-muted);">REINDEX would reduce this index from ${stats.indexSize / 8192} to ${reindex.totalPages} pages (${bloat.estimatedBloat.toFixed(1)}%)</div>
                 </div>
             </div>` : ''}
         </div>
@@ -564,7 +564,7 @@ function renderLeafGrid(pages) {
 function renderBloatPanel(bloat) {
     const actionConfig = {
         ok: { icon: '✅', title: 'Index is healthy', desc: 'No action needed. Density and page usage are within acceptable ranges.' },
-        vacuum: { icon: '🧹', title: 'Consider running VACUUM', desc: 'Some dead tuples detected. VACUUM will clean dead entries but won\'t compact pages.' },
+        vacuum: { icon: '🧹', title: 'Consider running VACUUM', desc: 'The excess is made of empty or deleted pages. VACUUM makes them reusable for future page splits, so the index stops growing, but it never shrinks the file; only REINDEX returns the space.' },
         reindex: { icon: '🔄', title: 'REINDEX recommended', desc: `Significant bloat detected (${bloat.estimatedBloat.toFixed(1)}%). REINDEX will rebuild the index optimally.` }
     };
     const action = actionConfig[bloat.recommendAction];
@@ -638,9 +638,18 @@ async function loadPage(blockNo) {
     }
 }
 
-// Decode hex data to integer (for integer primary keys)
-// PostgreSQL stores integers in little-endian format
-function decodeKeyData(hexData) {
+// Decode an index item key. The server decodes keys using the index column
+// types; for raw hex we fall back to guessing an integer key
+// (PostgreSQL stores integers in little-endian format)
+function decodeKeyData(item) {
+    if (item && typeof item === 'object') {
+        if (item.keys && item.keys.length > 0) {
+            const key = item.keys.join(', ');
+            return item.keys.length === 1 && /^-?\d+$/.test(key) ? Number(key) : key;
+        }
+        item = item.data;
+    }
+    const hexData = item;
     if (!hexData || hexData.trim().length === 0) return '−∞';
 
     // Split by spaces and reverse for little-endian
@@ -678,8 +687,7 @@ function renderPageDetail(detail) {
 
     // Get high key (first item on non-rightmost pages)
     const hasHighKey = stats.btpoNext !== 0 && items.length > 0;
-    const highKeyRaw = hasHighKey ? items[0].data : null;
-    const highKey = hasHighKey ? decodeKeyData(highKeyRaw) : '∞';
+    const highKey = hasHighKey ? decodeKeyData(items[0]) : '∞';
 
     // Skip high key item for display (it's not a real data item)
     const dataItems = hasHighKey ? items.slice(1) : items;
@@ -689,7 +697,7 @@ function renderPageDetail(detail) {
     const deadCount = dataItems.filter(i => i.dead).length;
 
     // Get key range
-    const keys = dataItems.map(i => decodeKeyData(i.data)).filter(k => typeof k === 'number');
+    const keys = dataItems.map(i => decodeKeyData(i)).filter(k => typeof k === 'number');
     const minKey = keys.length > 0 ? Math.min(...keys) : null;
     const maxKey = keys.length > 0 ? Math.max(...keys) : null;
 
@@ -803,17 +811,22 @@ function renderPageDetail(detail) {
                         ${deadCount > 0 ? `<span style="color: var(--red-400);">● ${deadCount} dead</span>` : ''}
                     </div>
                 </div>
+                ${deadCount > 0 ? `
+                <div style="font-size: 0.75rem; color: var(--text-muted); margin-bottom: 12px;">
+                    DEAD items carry the LP_DEAD hint: an index scan found every heap version they point to dead to all transactions.
+                    Scans skip them, and nbtree removes them (simple or bottom-up deletion) the next time the page runs out of room.
+                </div>` : ''}
 
                 <!-- Tuple Grid -->
                 <div style="display: grid; grid-template-columns: repeat(auto-fill, minmax(180px, 1fr)); gap: 8px; max-height: 500px; overflow-y: auto; padding: 4px;">
                     ${dataItems.slice(0, 50).map((item, idx) => {
-                        const keyVal = decodeKeyData(item.data);
+                        const keyVal = decodeKeyData(item);
                         const keyDisplay = typeof keyVal === 'number' ? keyVal.toLocaleString() : keyVal;
 
                         // Check for gap from previous item
                         let gapHtml = '';
                         if (idx > 0 && typeof keyVal === 'number') {
-                            const prevKey = decodeKeyData(dataItems[idx - 1].data);
+                            const prevKey = decodeKeyData(dataItems[idx - 1]);
                             if (typeof prevKey === 'number' && keyVal - prevKey > 1) {
                                 const gapSize = keyVal - prevKey - 1;
                                 gapHtml = `
@@ -849,7 +862,7 @@ function renderPageDetail(detail) {
                 <div style="margin-top: 16px; padding: 20px; background: var(--bg-primary); border-radius: 12px; text-align: center;">
                     <div style="font-size: 1.2rem; font-weight: 700; color: var(--text-secondary); margin-bottom: 8px;">+${dataItems.length - 50} more tuples</div>
                     <div style="font-size: 0.85rem; color: var(--text-muted);">
-                        Keys continue from <span style="font-family: 'IBM Plex Mono', monospace; color: var(--cyan-400);">${decodeKeyData(dataItems[50]?.data)}</span> to <span style="font-family: 'IBM Plex Mono', monospace; color: var(--cyan-400);">${decodeKeyData(dataItems[dataItems.length-1]?.data)}</span>
+                        Keys continue from <span style="font-family: 'IBM Plex Mono', monospace; color: var(--cyan-400);">${decodeKeyData(dataItems[50])}</span> to <span style="font-family: 'IBM Plex Mono', monospace; color: var(--cyan-400);">${decodeKeyData(dataItems[dataItems.length-1])}</span>
                     </div>
                 </div>` : ''}
             </div>
@@ -888,6 +901,12 @@ async function selectTable(tableName, pushHistory = true) {
     highlightTID = null;
     demoRowData = null;      // Reset demo state when switching tables
     demoResult = null;
+    demoDmlResult = null;
+    experimentRuns = [];
+    demoPruneResult = null;
+    savepointResult = null;
+    rowHistory = null;
+    deadTuples = null;
     demoIndexInfo = null;    // Reset indexed columns info
 
     // Update URL
@@ -953,6 +972,7 @@ function renderTableView(data) {
             <button class="tab ${currentTab === 'pages' ? 'active' : ''}" onclick="switchTableTab('pages')">Pages</button>
             <button class="tab ${currentTab === 'storage' ? 'active' : ''}" onclick="switchTableTab('storage')">Storage Layout <sup style="font-size: 0.6em; opacity: 0.7;">INFO</sup></button>
             <button class="tab ${currentTab === 'demo' ? 'active' : ''}" onclick="switchTableTab('demo')">🧪 HOT Demo</button>
+            <button class="tab ${currentTab === 'dead' ? 'active' : ''}" onclick="switchTableTab('dead')">🧟 Dead Tuples</button>
         </div>
 
         <div id="tableTabContent"></div>
@@ -974,6 +994,8 @@ function renderTableTabContent() {
         container.innerHTML = renderTableStorageTab();
     } else if (currentTab === 'demo') {
         container.innerHTML = renderDemoTab();
+    } else if (currentTab === 'dead') {
+        container.innerHTML = renderDeadTuplesTab();
     }
 }
 
@@ -1082,7 +1104,8 @@ function renderTableOverviewTab() {
                                 ${idx.isPrimary ? '<span style="margin-left: 8px; background: var(--purple-500); color: white; font-size: 0.6rem; padding: 2px 6px; border-radius: 4px;">PRIMARY</span>' : ''}
                                 ${idx.isUnique && !idx.isPrimary ? '<span style="margin-left: 8px; background: var(--blue-500); color: white; font-size: 0.6rem; padding: 2px 6px; border-radius: 4px;">UNIQUE</span>' : ''}
                             </div>
-                            <div style="font-family: 'IBM Plex Mono', monospace; font-size: 0.8rem; color: var(--text-secondary);">${idx.columns.join(', ')}</div>
+                            <div style="font-family: 'IBM Plex Mono', monospace; font-size: 0.8rem; color: var(--text-secondary);">${(idx.keyColumns || idx.columns).join(', ')}${idx.includeColumns && idx.includeColumns.length ? ` <span style="color: var(--text-muted);">INCLUDE (${idx.includeColumns.join(', ')})</span>` : ''}</div>
+                            ${idx.predicate ? `<div style="font-family: 'IBM Plex Mono', monospace; font-size: 0.75rem; color: var(--yellow-400);">WHERE ${idx.predicate}</div>` : ''}
                             <div style="font-size: 0.75rem; color: var(--text-muted);">${formatBytes(idx.size)}</div>
                         </div>
                     `).join('')}
@@ -1163,12 +1186,419 @@ function renderTablePagesTab() {
                 <div style="font-weight: 600; margin-bottom: 16px;">Density Distribution</div>
                 ${renderHeapDensityHistogram(pageMap.pages || [])}
             </div>
+
+            <!-- Freeze debt -->
+            <div style="margin-top: 24px; padding: 20px; background: var(--bg-primary); border-radius: 12px;">
+                <div style="display: flex; justify-content: space-between; align-items: center; margin-bottom: 12px;">
+                    <div style="font-weight: 600;">Freeze Debt (XID age)</div>
+                    <button class="btn" onclick="loadFreezeInfo()">Load</button>
+                </div>
+                <div id="freezeInfoPanel" style="font-size: 0.8rem; color: var(--text-muted);">Shows the age of the oldest unfrozen xmin on every page against the autovacuum freeze thresholds.</div>
+            </div>
+
+            <!-- VACUUM prediction -->
+            <div style="margin-top: 24px; padding: 20px; background: var(--bg-primary); border-radius: 12px;">
+                <div style="display: flex; justify-content: space-between; align-items: center; margin-bottom: 12px;">
+                    <div style="font-weight: 600;">VACUUM Prediction</div>
+                    <div style="display: flex; gap: 8px;">
+                        <button class="btn" onclick="loadVacuumPrediction(false)">Predict</button>
+                        <button class="btn" onclick="loadVacuumPrediction(true)">Run VACUUM now</button>
+                    </div>
+                </div>
+                <div id="vacuumPredictionPanel" style="font-size: 0.8rem; color: var(--text-muted);">Predicts, page by page, which tuples VACUUM would prune, which line pointers change and whether the page becomes all-visible.</div>
+            </div>
+
+            <!-- Maintenance demos -->
+            <div style="margin-top: 24px; padding: 20px; background: var(--bg-primary); border-radius: 12px;">
+                <div style="display: flex; justify-content: space-between; align-items: center; margin-bottom: 12px;">
+                    <div style="font-weight: 600;">Maintenance Demos</div>
+                    <div style="display: flex; gap: 8px;">
+                        <button class="btn" onclick="runMaintenanceDemo('vacuum')">VACUUM</button>
+                        <button class="btn" onclick="runMaintenanceDemo('freeze')">VACUUM (FREEZE)</button>
+                        <button class="btn" onclick="runMaintenanceDemo('full')">VACUUM FULL</button>
+                        <button class="btn" onclick="runMaintenanceDemo('cluster')">CLUSTER</button>
+                    </div>
+                </div>
+                <div id="maintenancePanel" style="font-size: 0.8rem; color: var(--text-muted);">Runs the command and compares the page map, visibility bits, index stats and relfilenode before and after.</div>
+            </div>
+
+            <!-- Sessions -->
+            <div style="margin-top: 24px; padding: 20px; background: var(--bg-primary); border-radius: 12px;">
+                <div style="display: flex; justify-content: space-between; align-items: center; margin-bottom: 12px;">
+                    <div style="font-weight: 600;">Sessions</div>
+                    <div style="display: flex; gap: 8px; align-items: center;">
+                        <input id="sessionNameInput" placeholder="name" style="padding: 6px 10px; background: var(--bg-secondary); border: 1px solid var(--border); border-radius: 6px; color: var(--text-primary); width: 100px;">
+                        <select style="padding: 6px 10px; background: var(--bg-secondary); border: 1px solid var(--border); border-radius: 6px; color: var(--text-primary);" id="sessionIsolationInput">
+                            <option value="">read committed</option>
+                            <option value="repeatable read">repeatable read</option>
+                            <option value="serializable">serializable</option>
+                        </select>
+                        <button class="btn" onclick="openSession()">Open</button>
+                        <button class="btn" onclick="loadSessions()">Refresh</button>
+                    </div>
+                </div>
+                <div style="display: flex; gap: 8px; align-items: center; margin-bottom: 12px; font-size: 0.8rem;">
+                    <select style="padding: 6px 10px; background: var(--bg-secondary); border: 1px solid var(--border); border-radius: 6px; color: var(--text-primary);" id="scenarioSelect" onchange="selectScenario()">
+                        <option value="">Scenario...</option>
+                        ${Object.entries(SESSION_SCENARIOS).map(([k, sc]) => `<option value="${k}">${sc.title}</option>`).join('')}
+                    </select>
+                    <input id="scenarioX" placeholder="row X pk" style="padding: 6px 10px; background: var(--bg-secondary); border: 1px solid var(--border); border-radius: 6px; color: var(--text-primary); width: 90px;">
+                    <input id="scenarioY" placeholder="row Y pk" style="padding: 6px 10px; background: var(--bg-secondary); border: 1px solid var(--border); border-radius: 6px; color: var(--text-primary); width: 90px;">
+                    <button class="btn" onclick="runScenarioStep()">Next step</button>
+                </div>
+                <div id="scenarioPanel" style="font-size: 0.8rem; color: var(--text-muted); margin-bottom: 12px;"></div>
+                <div id="sessionsPanel" style="font-size: 0.8rem; color: var(--text-muted);">Open named sessions that keep their transaction across requests, then view a page through each session's snapshot.</div>
+            </div>
+
+            <!-- Row locks (pgrowlocks) -->
+            <div style="margin-top: 24px; padding: 20px; background: var(--bg-primary); border-radius: 12px;">
+                <div style="display: flex; justify-content: space-between; align-items: center; margin-bottom: 12px;">
+                    <div style="font-weight: 600;">Row Locks</div>
+                    <button class="btn" onclick="loadRowLocks()">Refresh</button>
+                </div>
+                <div id="rowLocksPanel" style="font-size: 0.8rem; color: var(--text-muted);">Click refresh to list rows locked right now.</div>
+            </div>
         </div>
 
         <div id="heapPageDetailPanel"></div>
     `;
 }
 
+async function loadFreezeInfo() {
+    const panel = document.getElementById('freezeInfoPanel');
+    if (!panel) return;
+    try {
+        const info = await fetchAPI(`/api/table/${currentTable}/freeze`);
+        const pages = (currentTableData.pageMap.pages || []).filter(p => p.liveTuples >= 0).slice(0, 200);
+        const frozen = pages.filter(p => p.allFrozen).length;
+        const colorFor = p => {
+            if (p.allFrozen) return 'var(--blue-500)';
+            const pct = p.oldestXminAge / info.freezeMaxAge * 100;
+            if (pct >= 75) return 'var(--red-500)';
+            if (pct >= 50) return 'var(--orange-500)';
+            if (pct >= 25) return 'var(--yellow-500)';
+            return 'var(--green-500)';
+        };
+        panel.innerHTML = `
+            <div style="display: grid; grid-template-columns: repeat(auto-fit, minmax(160px, 1fr)); gap: 12px; margin-bottom: 12px;">
+                <div><div class="stat-label">age(relfrozenxid)</div><div class="stat-value">${info.frozenXidAge.toLocaleString()}</div></div>
+                <div><div class="stat-label">age(relminmxid)</div><div class="stat-value">${info.minMxidAge.toLocaleString()}</div></div>
+                <div><div class="stat-label">Until forced autovacuum</div><div class="stat-value ${info.xidsUntilForced <= 0 ? 'bad' : ''}">${info.xidsUntilForced.toLocaleString()}</div></div>
+                <div><div class="stat-label">All-frozen pages</div><div class="stat-value">${frozen} / ${pages.length}</div></div>
+            </div>
+            <div style="color: var(--text-secondary); margin-bottom: 12px;">${info.explanation}</div>
+            <div style="color: var(--text-muted); margin-bottom: 8px;">
+                freeze_min_age ${info.freezeMinAge.toLocaleString()} • freeze_table_age ${info.freezeTableAge.toLocaleString()} •
+                freeze_max_age ${info.freezeMaxAge.toLocaleString()} • multixact_freeze_max_age ${info.multixactFreezeMaxAge.toLocaleString()} •
+                failsafe_age ${info.failsafeAge != null ? info.failsafeAge.toLocaleString() : 'n/a'}
+            </div>
+            <div class="leaf-grid">
+                ${pages.map(p => `<div class="leaf-page" style="background: ${colorFor(p)} !important;" onclick="loadHeapPage(${p.blockNo})"
+                    title="Page ${p.blockNo}: ${p.allFrozen ? 'all frozen' : `${p.unfrozenTuples} unfrozen, oldest xmin ${p.oldestUnfrozenXmin} (age ${p.oldestXminAge.toLocaleString()})`}"></div>`).join('')}
+            </div>
+            <div class="leaf-legend" style="margin-top: 8px;">
+                <div class="legend-item"><div class="legend-box" style="background: var(--blue-500);"></div>All frozen</div>
+                <div class="legend-item"><div class="legend-box" style="background: var(--green-500);"></div>&lt;25% of max age</div>
+                <div class="legend-item"><div class="legend-box" style="background: var(--yellow-500);"></div>25-50%</div>
+                <div class="legend-item"><div class="legend-box" style="background: var(--orange-500);"></div>50-75%</div>
+                <div class="legend-item"><div class="legend-box" style="background: var(--red-500);"></div>&gt;75%</div>
+            </div>`;
+    } catch (err) {
+        panel.innerHTML = `Failed to load freeze info: ${err.message}`;
+    }
+}
+
+async function loadVacuumPrediction(run) {
+    const panel = document.getElementById('vacuumPredictionPanel');
+    if (!panel) return;
+    if (run && !confirm(`Run VACUUM on ${currentTable}?`)) return;
+    panel.innerHTML = run ? 'Running VACUUM...' : 'Predicting...';
+    try {
+        const url = `/api/table/${currentTable}/vacuum-prediction`;
+        const v = run
+            ? await fetch(`${url}/run`, { method: 'POST' }).then(r => { if (!r.ok) throw new Error(`HTTP ${r.status}`); return r.json(); })
+            : await fetchAPI(url);
+        const pages = v.pages.filter(p => p.changes.length > 0 || p.recentlyDead > 0 || p.allVisible !== p.allVisibleBefore || p.actual);
+        panel.innerHTML = `
+            <div style="display: grid; grid-template-columns: repeat(auto-fit, minmax(160px, 1fr)); gap: 12px; margin-bottom: 12px;">
+                <div><div class="stat-label">Horizon</div><div class="stat-value">${v.horizon}</div></div>
+                <div><div class="stat-label">Dead tuples removed</div><div class="stat-value">${v.deadTuples}</div></div>
+                <div><div class="stat-label">Bytes freed</div><div class="stat-value">${v.freedBytes.toLocaleString()}</div></div>
+                <div><div class="stat-label">All-visible pages</div><div class="stat-value">${v.allVisiblePages} / ${v.pages.length}</div></div>
+                ${v.ran ? `<div><div class="stat-label">Matched prediction</div><div class="stat-value ${v.matchingPages < v.pages.length ? 'bad' : ''}">${v.matchingPages} / ${v.pages.length}</div></div>` : ''}
+            </div>
+            <div style="color: var(--text-secondary); margin-bottom: 12px;">${v.explanation}</div>
+            ${pages.length === 0 ? '<div>No page would change.</div>' : pages.map(p => `
+                <div style="display: flex; gap: 16px; padding: 6px 0; border-bottom: 1px solid var(--border);">
+                    <span onclick="loadHeapPage(${p.blockNo})" style="cursor: pointer; font-family: 'IBM Plex Mono', monospace; color: var(--cyan-400); min-width: 64px;">page ${p.blockNo}</span>
+                    <div style="flex: 1;">
+                        ${p.changes.map(c => `<div title="${c.reason}">lp ${c.lp}: ${c.from} → ${c.to}${c.to === 'LP_REDIRECT' ? ` (${c.redirectTo})` : ''}</div>`).join('')}
+                        ${p.recentlyDead ? `<div style="color: var(--orange-500);">${p.recentlyDead} recently dead kept</div>` : ''}
+                    </div>
+                    <span>free ${p.freeBefore} → ${p.freeAfter}${p.truncatedLps ? ` (${p.truncatedLps} lp truncated)` : ''}</span>
+                    <span title="${p.allVisibleReason}">all-visible ${p.allVisibleBefore ? 'yes' : 'no'} → ${p.allVisible ? 'yes' : 'no'}</span>
+                    ${v.ran ? `<span>${p.actual.matches ? '✅ as predicted' : p.actual.mismatches.map(m => `<div>❌ ${m}</div>`).join('')}</span>` : ''}
+                </div>`).join('')}`;
+    } catch (err) {
+        panel.innerHTML = `Failed to predict VACUUM: ${err.message}`;
+    }
+}
+
+async function runMaintenanceDemo(operation) {
+    const panel = document.getElementById('maintenancePanel');
+    if (!panel) return;
+    if ((operation === 'full' || operation === 'cluster') && !confirm(`This rewrites ${currentTable} under an ACCESS EXCLUSIVE lock. Continue?`)) return;
+    panel.innerHTML = 'Running...';
+    try {
+        const res = await fetch(`/api/table/${currentTable}/demo/maintenance`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ operation })
+        });
+        if (!res.ok) throw new Error(`HTTP ${res.status}`);
+        const data = await res.json();
+        if (!data.success) {
+            panel.innerHTML = `<span style="color: var(--red-500);">${data.error}</span>`;
+            return;
+        }
+        const b = data.before, a = data.after, d = data.diff;
+        const row = (label, before, after) => `
+            <div><div class="stat-label">${label}</div><div class="stat-value">${before} → ${after}</div></div>`;
+        panel.innerHTML = `
+            <div style="font-family: 'IBM Plex Mono', monospace; color: var(--cyan-400); margin-bottom: 8px;">${data.command} (${data.durationMs} ms)</div>
+            <div style="display: grid; grid-template-columns: repeat(auto-fit, minmax(160px, 1fr)); gap: 12px; margin-bottom: 12px;">
+                ${row('relfilenode', b.relfilenode, a.relfilenode)}
+                ${row('Pages', b.pages, a.pages)}
+                ${row('Dead tuples', b.deadTuples, a.deadTuples)}
+                ${row('Free space', b.freeSpace.toLocaleString(), a.freeSpace.toLocaleString())}
+                ${row('All-visible pages', b.allVisiblePages, a.allVisiblePages)}
+                ${row('All-frozen pages', b.allFrozenPages, a.allFrozenPages)}
+                ${row('age(relfrozenxid)', b.frozenXidAge.toLocaleString(), a.frozenXidAge.toLocaleString())}
+            </div>
+            <div style="color: var(--text-secondary); margin-bottom: 12px;">${data.explanation}</div>
+            ${d.indexes.map(i => `
+                <div style="display: flex; gap: 16px; padding: 6px 0; border-bottom: 1px solid var(--border);">
+                    <span style="font-family: 'IBM Plex Mono', monospace; color: var(--cyan-400);">${i.name}</span>
+                    <span>${i.rebuilt ? `rebuilt (${i.relfilenodeBefore} → ${i.relfilenodeAfter})` : 'same file'}</span>
+                    <span>${formatBytes(i.sizeBefore)} → ${formatBytes(i.sizeAfter)}</span>
+                    <span>leaf density ${i.leafDensityBefore.toFixed(1)}% → ${i.leafDensityAfter.toFixed(1)}%</span>
+                    <span>deleted pages ${i.deletedPagesBefore} → ${i.deletedPagesAfter}</span>
+                </div>`).join('')}
+            <div style="margin-top: 8px;">${d.pages.length} heap pages changed.</div>`;
+    } catch (err) {
+        panel.innerHTML = `Failed to run demo: ${err.message}`;
+    }
+}
+
+let viewSession = '';
+let scenarioState = null;
+
+const SESSION_SCENARIOS = {
+    concurrentUpdate: {
+        title: 'Concurrent update (row lock wait)',
+        steps: [
+            ['a', 'BEGIN', 'A starts a transaction'],
+            ['a', 'UPDATE {table} SET {pk} = {pk} WHERE {pk} = {x}', 'A updates row X: a new version appears, the old one gets xmax = A'],
+            ['b', 'BEGIN', 'B starts a transaction'],
+            ['b', 'UPDATE {table} SET {pk} = {pk} WHERE {pk} = {x}', 'B tries to update row X and waits for A; click next within 20s'],
+            ['a', 'COMMIT', 'A commits; under read committed B follows the ctid chain to A\'s version and updates that one'],
+            ['b', 'COMMIT', 'B commits'],
+        ],
+    },
+    repeatableRead: {
+        title: 'Repeatable read vs read committed',
+        steps: [
+            ['a', 'BEGIN ISOLATION LEVEL REPEATABLE READ', 'A starts a repeatable read transaction'],
+            ['a', 'SELECT * FROM {table} WHERE {pk} = {x}', 'The first query fixes A\'s snapshot'],
+            ['b', 'BEGIN ISOLATION LEVEL READ COMMITTED', 'B starts a read committed transaction'],
+            ['b', 'SELECT * FROM {table} WHERE {pk} = {x}', 'B reads row X'],
+            ['c', 'UPDATE {table} SET {pk} = {pk} WHERE {pk} = {x}', 'C updates row X and commits right away'],
+            ['a', 'SELECT * FROM {table} WHERE {pk} = {x}', 'A still sees the old version: its snapshot predates C'],
+            ['b', 'SELECT * FROM {table} WHERE {pk} = {x}', 'B takes a new snapshot per statement and sees the new version'],
+            ['a', 'COMMIT', ''],
+            ['b', 'COMMIT', ''],
+        ],
+    },
+    writeSkew: {
+        title: 'Write skew under serializable',
+        steps: [
+            ['a', 'BEGIN ISOLATION LEVEL SERIALIZABLE', 'A starts a serializable transaction'],
+            ['b', 'BEGIN ISOLATION LEVEL SERIALIZABLE', 'B starts a serializable transaction'],
+            ['a', 'SELECT count(*) FROM {table} WHERE {pk} IN ({x}, {y})', 'A reads rows X and Y'],
+            ['b', 'SELECT count(*) FROM {table} WHERE {pk} IN ({x}, {y})', 'B reads rows X and Y'],
+            ['a', 'UPDATE {table} SET {pk} = {pk} WHERE {pk} = {x}', 'A writes row X, which B read'],
+            ['b', 'UPDATE {table} SET {pk} = {pk} WHERE {pk} = {y}', 'B writes row Y, which A read'],
+            ['a', 'COMMIT', 'A commits first'],
+            ['b', 'COMMIT', 'B fails with a serialization error: the two could not have run one after the other'],
+        ],
+    },
+};
+
+async function sessionAPI(path, options = {}) {
+    const res = await fetch(`/api/sessions${path}`, {
+        headers: { 'Content-Type': 'application/json' },
+        ...options,
+    });
+    if (res.status === 204) return null;
+    const data = await res.json();
+    if (!res.ok) throw new Error(data.error || `HTTP ${res.status}`);
+    return data;
+}
+
+async function loadSessions() {
+    const panel = document.getElementById('sessionsPanel');
+    if (!panel) return;
+    try {
+        const sessions = await sessionAPI('');
+        if (sessions.length === 0) {
+            panel.innerHTML = 'No open sessions.';
+            return;
+        }
+        panel.innerHTML = sessions.map(s => `
+            <div style="padding: 10px 0; border-bottom: 1px solid var(--border);">
+                <div style="display: flex; gap: 16px; align-items: center; margin-bottom: 6px;">
+                    <span style="font-family: 'IBM Plex Mono', monospace; color: var(--cyan-400); font-weight: 600;">${s.name}</span>
+                    <span>pid ${s.pid}</span>
+                    <span>${s.isolation}</span>
+                    <span style="color: ${s.txStatus === 'failed' ? 'var(--red-500)' : s.txStatus === 'idle' ? 'var(--text-muted)' : 'var(--green-500)'};">${s.txStatus}</span>
+                    ${s.xid ? `<span>xid ${s.xid}</span>` : ''}
+                    ${s.snapshot ? `<span>snapshot ${s.snapshot.xmin}:${s.snapshot.xmax}:${(s.snapshot.xip || []).join(',')}</span>` : ''}
+                    <span style="flex: 1;"></span>
+                    <button class="btn" onclick="viewPageAs('${s.name}')">View page as</button>
+                    <button class="btn" onclick="closeSession('${s.name}')">Close</button>
+                </div>
+                <div style="display: flex; gap: 8px;">
+                    <input id="sessionSQL-${s.name}" style="padding: 6px 10px; background: var(--bg-secondary); border: 1px solid var(--border); border-radius: 6px; color: var(--text-primary); flex: 1; font-family: 'IBM Plex Mono', monospace;" placeholder="SQL" onkeydown="if (event.key === 'Enter') execSession('${s.name}')">
+                    <button class="btn" onclick="execSession('${s.name}')">Run</button>
+                </div>
+                <div id="sessionResult-${s.name}"></div>
+            </div>`).join('');
+    } catch (err) {
+        panel.innerHTML = `Failed to load sessions: ${err.message}`;
+    }
+}
+
+async function openSession(name, isolation) {
+    name = name || document.getElementById('sessionNameInput').value.trim();
+    isolation = isolation ?? document.getElementById('sessionIsolationInput').value;
+    try {
+        await sessionAPI('', { method: 'POST', body: JSON.stringify({ name, isolation }) });
+    } catch (err) {
+        alert(`Failed to open session: ${err.message}`);
+    }
+    await loadSessions();
+}
+
+async function closeSession(name) {
+    try {
+        await sessionAPI(`/${name}`, { method: 'DELETE' });
+    } catch (err) {
+        alert(`Failed to close session: ${err.message}`);
+    }
+    if (viewSession === name) viewSession = '';
+    await loadSessions();
+}
+
+async function execSession(name, sql) {
+    sql = sql || document.getElementById(`sessionSQL-${name}`)?.value.trim();
+    if (!sql) return null;
+    let res;
+    try {
+        res = await sessionAPI(`/${name}/exec`, { method: 'POST', body: JSON.stringify({ sql }) });
+    } catch (err) {
+        res = { success: false, error: err.message, results: [] };
+    }
+    await loadSessions();
+    const out = document.getElementById(`sessionResult-${name}`);
+    if (out) out.innerHTML = renderSessionResult(res);
+    if (selectedPage !== null) loadHeapPage(selectedPage);
+    return res;
+}
+
+function renderSessionResult(res) {
+    const results = (res.results || []).map(r => `
+        <div style="margin-top: 6px;">
+            <div style="color: var(--text-secondary);">${r.commandTag}</div>
+            ${r.columns?.length ? `<div style="font-family: 'IBM Plex Mono', monospace; color: var(--text-muted);">
+                <div>${r.columns.join(' | ')}</div>
+                ${r.rows.map(row => `<div>${row.map(v => v === null ? 'NULL' : v).join(' | ')}</div>`).join('')}
+                ${r.truncated ? '<div>…</div>' : ''}
+            </div>` : ''}
+        </div>`).join('');
+    return results + (res.success ? '' : `<div style="margin-top: 6px; color: var(--red-500);">${res.error}</div>`)
+        + (res.stateError ? `<div style="margin-top: 6px; color: var(--text-muted);">session state not refreshed: ${res.stateError}</div>` : '');
+}
+
+function viewPageAs(name) {
+    viewSession = name;
+    if (selectedPage !== null) loadHeapPage(selectedPage);
+}
+
+function selectScenario() {
+    const key = document.getElementById('scenarioSelect').value;
+    scenarioState = key ? { key, step: 0 } : null;
+    renderScenario();
+}
+
+function renderScenario(lastResult) {
+    const panel = document.getElementById('scenarioPanel');
+    if (!panel) return;
+    if (!scenarioState) {
+        panel.innerHTML = '';
+        return;
+    }
+    const sc = SESSION_SCENARIOS[scenarioState.key];
+    panel.innerHTML = sc.steps.map(([who, sql, note], i) => `
+        <div style="padding: 2px 0; ${i === scenarioState.step ? 'color: var(--text-primary); font-weight: 600;' : i < scenarioState.step ? 'opacity: 0.5;' : ''}">
+            ${i + 1}. <span style="color: var(--cyan-400);">${who}</span>: <code>${scenarioSQL(sql)}</code>${note ? ` — ${note}` : ''}
+        </div>`).join('') + (lastResult && !lastResult.success ? `<div style="color: var(--red-500);">${lastResult.error}</div>` : '');
+}
+
+function scenarioSQL(sql) {
+    const pk = currentTableData?.detail?.columns?.find(c => c.isPK)?.name || 'id';
+    const x = document.getElementById('scenarioX')?.value || '1';
+    const y = document.getElementById('scenarioY')?.value || '2';
+    return sql.replaceAll('{table}', currentTable).replaceAll('{pk}', pk).replaceAll('{x}', x).replaceAll('{y}', y);
+}
+
+async function runScenarioStep() {
+    if (!scenarioState) return;
+    const sc = SESSION_SCENARIOS[scenarioState.key];
+    if (scenarioState.step >= sc.steps.length) return;
+    const [who, sql] = sc.steps[scenarioState.step];
+    const open = await sessionAPI('');
+    if (!open.some(s => s.name === who)) await openSession(who, '');
+    scenarioState.step++;
+    renderScenario();
+    // a blocked statement only returns once another session moves on
+    const res = await execSession(who, scenarioSQL(sql));
+    renderScenario(res);
+}
+
+async function loadRowLocks() {
+    const panel = document.getElementById('rowLocksPanel');
+    if (!panel) return;
+    try {
+        const data = await fetchAPI(`/api/table/${currentTable}/row-locks`);
+        if (!data.available) {
+            panel.innerHTML = `pgrowlocks is not installed (${data.hint})`;
+            return;
+        }
+        if (data.locks.length === 0) {
+            panel.innerHTML = 'No rows are locked.';
+            return;
+        }
+        panel.innerHTML = data.locks.map(l => `
+            <div onclick="goToRowLocation(${l.page}, ${l.item})" style="cursor: pointer; display: flex; gap: 16px; padding: 6px 0; border-bottom: 1px solid var(--border);">
+                <span style="font-family: 'IBM Plex Mono', monospace; color: var(--cyan-400);">${l.tid}</span>
+                <span>${l.multi ? `multixact ${l.locker}` : `xid ${l.locker}`}</span>
+                <span>${l.xids.map((x, i) => `${x} ${l.modes[i]} (pid ${l.pids[i]})`).join(', ')}</span>
+            </div>`).join('');
+    } catch (err) {
+        panel.innerHTML = `Failed to load row locks: ${err.message}`;
+    }
+}
+
 function renderHeapPageGrid(pages) {
     const maxShow = 200;
     const pagesToShow = pages.slice(0, maxShow);
@@ -1573,8 +2003,24 @@ async function loadHeapPage(blockNo) {
         </div>`;
 
     try {
-        const detail = await fetchAPI(`/api/table/${currentTable}/page/${blockNo}`);
-        panel.innerHTML = renderHeapPageDetail(detail, blockNo);
+        const sessionQuery = viewSession ? `?session=${encodeURIComponent(viewSession)}` : '';
+        const [detail, horizon, chains] = await Promise.all([
+            fetchAPI(`/api/table/${currentTable}/page/${blockNo}${sessionQuery}`),
+            fetchAPI(`/api/mvcc/horizon?table=${encodeURIComponent(currentTable)}&page=${blockNo}`).catch(() => null),
+            fetchAPI(`/api/table/${currentTable}/page/${blockNo}/hot-chains${sessionQuery}`).catch(() => null)
+        ]);
+        detail.hotChains = chains;
+        if (horizon) {
+            const byLp = new Map((horizon.tuples || []).map(t => [t.lp, t]));
+            detail.tuples.forEach(t => {
+                const h = byLp.get(t.lp);
+                if (h) {
+                    t.vacuumState = h.state;
+                    t.vacuumReason = h.reason;
+                }
+            });
+        }
+        panel.innerHTML = renderSessionBanner(detail) + renderHorizonBanner(horizon) + renderHeapPageDetail(detail, blockNo);
     } catch (err) {
         panel.innerHTML = `
             <div class="page-detail">
@@ -1587,104 +2033,60 @@ async function loadHeapPage(blockNo) {
 }
 
 // Find and render ctid chains (HOT chains) on a page
-function renderCtidChains(tuples, blockNo) {
-    // Build a map of ctid targets -> source tuples
-    // A chain exists when tuple.ctid points to another tuple on the same page
-    const chains = [];
-    const visited = new Set();
-
-    // Parse ctid format "(page,item)" and find chains
-    function parseCtid(ctid) {
-        const match = ctid?.match(/\((\d+),(\d+)\)/);
-        if (match) {
-            return { page: parseInt(match[1]), item: parseInt(match[2]) };
-        }
-        return null;
+function renderCtidChains(hotChains) {
+    // Chains are rebuilt by the server, following LP_REDIRECT and t_ctid
+    const chains = hotChains?.chains || [];
+    const orphans = hotChains?.orphans || [];
+    if (chains.length === 0 && orphans.length === 0) {
+        return ''; // No chains to display
     }
 
-    // Find tuples that start chains (have ctid pointing elsewhere on same page)
-    for (const tuple of tuples) {
-        if (visited.has(tuple.lp)) continue;
-
-        const target = parseCtid(tuple.ctid);
-        // If ctid points to a different tuple on the same page, this is a chain
-        if (target && target.page === blockNo && target.item !== tuple.lp) {
-            // Build the chain
-            const chain = [tuple.lp];
-            visited.add(tuple.lp);
-
-            let currentItem = target.item;
-            let iterations = 0;
-            const maxIterations = 20; // Prevent infinite loops
-
-            while (iterations < maxIterations) {
-                const nextTuple = tuples.find(t => t.lp === currentItem);
-                if (!nextTuple || visited.has(nextTuple.lp)) break;
-
-                chain.push(nextTuple.lp);
-                visited.add(nextTuple.lp);
-
-                const nextTarget = parseCtid(nextTuple.ctid);
-                if (!nextTarget || nextTarget.page !== blockNo || nextTarget.item === nextTuple.lp) {
-                    break; // End of chain
-                }
-                currentItem = nextTarget.item;
-                iterations++;
-            }
+    const box = (lp, label, sub, color) => `
+        <div onclick="scrollToTuple(${lp})" style="cursor: pointer; padding: 8px 16px; background: var(--bg-primary); border: 2px solid ${color}; border-radius: 8px; transition: all 0.2s;">
+            <div style="font-family: 'IBM Plex Mono', monospace; font-weight: 700; color: ${color};">lp=${lp}</div>
+            <div style="font-size: 0.65rem; color: var(--text-muted);">${label}</div>
+            ${sub ? `<div style="font-size: 0.6rem; color: var(--text-muted);">${sub}</div>` : ''}
+        </div>`;
+    const arrow = '<span style="color: var(--purple-400); font-size: 1.2rem;">→</span>';
 
-            if (chain.length > 1) {
-                chains.push(chain);
-            }
+    const renderChain = (chain, idx) => {
+        const parts = [];
+        if (chain.rootFlags !== 'LP_NORMAL') {
+            const sub = chain.rootFlags === 'LP_REDIRECT' ? `→ lp ${chain.redirectTo}` : 'whole chain pruned';
+            parts.push(box(chain.root, `ROOT ${chain.rootFlags}`, sub, 'var(--yellow-400)'));
         }
-    }
-
-    if (chains.length === 0) {
-        return ''; // No chains to display
-    }
+        chain.members.forEach((m, i) => {
+            const color = m.aborted ? 'var(--red-400)' : m.visible ? 'var(--green-400)' : m.heapOnly ? 'var(--purple-400)' : 'var(--text-muted)';
+            const role = m.aborted ? 'ABORTED' : m.visible ? 'VISIBLE' : (i === 0 && chain.rootFlags === 'LP_NORMAL') ? 'ROOT' : 'heap-only';
+            parts.push(box(m.lp, role, `xmin: ${m.xmin}${m.heapOnly ? ' • HOT' : ''}`, color));
+        });
+        return `
+            <div style="background: var(--bg-secondary); border-radius: 12px; padding: 16px;">
+                <div style="font-size: 0.75rem; color: var(--text-muted); margin-bottom: 8px;">Chain ${idx + 1} (root lp ${chain.root}, ${chain.members.length} versions)</div>
+                <div style="display: flex; align-items: center; gap: 8px; flex-wrap: wrap;">
+                    ${parts.join(arrow)}
+                    ${chain.continuesAt ? `${arrow}<span style="font-family: 'IBM Plex Mono', monospace; color: var(--blue-400);">${chain.continuesAt} (regular update)</span>` : ''}
+                </div>
+                ${chain.break ? `<div style="margin-top: 8px; font-size: 0.75rem; color: var(--red-400);">⛓️‍💥 ${chain.break}</div>` : ''}
+                <div style="margin-top: 8px; font-size: 0.7rem; color: var(--text-muted);">
+                    ${chain.visibleLp ? `Index scans land on lp ${chain.root} and follow the chain to lp ${chain.visibleLp}.` : 'No version in this chain is visible to the snapshot.'}
+                </div>
+            </div>`;
+    };
 
-    // Render the chains visualization
     return `
         <div style="background: linear-gradient(135deg, rgba(168,85,247,0.1) 0%, rgba(139,92,246,0.1) 100%); border: 2px solid var(--purple-500); border-radius: 16px; padding: 20px; margin-bottom: 24px;">
             <div style="display: flex; align-items: center; gap: 12px; margin-bottom: 16px;">
                 <span style="font-size: 1.5rem;">🔗</span>
                 <div>
                     <div style="font-weight: 700; font-size: 1rem; color: var(--purple-400);">HOT Chains Detected</div>
-                    <div style="font-size: 0.8rem; color: var(--text-muted);">Tuples linked via ctid (same page updates)</div>
+                    <div style="font-size: 0.8rem; color: var(--text-muted);">Followed from each root line pointer through redirects and t_ctid</div>
                 </div>
             </div>
 
             <div style="display: flex; flex-direction: column; gap: 12px;">
-                ${chains.map((chain, idx) => `
-                    <div style="background: var(--bg-secondary); border-radius: 12px; padding: 16px;">
-                        <div style="font-size: 0.75rem; color: var(--text-muted); margin-bottom: 8px;">Chain ${idx + 1} (${chain.length} versions)</div>
-                        <div style="display: flex; align-items: center; gap: 8px; flex-wrap: wrap;">
-                            ${chain.map((lp, i) => {
-                                const tuple = tuples.find(t => t.lp === lp);
-                                const isLast = i === chain.length - 1;
-                                const isFirst = i === 0;
-                                return `
-                                    <div style="display: flex; align-items: center; gap: 8px;">
-                                        <div onclick="scrollToTuple(${lp})" style="cursor: pointer; padding: 8px 16px; background: ${!tuple?.isLive ? 'rgba(239,68,68,0.2)' : isLast ? 'rgba(34,197,94,0.2)' : 'rgba(168,85,247,0.2)'}; border: 2px solid ${!tuple?.isLive ? 'var(--red-400)' : isLast ? 'var(--green-400)' : 'var(--purple-400)'}; border-radius: 8px; transition: all 0.2s;">
-                                            <div style="font-family: 'IBM Plex Mono', monospace; font-weight: 700; color: ${!tuple?.isLive ? 'var(--red-400)' : isLast ? 'var(--green-400)' : 'var(--purple-400)'};">
-                                                lp=${lp}
-                                            </div>
-                                            <div style="font-size: 0.65rem; color: var(--text-muted);">
-                                                ${isFirst ? 'OLD' : isLast ? 'CURRENT' : `v${i + 1}`}
-                                            </div>
-                                            <div style="font-size: 0.6rem; color: var(--text-muted);">
-                                                xmin: ${tuple?.xmin || '?'}
-                                            </div>
-                                        </div>
-                                        ${!isLast ? '<span style="color: var(--purple-400); font-size: 1.2rem;">→</span>' : ''}
-                                    </div>
-                                `;
-                            }).join('')}
-                        </div>
-                        <div style="margin-top: 8px; font-size: 0.7rem; color: var(--text-muted);">
-                            ${chain.length > 1 ? `Old versions will be removed by VACUUM when no longer visible to any transaction.` : ''}
-                        </div>
-                    </div>
-                `).join('')}
+                ${chains.map(renderChain).join('')}
+                ${orphans.length ? `<div style="font-size: 0.8rem; color: var(--text-muted);">Heap-only tuples no chain reaches (pruning removes them): ${orphans.map(lp => `lp ${lp}`).join(', ')}</div>` : ''}
             </div>
 
             <div style="margin-top: 16px; padding: 12px; background: rgba(168,85,247,0.1); border-radius: 8px; font-size: 0.75rem; color: var(--text-secondary);">
@@ -1706,6 +2108,26 @@ function scrollToTuple(lp) {
     }
 }
 
+function renderSessionBanner(detail) {
+    if (!viewSession) return '';
+    const snap = detail.snapshot;
+    return `
+        <div style="margin-bottom: 16px; padding: 12px 16px; background: var(--bg-secondary); border: 1px solid var(--cyan-400); border-radius: 12px; font-size: 0.8rem; display: flex; justify-content: space-between;">
+            <span>👁️ Visibility as seen by session <b>${viewSession}</b>: snapshot ${snap ? `${snap.xmin}:${snap.xmax}:${(snap.xip || []).join(',')}` : 'none'}${snap?.current ? `, own xid ${snap.current}` : ''}${snap?.subxids?.length ? `, subxids ${snap.subxids.join(',')}` : ''}</span>
+            <a href="#" onclick="viewPageAs(''); return false;">show current snapshot</a>
+        </div>`;
+}
+
+function renderHorizonBanner(horizon) {
+    if (!horizon) return '';
+    const recentlyDead = (horizon.tuples || []).filter(t => t.state === 'RECENTLY_DEAD').length;
+    return `
+        <div style="margin-bottom: 16px; padding: 12px 16px; background: var(--bg-secondary); border: 1px solid var(--border); border-radius: 12px; font-size: 0.8rem;">
+            <div style="color: var(--text-secondary);">${horizon.explanation}</div>
+            ${recentlyDead > 0 ? `<div style="margin-top: 6px; color: var(--yellow-400);">${recentlyDead} dead tuple${recentlyDead > 1 ? 's' : ''} on this page cannot be removed yet (RECENTLY_DEAD).</div>` : ''}
+        </div>`;
+}
+
 function renderHeapPageDetail(detail, blockNo) {
     const { stats, tuples } = detail;
     const pageSize = 8192;
@@ -1796,7 +2218,7 @@ function renderHeapPageDetail(detail, blockNo) {
             </div>
 
             <!-- CTID CHAIN VISUALIZATION -->
-            ${renderCtidChains(tuples, blockNo)}
+            ${renderCtidChains(detail.hotChains)}
 
             <!-- TUPLES DETAIL -->
             <div style="background: var(--bg-secondary); border: 1px solid var(--border); border-radius: 16px; padding: 20px;">
@@ -1830,10 +2252,14 @@ function renderHeapPageDetail(detail, blockNo) {
                                     <div style="background: var(--bg-secondary); padding: 8px; border-radius: 6px;">
                                         <div style="font-size: 0.65rem; color: var(--text-muted);">xmin (insert)</div>
                                         <div style="font-family: 'IBM Plex Mono', monospace; font-size: 0.85rem; color: var(--green-400);">${tuple.xmin}</div>
+                                        ${tuple.xminStatus ? `<div style="font-size: 0.6rem; color: var(--text-muted);" title="${tuple.xminCommitTs ? new Date(tuple.xminCommitTs).toLocaleString() : ''}">${tuple.xminStatus}</div>` : ''}
                                     </div>
                                     <div style="background: var(--bg-secondary); padding: 8px; border-radius: 6px;">
                                         <div style="font-size: 0.65rem; color: var(--text-muted);">xmax (delete)</div>
                                         <div style="font-family: 'IBM Plex Mono', monospace; font-size: 0.85rem; color: ${tuple.xmax > 0 ? 'var(--red-400)' : 'var(--text-muted)'};">${tuple.xmax || '−'}</div>
+                                        ${tuple.xmaxStatus ? `<div style="font-size: 0.6rem; color: var(--text-muted);" title="${tuple.xmaxCommitTs ? new Date(tuple.xmaxCommitTs).toLocaleString() : ''}">${tuple.xmaxStatus}</div>` : ''}
+                                        ${tuple.lockState ? `<div style="font-size: 0.6rem; color: var(--yellow-400);">${tuple.lockState}</div>` : ''}
+                                        ${(tuple.xmaxMembers || []).map(m => `<div style="font-size: 0.6rem; color: var(--text-muted);">${m.xid} ${m.lock}${m.status ? ` (${m.status})` : ''}</div>`).join('')}
                                     </div>
                                 </div>
 
@@ -1866,6 +2292,8 @@ function renderHeapPageDetail(detail, blockNo) {
                                 <!-- Flags/State -->
                                 <div style="display: flex; flex-wrap: wrap; gap: 4px; margin-top: 8px;">
                                     ${!tuple.isLive ? '<span style="background: var(--red-500); color: white; font-size: 0.6rem; font-weight: 700; padding: 2px 6px; border-radius: 4px;">DEAD</span>' : ''}
+                                    ${tuple.vacuumState === 'RECENTLY_DEAD' ? `<span title="${tuple.vacuumReason}" style="background: var(--yellow-500); color: black; font-size: 0.6rem; font-weight: 700; padding: 2px 6px; border-radius: 4px; cursor: help;">RECENTLY DEAD</span>` : ''}
+                                    ${tuple.visibility ? `<span title="${tuple.visibility.steps.join('\n').replace(/"/g, '&quot;')}" style="background: var(--bg-secondary); color: ${tuple.visibility.visible ? 'var(--green-400)' : 'var(--red-400)'}; font-size: 0.6rem; font-weight: 700; padding: 2px 6px; border-radius: 4px; cursor: help;">${tuple.visibility.visible ? 'VISIBLE' : 'INVISIBLE'}</span>` : ''}
                                     ${tuple.isHot ? '<span style="background: var(--purple-500); color: white; font-size: 0.6rem; font-weight: 700; padding: 2px 6px; border-radius: 4px;">HOT</span>' : ''}
                                     ${tuple.isUpdated ? '<span style="background: var(--yellow-500); color: black; font-size: 0.6rem; font-weight: 700; padding: 2px 6px; border-radius: 4px;">UPDATED</span>' : ''}
                                     ${tuple.infoMask?.includes('HEAP_XMIN_COMMITTED') ? '<span style="background: var(--green-600); color: white; font-size: 0.6rem; padding: 2px 6px; border-radius: 4px;">COMMITTED</span>' : ''}
@@ -1900,6 +2328,8 @@ function switchTableTab(tab) {
 let demoRowData = null;
 let demoResult = null;
 let demoIndexInfo = null; // Cached indexed columns info for current table
+let demoRollback = false;
+let demoDmlResult = null;
 
 async function loadDemoIndexInfo() {
     if (!currentTable) return null;
@@ -2057,10 +2487,49 @@ function renderDemoTab() {
             </div>
             ` : ''}
 
+            <label style="display: flex; gap: 8px; align-items: center; font-size: 0.85rem; color: var(--text-secondary); margin-bottom: 16px;">
+                <input type="checkbox" id="demoRollback" ${demoRollback ? 'checked' : ''} onchange="demoRollback = this.checked">
+                Roll back afterwards: inspect the uncommitted update from inside and outside its transaction, then undo it
+            </label>
+
             <!-- Step 3: Results -->
             <div id="demoResultPanel">
                 ${demoResult ? renderDemoResult(demoResult) : ''}
             </div>
+
+            <!-- INSERT / DELETE -->
+            <div style="display: grid; grid-template-columns: 1fr 1fr; gap: 16px; margin-top: 24px; margin-bottom: 16px;">
+                <div style="background: var(--bg-secondary); border: 2px solid var(--green-500); border-radius: 12px; padding: 20px;">
+                    <div style="font-weight: 700; color: var(--green-400); margin-bottom: 12px;">➕ INSERT</div>
+                    <div style="font-size: 0.8rem; color: var(--text-muted); margin-bottom: 12px;">Empty fields use the column default. See which page the row lands on and why.</div>
+                    ${[...indexedColumns, ...nonIndexedColumns].map(col => `
+                    <div style="margin-bottom: 8px;">
+                        <label style="display: block; font-size: 0.75rem; color: var(--text-muted); margin-bottom: 4px;">${col} (${columnTypes[col]})${col === pkColumn ? ' - PK' : ''}</label>
+                        <input type="text" id="demoInsert_${col}" value="${col === pkColumn ? '' : (demoRowData?.columnData?.[col] ?? '')}"
+                               style="width: 100%; padding: 8px 12px; background: var(--bg-primary); border: 1px solid var(--border-light); border-radius: 8px; color: var(--text-primary); font-family: 'IBM Plex Mono', monospace; font-size: 0.85rem;">
+                    </div>
+                    `).join('')}
+                    <button class="btn" onclick="executeDemoInsert()" style="width: 100%; background: var(--green-500); border-color: var(--green-500); color: white; padding: 12px;">Execute INSERT</button>
+                </div>
+                <div style="background: var(--bg-secondary); border: 2px solid var(--red-500); border-radius: 12px; padding: 20px;">
+                    <div style="font-weight: 700; color: var(--red-400); margin-bottom: 12px;">🗑️ DELETE</div>
+                    <div style="font-size: 0.8rem; color: var(--text-muted); margin-bottom: 12px;">Delete the loaded row and watch xmax, the infomask and pd_prune_xid change, plus what pruning would do next.</div>
+                    <button class="btn" onclick="executeDemoDelete()" ${demoRowData ? '' : 'disabled'} style="width: 100%; background: var(--red-500); border-color: var(--red-500); color: white; padding: 12px;">
+                        ${demoRowData ? `Delete ${pkColumn} = ${demoRowData.pkValue ?? demoRowData.columnData?.[pkColumn]}` : 'Load a row first'}
+                    </button>
+                </div>
+            </div>
+            <div id="demoDmlResultPanel">
+                ${demoDmlResult ? renderDemoDmlResult(demoDmlResult) : ''}
+            </div>
+
+            ${renderPruneDemo(pkColumn, nonIndexedColumns)}
+
+            ${renderSavepointScenario(pkColumn, indexedColumns, nonIndexedColumns)}
+
+            ${renderRowHistory(pkColumn)}
+
+            ${renderUpdateExperiment(pkColumn, indexedColumns, nonIndexedColumns)}
         </div>
     `;
 }
@@ -2179,7 +2648,7 @@ function renderDemoResult(result) {
 
                 <!-- After (old tuple state) -->
                 <div style="background: var(--bg-primary); border-radius: 12px; padding: 16px; border: 2px solid var(--red-500);">
-                    <div style="font-weight: 600; color: var(--red-400); margin-bottom: 12px;">OLD TUPLE (now dead)</div>
+                    <div style="font-weight: 600; color: var(--red-400); margin-bottom: 12px;">${result.rolledBack ? 'OLD TUPLE (before rollback)' : 'OLD TUPLE (now dead)'}</div>
                     <div style="display: grid; gap: 8px;">
                         <div style="display: flex; justify-content: space-between;">
                             <span style="color: var(--text-muted);">TID</span>
@@ -2243,6 +2712,10 @@ function renderDemoResult(result) {
                 <div style="color: var(--text-secondary); line-height: 1.6;">${result.explanation}</div>
             </div>
 
+            ${result.hotReason ? renderHotReason(result.hotReason) : ''}
+
+            ${result.rolledBack ? renderRollbackResult(result) : ''}
+
             <!-- Actions -->
             <div style="display: flex; gap: 8px; margin-top: 16px;">
                 <button class="btn" onclick="loadDemoRow()" style="flex: 1;">🔄 Load Updated Row</button>
@@ -2252,6 +2725,59 @@ function renderDemoResult(result) {
     `;
 }
 
+function renderHotReason(r) {
+    const stat = (label, value) => `
+        <div>
+            <div style="font-size: 0.65rem; color: var(--text-muted);">${label}</div>
+            <div style="font-family: 'IBM Plex Mono', monospace; font-size: 0.9rem;">${value}</div>
+        </div>`;
+    const indexes = [...r.blockingIndexes, ...r.expressionIndexes, ...r.summarizingIndexes];
+    return `
+        <div style="background: var(--bg-primary); border-radius: 12px; padding: 16px; margin-top: 16px;">
+            <div style="font-weight: 600; margin-bottom: 12px;">
+                ${r.hot ? '✅' : '⛔'} HOT reason: <code style="color: ${r.hot ? 'var(--orange-400)' : 'var(--blue-400)'};">${r.code}</code>
+            </div>
+            <div style="display: grid; grid-template-columns: repeat(auto-fit, minmax(130px, 1fr)); gap: 12px;">
+                ${stat('Value changed', r.valueChanged ? 'yes' : 'no')}
+                ${stat('Indexes on column', indexes.join(', ') || 'none')}
+                ${stat('New version', `${r.newTupleSize} B`)}
+                ${stat('Usable on page', `${r.heapFreeSpace} B`)}
+                ${stat('Page stats free', `${r.pageFree} B`)}
+                ${stat('Line pointers', `${r.lpCount} / ${r.maxLps}`)}
+                ${stat('Fillfactor', `${r.fillFactor} (${r.reservedSpace} B)`)}
+                ${stat('TOAST', r.toasted ? 'out of line' : 'inline')}
+            </div>
+        </div>`;
+}
+
+function renderRollbackResult(result) {
+    const view = (label, v) => `
+        <div style="background: var(--bg-primary); border-radius: 12px; padding: 16px;">
+            <div style="font-weight: 600; color: var(--text-muted); margin-bottom: 8px;">${label}</div>
+            <div style="font-family: 'IBM Plex Mono', monospace; font-size: 0.75rem; color: var(--text-muted); margin-bottom: 8px;">snapshot ${v.snapshot.xmin}:${v.snapshot.xmax}:${(v.snapshot.xip || []).join(',')}</div>
+            <div title="${v.oldReason}">old ${result.after.tid}: ${v.oldVisible ? '✅ visible' : '🚫 invisible'}</div>
+            <div title="${v.newReason}">new ${result.newTuple.tid}: ${v.newVisible ? '✅ visible' : '🚫 invisible'}</div>
+        </div>`;
+    const stage = (label, st) => `
+        <div style="background: var(--bg-primary); border-radius: 12px; padding: 16px;">
+            <div style="font-weight: 600; color: var(--text-muted); margin-bottom: 8px;">${label}</div>
+            <div>old: ${st.old ? `xmax ${st.old.xmax} • ${st.old.infoMask.filter(f => f.includes('XMAX')).join(', ') || 'no xmax hints'}` : st.oldLp}</div>
+            <div>new: ${st.new ? `xmin ${st.new.xmin} • ${st.new.infoMask.filter(f => f.includes('XMIN')).join(', ') || 'no xmin hints'}` : st.newLp}</div>
+        </div>`;
+    return `
+        <div style="margin-top: 16px;">
+            <div style="font-weight: 600; margin-bottom: 8px;">↩️ Transaction ${result.xid}, rolled back</div>
+            <div style="display: grid; grid-template-columns: 1fr 1fr; gap: 16px; margin-bottom: 16px;">
+                ${view('Inside the transaction', result.inside)}
+                ${view('Outside (other sessions)', result.outside)}
+                ${stage('Right after ROLLBACK', result.afterRollback)}
+                ${stage('After the next read', result.afterRead)}
+            </div>
+            ${result.abortedVacuumState ? `<div style="color: var(--text-muted); font-size: 0.85rem; margin-bottom: 8px;">VACUUM verdict for the aborted version: ${result.abortedVacuumState}</div>` : ''}
+            <div style="background: var(--bg-tertiary); border-radius: 12px; padding: 16px; color: var(--text-secondary); line-height: 1.6;">${result.cleanup}</div>
+        </div>`;
+}
+
 async function loadDemoRow() {
     const pk = document.getElementById('demoPK')?.value?.trim();
     if (!pk) {
@@ -2366,7 +2892,8 @@ async function executeDemoUpdate(updateType) {
             body: JSON.stringify({
                 pk: String(pkValue),
                 column: column,
-                newValue: newValue
+                newValue: newValue,
+                rollback: demoRollback
             })
         });
 
@@ -2374,7 +2901,7 @@ async function executeDemoUpdate(updateType) {
         demoResult = data;
 
         // Update the PK input if PK column was changed
-        if (column === demoIndexInfo?.pkColumn && data.success) {
+        if (column === demoIndexInfo?.pkColumn && data.success && !data.rolledBack) {
             document.getElementById('demoPK').value = newValue;
         }
 
@@ -2384,6 +2911,623 @@ async function executeDemoUpdate(updateType) {
     }
 }
 
+async function executeDemoInsert() {
+    const values = {};
+    [...demoIndexInfo.indexedColumns, ...demoIndexInfo.nonIndexedColumns].forEach(col => {
+        const v = document.getElementById(`demoInsert_${col}`)?.value;
+        if (v) values[col] = v;
+    });
+    try {
+        const res = await fetch(`/api/table/${currentTable}/demo/insert`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ values })
+        });
+        demoDmlResult = { kind: 'insert', ...(await res.json()) };
+        renderTableTabContent();
+    } catch (err) {
+        alert('Error executing insert: ' + err.message);
+    }
+}
+
+async function executeDemoDelete() {
+    if (!demoRowData) {
+        alert('Please load a row first');
+        return;
+    }
+    const pkValue = demoRowData.pkValue || demoRowData.columnData?.[demoIndexInfo?.pkColumn];
+    if (!confirm(`Delete the row with ${demoIndexInfo?.pkColumn} = ${pkValue}?`)) return;
+    try {
+        const res = await fetch(`/api/table/${currentTable}/demo/delete`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ pk: String(pkValue) })
+        });
+        demoDmlResult = { kind: 'delete', ...(await res.json()) };
+        if (demoDmlResult.success) demoRowData = null;
+        renderTableTabContent();
+    } catch (err) {
+        alert('Error executing delete: ' + err.message);
+    }
+}
+
+function renderDemoDmlResult(result) {
+    if (!result.success) {
+        return `
+            <div style="background: rgba(239, 68, 68, 0.1); border: 2px solid var(--red-500); border-radius: 12px; padding: 20px;">
+                <div style="color: var(--red-400); font-weight: 600;">❌ Error</div>
+                <div style="color: var(--text-secondary); margin-top: 8px;">${result.error || 'request failed'}</div>
+            </div>
+        `;
+    }
+    const row = (label, value, color) => `
+        <div style="display: flex; justify-content: space-between;">
+            <span style="color: var(--text-muted);">${label}</span>
+            <span style="font-family: 'IBM Plex Mono', monospace; color: ${color || 'var(--text-primary)'};">${value}</span>
+        </div>`;
+    const tuple = (label, t, border) => `
+        <div style="background: var(--bg-primary); border-radius: 12px; padding: 16px; border: 2px solid ${border};">
+            <div style="font-weight: 600; color: var(--text-muted); margin-bottom: 12px;">${label}</div>
+            <div style="display: grid; gap: 8px;">
+                ${row('TID', t.tid, 'var(--cyan-400)')}
+                ${row('xmin', t.xmin, 'var(--green-400)')}
+                ${row('xmax', t.xmax || '0', t.xmax ? 'var(--red-400)' : 'var(--text-muted)')}
+                ${row('ctid', t.ctid)}
+            </div>
+            <div style="display: flex; flex-wrap: wrap; gap: 4px; margin-top: 8px;">
+                ${(t.infoMask || []).map(f => `<span style="background: var(--bg-tertiary); font-size: 0.7rem; padding: 2px 6px; border-radius: 4px; font-family: 'IBM Plex Mono', monospace;">${f}</span>`).join('')}
+            </div>
+        </div>`;
+
+    let body;
+    if (result.kind === 'insert') {
+        const labels = { fsm: '♻️ FREE SPACE MAP', last_page: '📄 LAST PAGE', new_page: '🆕 NEW PAGE' };
+        body = `
+            <div style="font-size: 1.2rem; font-weight: 800; color: var(--green-400); margin-bottom: 16px;">➕ INSERT → ${labels[result.placement]}</div>
+            <div style="display: grid; grid-template-columns: 1fr 1fr; gap: 16px; margin-bottom: 16px;">
+                ${tuple('NEW TUPLE', result.after, 'var(--green-500)')}
+                <div style="background: var(--bg-primary); border-radius: 12px; padding: 16px; display: grid; gap: 8px; align-content: start;">
+                    ${row('Pages', `${result.pagesBefore} → ${result.pagesAfter}`)}
+                    ${row('Tuple size', `${result.tupleSize} B`)}
+                    ${row('Space needed', `${result.spaceNeeded} B${result.reusedLp ? ' (reused lp)' : ''}`)}
+                    ${row('Page free', `${result.targetFreeBefore} → ${result.targetFreeAfter} B`)}
+                    ${row('Last page free before', `${result.lastPageFreeBefore} B`)}
+                    ${row('Fillfactor', `${result.fillFactor} (${result.reservedSpace} B reserved)`)}
+                    ${result.fsmAvail != null ? row('FSM now records', `${result.fsmAvail} B`) : ''}
+                </div>
+            </div>`;
+    } else {
+        body = `
+            <div style="font-size: 1.2rem; font-weight: 800; color: var(--red-400); margin-bottom: 16px;">🗑️ DELETE ${result.pk}</div>
+            <div style="display: grid; grid-template-columns: 1fr auto 1fr; gap: 16px; margin-bottom: 16px;">
+                ${tuple('BEFORE', result.before, 'var(--border-light)')}
+                <div style="display: flex; align-items: center; font-size: 2rem; color: var(--text-muted);">→</div>
+                ${tuple('AFTER (still on the page)', result.after, 'var(--red-500)')}
+            </div>
+            <div style="background: var(--bg-primary); border-radius: 12px; padding: 16px; display: grid; gap: 8px; margin-bottom: 16px;">
+                ${row('Infomask set', result.infoMaskSet.join(', ') || '-', 'var(--green-400)')}
+                ${row('Infomask cleared', result.infoMaskCleared.join(', ') || '-', 'var(--red-400)')}
+                ${row('pd_prune_xid', `${result.pruneXidBefore} → ${result.pruneXidAfter}`)}
+                ${row('Page free / prune-on-read threshold', `${result.pageFree} / ${result.pruneThreshold} B`)}
+                ${row('VACUUM verdict', result.vacuumState)}
+                ${row('Pruning now', result.prune ? `lp ${result.prune.lp}: ${result.prune.from} → ${result.prune.to}` : 'no change')}
+            </div>`;
+    }
+    const page = result.after.page;
+    return `
+        <div style="background: var(--bg-secondary); border-radius: 16px; padding: 24px; animation: fadeIn 0.5s ease;">
+            ${body}
+            <div style="background: var(--bg-tertiary); border-radius: 12px; padding: 16px;">
+                <div style="font-weight: 600; margin-bottom: 8px;">💡 What happened?</div>
+                <div style="color: var(--text-secondary); line-height: 1.6;">${result.explanation}</div>
+            </div>
+            <div style="display: flex; gap: 8px; margin-top: 16px;">
+                <button class="btn" onclick="viewDemoPage(${page})" style="flex: 1;">View Page ${page}</button>
+                <button class="btn" onclick="demoDmlResult = null; renderTableTabContent()" style="flex: 1;">Clear Result</button>
+            </div>
+        </div>
+    `;
+}
+
+// ==================== BULK UPDATE EXPERIMENT ====================
+
+let experimentRuns = [];
+const EXPERIMENT_COLORS = ['var(--orange-400)', 'var(--blue-400)', 'var(--green-400)', 'var(--purple-400)', 'var(--red-400)', 'var(--cyan-400)'];
+
+function renderUpdateExperiment(pkColumn, indexedColumns, nonIndexedColumns) {
+    const field = 'padding: 8px 12px; background: var(--bg-primary); border: 1px solid var(--border-light); border-radius: 8px; color: var(--text-primary); font-family: \'IBM Plex Mono\', monospace; font-size: 0.85rem; width: 100%;';
+    const label = 'display: block; font-size: 0.75rem; color: var(--text-muted); margin-bottom: 4px;';
+    const columns = [...nonIndexedColumns, ...indexedColumns.filter(c => c !== pkColumn)];
+    return `
+        <div style="background: var(--bg-secondary); border-radius: 12px; padding: 20px; margin-top: 24px;">
+            <div style="font-weight: 700; margin-bottom: 8px;">📈 Bulk Update Experiment</div>
+            <div style="font-size: 0.8rem; color: var(--text-muted); margin-bottom: 12px;">
+                Runs many updates on a scratch copy of the table (the table itself is not touched) and tracks HOT ratio, line pointers and free space.
+                Rerun with another fillfactor to compare.
+            </div>
+            <div style="display: grid; grid-template-columns: repeat(auto-fit, minmax(140px, 1fr)); gap: 12px; margin-bottom: 12px;">
+                <div><label style="${label}">Updates</label><input id="expUpdates" type="number" value="1000" min="1" max="20000" style="${field}"></div>
+                <div><label style="${label}">Steps</label><input id="expSteps" type="number" value="20" min="1" max="100" style="${field}"></div>
+                <div><label style="${label}">Rows</label>
+                    <select id="expDistribution" style="${field}">
+                        <option value="uniform">uniform</option>
+                        <option value="skewed">skewed (80/20)</option>
+                        <option value="sequential">sequential</option>
+                    </select>
+                </div>
+                <div><label style="${label}">Fillfactor (empty = table's)</label><input id="expFillFactor" type="number" min="10" max="100" placeholder="table" style="${field}"></div>
+                <div><label style="${label}">Seed</label><input id="expSeed" type="number" value="1" min="1" style="${field}"></div>
+                <div><label style="${label}">Columns</label>
+                    <select id="expColumns" multiple size="3" style="${field}">
+                        ${columns.map((c, i) => `<option value="${c}" ${i === 0 ? 'selected' : ''}>${c}${indexedColumns.includes(c) ? ' (indexed)' : ''}</option>`).join('')}
+                    </select>
+                </div>
+            </div>
+            <div style="display: flex; gap: 8px; margin-bottom: 16px;">
+                <button class="btn" id="expRunBtn" onclick="runUpdateExperiment()" style="flex: 1;">Run Experiment</button>
+                ${experimentRuns.length ? `<button class="btn" onclick="experimentRuns = []; renderTableTabContent()">Clear Runs</button>` : ''}
+            </div>
+            ${experimentRuns.length ? renderExperimentRuns() : ''}
+        </div>
+    `;
+}
+
+async function runUpdateExperiment() {
+    const num = id => parseInt(document.getElementById(id)?.value, 10) || 0;
+    const cfg = {
+        updates: num('expUpdates'),
+        steps: num('expSteps'),
+        distribution: document.getElementById('expDistribution').value,
+        fillFactor: num('expFillFactor'),
+        seed: num('expSeed'),
+        columns: [...document.getElementById('expColumns').selectedOptions].map(o => o.value)
+    };
+    const btn = document.getElementById('expRunBtn');
+    btn.disabled = true;
+    btn.textContent = 'Running...';
+    try {
+        const res = await fetch(`/api/table/${currentTable}/experiment/updates`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(cfg)
+        });
+        const data = await res.json();
+        if (!data.success) {
+            alert('Experiment failed: ' + (data.error || res.statusText));
+        } else {
+            experimentRuns.push(data);
+        }
+    } catch (err) {
+        alert('Error running experiment: ' + err.message);
+    }
+    renderTableTabContent();
+}
+
+function renderLineChart(title, runs, value, yMax, format) {
+    const w = 480, h = 160, pad = 30;
+    const xMax = Math.max(...runs.map(r => r.config.updates), 1);
+    const x = v => pad + (w - 2 * pad) * v / xMax;
+    const y = v => h - pad + (2 * pad - h) * v / (yMax || 1);
+    const lines = runs.map((r, i) => {
+        const pts = [r.start, ...r.series].map(s => `${x(s.updates).toFixed(1)},${y(value(s)).toFixed(1)}`).join(' ');
+        return `<polyline points="${pts}" fill="none" stroke="${EXPERIMENT_COLORS[i % EXPERIMENT_COLORS.length]}" stroke-width="2"/>`;
+    }).join('');
+    return `
+        <div style="background: var(--bg-primary); border-radius: 12px; padding: 12px;">
+            <div style="font-size: 0.8rem; font-weight: 600; margin-bottom: 4px;">${title}</div>
+            <svg viewBox="0 0 ${w} ${h}" style="width: 100%; height: auto;">
+                <line x1="${pad}" y1="${h - pad}" x2="${w - pad}" y2="${h - pad}" stroke="var(--border-light)"/>
+                <line x1="${pad}" y1="${pad}" x2="${pad}" y2="${h - pad}" stroke="var(--border-light)"/>
+                <text x="${pad - 4}" y="${pad + 4}" text-anchor="end" font-size="10" fill="var(--text-muted)">${format(yMax)}</text>
+                <text x="${pad - 4}" y="${h - pad}" text-anchor="end" font-size="10" fill="var(--text-muted)">0</text>
+                <text x="${w - pad}" y="${h - pad + 14}" text-anchor="end" font-size="10" fill="var(--text-muted)">${xMax} updates</text>
+                ${lines}
+            </svg>
+        </div>`;
+}
+
+function renderExperimentRuns() {
+    const all = experimentRuns.flatMap(r => [r.start, ...r.series]);
+    const maxOf = f => Math.max(...all.map(f), 1);
+    const last = experimentRuns[experimentRuns.length - 1];
+    return `
+        <div style="display: flex; flex-wrap: wrap; gap: 12px; margin-bottom: 12px; font-size: 0.8rem;">
+            ${experimentRuns.map((r, i) => `
+                <span style="color: ${EXPERIMENT_COLORS[i % EXPERIMENT_COLORS.length]}; font-family: 'IBM Plex Mono', monospace;">
+                    ■ run ${i + 1}: ff ${r.config.fillFactor}, ${r.config.distribution}, ${r.config.columns.join('+')} → ${(100 * r.hotRatio).toFixed(0)}% HOT
+                </span>`).join('')}
+        </div>
+        <div style="display: grid; grid-template-columns: 1fr 1fr; gap: 12px; margin-bottom: 12px;">
+            ${renderLineChart('HOT ratio', experimentRuns, s => s.hotRatio, 1, v => `${v * 100}%`)}
+            ${renderLineChart('Average free space per page (bytes)', experimentRuns, s => s.avgPageFree, maxOf(s => s.avgPageFree), v => v)}
+            ${renderLineChart('Line pointers', experimentRuns, s => s.linePointers, maxOf(s => s.linePointers), v => v)}
+            ${renderLineChart('LP_REDIRECT items', experimentRuns, s => s.redirects, maxOf(s => s.redirects), v => v)}
+        </div>
+        <div style="background: var(--bg-tertiary); border-radius: 12px; padding: 16px; color: var(--text-secondary); line-height: 1.6;">
+            ${last.explanation}${last.rowsCapped ? ' Only the first 10000 rows were copied.' : ''}
+        </div>`;
+}
+
+// ==================== PRUNE ON READ ====================
+
+let demoPruneResult = null;
+
+function renderPruneDemo(pkColumn, nonIndexedColumns) {
+    const pkValue = demoRowData ? (demoRowData.pkValue ?? demoRowData.columnData?.[pkColumn]) : null;
+    return `
+        <div style="background: var(--bg-secondary); border: 2px solid var(--cyan-400); border-radius: 12px; padding: 20px; margin-top: 24px;">
+            <div style="font-weight: 700; color: var(--cyan-400); margin-bottom: 8px;">🧹 Prune on Read</div>
+            <div style="font-size: 0.8rem; color: var(--text-muted); margin-bottom: 12px;">
+                Fills the loaded row's page with dead HOT versions in one transaction, then runs a plain SELECT over the page.
+                Reading a nearly full page prunes it - no VACUUM involved.
+            </div>
+            <div style="display: flex; gap: 8px;">
+                <select id="demoPruneColumn" style="padding: 8px 12px; background: var(--bg-primary); border: 1px solid var(--border-light); border-radius: 8px; color: var(--text-primary); font-family: 'IBM Plex Mono', monospace; font-size: 0.85rem;">
+                    ${(nonIndexedColumns || []).map(c => `<option value="${c}">${c}</option>`).join('')}
+                </select>
+                <button class="btn" id="demoPruneBtn" onclick="executeDemoPrune()" ${pkValue != null ? '' : 'disabled'} style="flex: 1;">
+                    ${pkValue != null ? `Fill page and SELECT (${pkColumn} = ${pkValue})` : 'Load a row first'}
+                </button>
+            </div>
+            ${demoPruneResult ? renderDemoPruneResult(demoPruneResult) : ''}
+        </div>
+    `;
+}
+
+async function executeDemoPrune() {
+    const pkValue = demoRowData?.pkValue || demoRowData?.columnData?.[demoIndexInfo?.pkColumn];
+    const btn = document.getElementById('demoPruneBtn');
+    btn.disabled = true;
+    btn.textContent = 'Running...';
+    try {
+        const res = await fetch(`/api/table/${currentTable}/demo/prune`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ pk: String(pkValue), column: document.getElementById('demoPruneColumn')?.value || '' })
+        });
+        demoPruneResult = await res.json();
+    } catch (err) {
+        alert('Error running prune demo: ' + err.message);
+    }
+    renderTableTabContent();
+}
+
+function renderDemoPruneResult(result) {
+    if (!result.success) {
+        return `
+            <div style="background: rgba(239, 68, 68, 0.1); border: 2px solid var(--red-500); border-radius: 12px; padding: 16px; margin-top: 16px;">
+                <div style="color: var(--red-400); font-weight: 600;">❌ Error</div>
+                <div style="color: var(--text-secondary); margin-top: 8px;">${result.error || 'request failed'}</div>
+            </div>`;
+    }
+    const triggers = { page_full: 'PD_PAGE_FULL set', low_free: `free space ${result.freeSpace} B below ${result.threshold} B`, none: 'not triggered' };
+    const state = (label, s) => `
+        <div style="background: var(--bg-primary); border-radius: 12px; padding: 16px;">
+            <div style="font-weight: 600; color: var(--text-muted); margin-bottom: 12px;">${label}</div>
+            <div style="display: grid; grid-template-columns: 1fr 1fr; gap: 6px; font-family: 'IBM Plex Mono', monospace; font-size: 0.8rem;">
+                <span>LP_NORMAL</span><span>${s.normal}</span>
+                <span style="color: var(--orange-400);">LP_REDIRECT</span><span>${s.redirect}</span>
+                <span style="color: var(--red-400);">LP_DEAD</span><span>${s.dead}</span>
+                <span style="color: var(--text-muted);">LP_UNUSED</span><span>${s.unused}</span>
+                <span>free</span><span>${s.free} B</span>
+                <span>pd_prune_xid</span><span>${s.pruneXid}</span>
+                <span>PD_PAGE_FULL</span><span>${s.pageFull ? 'yes' : 'no'}</span>
+            </div>
+            <div style="margin-top: 8px; font-size: 0.75rem; color: var(--text-muted); font-family: 'IBM Plex Mono', monospace;">
+                ${s.chains.map(c => `lp ${c.root}${c.redirectTo ? ` ↪ ${c.redirectTo}` : ''}: ${c.members.map(m => m.lp).join(' → ') || c.rootFlags}`).join('<br>')}
+            </div>
+        </div>`;
+    return `
+        <div style="margin-top: 16px;">
+            <div style="font-family: 'IBM Plex Mono', monospace; font-size: 0.8rem; color: var(--text-muted); margin-bottom: 12px;">
+                ${result.updates} updates on page ${result.blockNo}, then <span style="color: var(--text-primary);">${result.query}</span>
+                · trigger: <span style="color: ${result.trigger === 'none' ? 'var(--text-muted)' : 'var(--cyan-400)'};">${triggers[result.trigger]}</span>
+                · ${result.pruned ? '<span style="color: var(--green-400);">pruned</span>' : 'not pruned'}
+            </div>
+            <div style="display: grid; grid-template-columns: 1fr 1fr; gap: 16px; margin-bottom: 16px;">
+                ${state('BEFORE THE SELECT', result.before)}
+                ${state('AFTER THE SELECT', result.after)}
+            </div>
+            ${result.changes.length ? `
+            <div style="font-size: 0.8rem; font-family: 'IBM Plex Mono', monospace; margin-bottom: 16px; max-height: 200px; overflow-y: auto;">
+                ${result.changes.map(ch => `<div>lp ${ch.lp}: ${ch.from} → ${ch.to}${ch.redirectTo ? ` (${ch.redirectTo})` : ''} <span style="color: var(--text-muted);">${ch.reason || ''}</span></div>`).join('')}
+            </div>` : ''}
+            <div style="background: var(--bg-tertiary); border-radius: 12px; padding: 16px; color: var(--text-secondary); line-height: 1.6;">
+                ${result.explanation}
+            </div>
+            <div style="display: flex; gap: 8px; margin-top: 12px;">
+                <button class="btn" onclick="viewDemoPage(${result.blockNo})" style="flex: 1;">View Page ${result.blockNo}</button>
+                <button class="btn" onclick="demoPruneResult = null; renderTableTabContent()" style="flex: 1;">Clear Result</button>
+            </div>
+        </div>`;
+}
+
+// ==================== SAVEPOINTS ====================
+
+let savepointResult = null;
+
+function renderSavepointScenario(pkColumn, indexedColumns, nonIndexedColumns) {
+    const pkValue = demoRowData ? (demoRowData.pkValue ?? demoRowData.columnData?.[pkColumn]) : null;
+    const field = 'padding: 8px 12px; background: var(--bg-primary); border: 1px solid var(--border-light); border-radius: 8px; color: var(--text-primary); font-family: \'IBM Plex Mono\', monospace; font-size: 0.85rem;';
+    const columns = [...nonIndexedColumns, ...indexedColumns.filter(c => c !== pkColumn)];
+    return `
+        <div style="background: var(--bg-secondary); border: 2px solid var(--purple-400); border-radius: 12px; padding: 20px; margin-top: 24px;">
+            <div style="font-weight: 700; color: var(--purple-400); margin-bottom: 8px;">🪆 Savepoints and Subtransactions</div>
+            <div style="font-size: 0.8rem; color: var(--text-muted); margin-bottom: 12px;">
+                Runs BEGIN, UPDATE, SAVEPOINT s1, UPDATE, SAVEPOINT s2, UPDATE, ROLLBACK TO SAVEPOINT s2 in the held session <code>savepoint-demo</code>
+                and shows the row's versions after each step: subtransaction XIDs, HEAP_COMBOCID and what the rollback leaves behind.
+            </div>
+            <div style="display: flex; gap: 8px;">
+                <select id="savepointColumn" style="${field}">
+                    ${columns.map(c => `<option value="${c}">${c}${indexedColumns.includes(c) ? ' (indexed)' : ''}</option>`).join('')}
+                </select>
+                <select id="savepointFinish" style="${field}">
+                    <option value="hold">then hold the transaction</option>
+                    <option value="commit">then COMMIT</option>
+                    <option value="rollback">then ROLLBACK</option>
+                </select>
+                <button class="btn" id="savepointBtn" onclick="runSavepointScenario()" ${pkValue != null ? '' : 'disabled'} style="flex: 1;">
+                    ${pkValue != null ? `Run on ${pkColumn} = ${pkValue}` : 'Load a row first'}
+                </button>
+            </div>
+            ${savepointResult ? renderSavepointResult(savepointResult) : ''}
+        </div>
+    `;
+}
+
+async function runSavepointScenario() {
+    const pkValue = demoRowData?.pkValue || demoRowData?.columnData?.[demoIndexInfo?.pkColumn];
+    const btn = document.getElementById('savepointBtn');
+    btn.disabled = true;
+    btn.textContent = 'Running...';
+    try {
+        const res = await fetch(`/api/table/${currentTable}/demo/savepoints`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({
+                pk: String(pkValue),
+                column: document.getElementById('savepointColumn')?.value || '',
+                finish: document.getElementById('savepointFinish')?.value || 'hold'
+            })
+        });
+        savepointResult = await res.json();
+    } catch (err) {
+        alert('Error running savepoint scenario: ' + err.message);
+    }
+    renderTableTabContent();
+}
+
+function renderSavepointResult(result) {
+    if (!result.success) {
+        return `
+            <div style="background: rgba(239, 68, 68, 0.1); border: 2px solid var(--red-500); border-radius: 12px; padding: 16px; margin-top: 16px;">
+                <div style="color: var(--red-400); font-weight: 600;">❌ Error</div>
+                <div style="color: var(--text-secondary); margin-top: 8px;">${result.error || 'request failed'}</div>
+            </div>`;
+    }
+    const xid = (x, owner, status) => x ? `${x}${owner ? ` <span style="color: var(--purple-400);">${owner}</span>` : ''} <span style="color: var(--text-muted);">${status || ''}</span>` : '-';
+    const cell = 'padding: 6px;';
+    return `
+        <div style="margin-top: 16px;">
+            <div style="display: flex; flex-wrap: wrap; gap: 12px; margin-bottom: 16px; font-family: 'IBM Plex Mono', monospace; font-size: 0.8rem;">
+                <span style="background: var(--bg-primary); padding: 6px 10px; border-radius: 8px;">top-level ${result.topXid}</span>
+                ${result.subXacts.map(s => `
+                <span style="background: var(--bg-primary); padding: 6px 10px; border-radius: 8px;">
+                    ${s.savepoint}: ${s.xid} → parent ${s.parent} <span style="color: ${s.status === 'aborted' ? 'var(--red-400)' : 'var(--text-muted)'};">${s.status}</span>
+                </span>`).join('')}
+            </div>
+            ${result.stages.map((st, n) => `
+            <div style="background: var(--bg-primary); border-radius: 12px; padding: 16px; margin-bottom: 12px;">
+                <div style="font-weight: 600; margin-bottom: 4px;">${n + 1}. ${st.label}${st.pageChanged ? '' : n > 0 ? ' <span style="color: var(--text-muted); font-weight: 400;">(page unchanged)</span>' : ''}</div>
+                <div style="font-family: 'IBM Plex Mono', monospace; font-size: 0.75rem; color: var(--text-muted); margin-bottom: 8px;">${st.sql}</div>
+                <table style="width: 100%; border-collapse: collapse; font-size: 0.8rem; font-family: 'IBM Plex Mono', monospace;">
+                    <thead>
+                        <tr style="text-align: left; color: var(--text-muted);">
+                            <th style="${cell}">TID</th><th style="${cell}">xmin</th><th style="${cell}">xmax</th><th style="${cell}">t_cid</th>
+                            <th style="${cell}">${result.column}</th><th style="${cell}">inside</th><th style="${cell}">outside</th>
+                        </tr>
+                    </thead>
+                    <tbody>
+                        ${st.versions.map(v => `
+                        <tr style="border-top: 1px solid var(--border-light);">
+                            <td style="${cell}">${v.tid}</td>
+                            <td style="${cell}">${xid(v.xmin, v.xminOwner, v.xminStatus)}</td>
+                            <td style="${cell}">${xid(v.xmax, v.xmaxOwner, v.xmaxStatus)}</td>
+                            <td style="${cell}">${v.cid}${v.comboCid ? ' <span style="color: var(--orange-400);">HEAP_COMBOCID</span>' : ''}</td>
+                            <td style="${cell}">${v.value ?? 'NULL'}</td>
+                            <td style="${cell} color: ${v.visibleInside ? 'var(--green-400)' : 'var(--text-muted)'};">${v.visibleInside ? '✓' : '-'}</td>
+                            <td style="${cell} color: ${v.visibleOutside ? 'var(--green-400)' : 'var(--text-muted)'};">${v.visibleOutside ? '✓' : '-'}</td>
+                        </tr>`).join('')}
+                    </tbody>
+                </table>
+                <div style="font-size: 0.8rem; color: var(--text-secondary); margin-top: 8px; line-height: 1.5;">${st.explanation}</div>
+            </div>`).join('')}
+            <div style="background: var(--bg-tertiary); border-radius: 12px; padding: 16px; color: var(--text-secondary); line-height: 1.6;">
+                ${result.explanation}
+            </div>
+            <div style="display: flex; gap: 8px; margin-top: 12px;">
+                ${result.session ? `<button class="btn" onclick="closeSession('${result.session}').then(() => { savepointResult.session = ''; renderTableTabContent(); })" style="flex: 1;">Close Session (rolls back)</button>` : ''}
+                <button class="btn" onclick="savepointResult = null; renderTableTabContent()" style="flex: 1;">Clear Result</button>
+            </div>
+        </div>`;
+}
+
+// ==================== ROW VERSION HISTORY ====================
+
+let rowHistory = null;
+const ROW_STATE_COLORS = { live: 'var(--green-400)', dead: 'var(--red-400)', aborted: 'var(--purple-400)', 'in progress': 'var(--orange-400)' };
+
+function renderRowHistory(pkColumn) {
+    const pk = rowHistory?.pk ?? demoRowData?.pkValue ?? demoRowData?.columnData?.[pkColumn] ?? '';
+    return `
+        <div style="background: var(--bg-secondary); border-radius: 12px; padding: 20px; margin-top: 24px;">
+            <div style="font-weight: 700; margin-bottom: 8px;">🔎 Row Version History</div>
+            <div style="font-size: 0.8rem; color: var(--text-muted); margin-bottom: 12px;">
+                Scans the heap for every version of a row still on disk - live, dead but not yet vacuumed, and written by aborted transactions - and links them along t_ctid.
+            </div>
+            <div style="display: flex; gap: 8px; margin-bottom: 16px;">
+                <input type="text" id="rowHistoryPK" value="${pk}" placeholder="${pkColumn} value"
+                       style="flex: 1; padding: 8px 12px; background: var(--bg-primary); border: 1px solid var(--border-light); border-radius: 8px; color: var(--text-primary); font-family: 'IBM Plex Mono', monospace; font-size: 0.85rem;">
+                <button class="btn" id="rowHistoryBtn" onclick="loadRowHistory()">Find All Versions</button>
+                ${rowHistory?.nextBlock ? `<button class="btn" onclick="loadRowHistory(${rowHistory.nextBlock})">Continue from block ${rowHistory.nextBlock}</button>` : ''}
+                ${rowHistory ? `<button class="btn" onclick="rowHistory = null; renderTableTabContent()">Clear</button>` : ''}
+            </div>
+            ${rowHistory ? renderRowVersions(rowHistory) : ''}
+        </div>
+    `;
+}
+
+async function loadRowHistory(from = 0) {
+    const pk = document.getElementById('rowHistoryPK')?.value.trim();
+    if (!pk) {
+        alert('Enter a primary key value');
+        return;
+    }
+    const btn = document.getElementById('rowHistoryBtn');
+    btn.disabled = true;
+    btn.textContent = 'Scanning...';
+    try {
+        rowHistory = await fetchAPI(`/api/table/${currentTable}/row-versions?pk=${encodeURIComponent(pk)}&from=${from}`);
+    } catch (err) {
+        alert('Error loading row history: ' + err.message);
+    }
+    renderTableTabContent();
+}
+
+function renderRowVersions(h) {
+    const cell = v => v === null || v === undefined ? '<span style="color: var(--text-muted);">NULL</span>' : v;
+    return `
+        <div style="display: flex; flex-direction: column; gap: 8px; margin-bottom: 12px;">
+            ${h.versions.map(v => `
+                <div style="background: var(--bg-primary); border-left: 4px solid ${ROW_STATE_COLORS[v.state] || 'var(--border-light)'}; border-radius: 8px; padding: 12px; ${v.previous ? 'margin-left: 24px;' : ''}">
+                    <div style="display: flex; flex-wrap: wrap; gap: 12px; align-items: center; font-family: 'IBM Plex Mono', monospace; font-size: 0.8rem;">
+                        <a href="#" onclick="viewDemoPage(${v.page}); return false;" style="color: var(--blue-400); font-weight: 600;">${v.tid}</a>
+                        <span style="color: ${ROW_STATE_COLORS[v.state] || 'inherit'}; font-weight: 600;">${v.state.toUpperCase()}</span>
+                        <span>xmin ${v.xmin} (${v.xminStatus})</span>
+                        <span>xmax ${v.xmax || '-'}${v.xmax ? ` (${v.xmaxStatus})` : ''}</span>
+                        <span>t_ctid ${v.ctid}</span>
+                        ${v.heapOnly ? '<span style="color: var(--orange-400);">heap-only</span>' : ''}
+                        <span style="color: var(--text-muted);">vacuum: ${v.vacuumState}${v.removable ? ' (removable)' : ''}</span>
+                    </div>
+                    ${v.changes.length ? `
+                    <div style="margin-top: 8px; font-size: 0.8rem; font-family: 'IBM Plex Mono', monospace;">
+                        ${v.changes.map(c => `<div>${c.column}: <span style="color: var(--red-400); text-decoration: line-through;">${cell(c.old)}</span> → <span style="color: var(--green-400);">${cell(c.new)}</span></div>`).join('')}
+                    </div>` : (v.previous ? '' : `
+                    <div style="margin-top: 8px; font-size: 0.75rem; color: var(--text-muted); font-family: 'IBM Plex Mono', monospace;">
+                        ${Object.entries(v.attrs).map(([k, val]) => `${k}=${cell(val)}`).join(', ')}
+                    </div>`)}
+                </div>`).join('')}
+        </div>
+        <div style="background: var(--bg-tertiary); border-radius: 12px; padding: 16px; color: var(--text-secondary); line-height: 1.6;">
+            ${h.explanation}
+        </div>`;
+}
+
+// ==================== DEAD TUPLE BROWSER ====================
+
+let deadTuples = null;   // { query, tuples, last } across "load more" pages
+
+function renderDeadTuplesTab() {
+    const field = 'padding: 8px 12px; background: var(--bg-primary); border: 1px solid var(--border-light); border-radius: 8px; color: var(--text-primary); font-family: \'IBM Plex Mono\', monospace; font-size: 0.85rem; width: 100%;';
+    const label = 'display: block; font-size: 0.75rem; color: var(--text-muted); margin-bottom: 4px;';
+    const q = deadTuples?.query || {};
+    const lp = q.lp ? q.lp.split(',') : ['normal', 'dead'];
+    return `
+        <div class="btree-container animate-in">
+            <div style="font-weight: 700; margin-bottom: 8px;">🧟 Dead Tuple Browser</div>
+            <div style="font-size: 0.8rem; color: var(--text-muted); margin-bottom: 12px;">
+                Lists tuples the current snapshot can't see, and leftover line pointers, across the whole heap - so you can find where the bloat is without paging through the heap one block at a time.
+            </div>
+            <div style="display: grid; grid-template-columns: repeat(auto-fit, minmax(130px, 1fr)); gap: 12px; margin-bottom: 12px;">
+                <div><label style="${label}">xmin from</label><input id="deadXminFrom" type="number" min="0" value="${q.xminFrom || ''}" style="${field}"></div>
+                <div><label style="${label}">xmin to</label><input id="deadXminTo" type="number" min="0" value="${q.xminTo || ''}" style="${field}"></div>
+                <div><label style="${label}">xmax from</label><input id="deadXmaxFrom" type="number" min="0" value="${q.xmaxFrom || ''}" style="${field}"></div>
+                <div><label style="${label}">xmax to</label><input id="deadXmaxTo" type="number" min="0" value="${q.xmaxTo || ''}" style="${field}"></div>
+                <div><label style="${label}">Removable now</label>
+                    <select id="deadRemovable" style="${field}">
+                        <option value="" ${!q.removable ? 'selected' : ''}>any</option>
+                        <option value="true" ${q.removable === 'true' ? 'selected' : ''}>yes</option>
+                        <option value="false" ${q.removable === 'false' ? 'selected' : ''}>no</option>
+                    </select>
+                </div>
+                <div><label style="${label}">Per page</label><input id="deadLimit" type="number" min="1" max="1000" value="${q.limit || 100}" style="${field}"></div>
+            </div>
+            <div style="display: flex; flex-wrap: wrap; gap: 16px; margin-bottom: 12px; font-size: 0.8rem;">
+                ${['normal', 'dead', 'redirect', 'unused'].map(s => `
+                <label><input type="checkbox" class="deadLpState" value="${s}" ${lp.includes(s) ? 'checked' : ''}> LP_${s.toUpperCase()}</label>`).join('')}
+            </div>
+            <div style="display: flex; gap: 8px; margin-bottom: 16px;">
+                <button class="btn" id="deadSearchBtn" onclick="loadDeadTuples()" style="flex: 1;">Search Heap</button>
+                ${deadTuples?.last?.nextCursor ? `<button class="btn" onclick="loadDeadTuples(true)">Load More (from ${deadTuples.last.nextCursor})</button>` : ''}
+            </div>
+            ${deadTuples ? renderDeadTupleList(deadTuples) : ''}
+        </div>
+    `;
+}
+
+async function loadDeadTuples(more = false) {
+    let query = deadTuples?.query;
+    if (!more) {
+        const val = id => document.getElementById(id)?.value.trim() || '';
+        query = {
+            xminFrom: val('deadXminFrom'),
+            xminTo: val('deadXminTo'),
+            xmaxFrom: val('deadXmaxFrom'),
+            xmaxTo: val('deadXmaxTo'),
+            removable: val('deadRemovable'),
+            limit: val('deadLimit'),
+            lp: [...document.querySelectorAll('.deadLpState:checked')].map(c => c.value).join(',')
+        };
+    }
+    const params = new URLSearchParams(Object.entries(query).filter(([, v]) => v));
+    if (more) params.set('cursor', deadTuples.last.nextCursor);
+
+    const btn = document.getElementById('deadSearchBtn');
+    btn.disabled = true;
+    btn.textContent = 'Scanning...';
+    try {
+        const data = await fetchAPI(`/api/table/${currentTable}/dead-tuples?${params}`);
+        deadTuples = { query, tuples: more ? [...deadTuples.tuples, ...data.tuples] : data.tuples, last: data };
+    } catch (err) {
+        alert('Error loading dead tuples: ' + err.message);
+    }
+    renderTableTabContent();
+}
+
+function renderDeadTupleList(d) {
+    const cell = v => v === null || v === undefined ? 'NULL' : v;
+    const lpColors = { LP_NORMAL: 'var(--red-400)', LP_DEAD: 'var(--text-muted)', LP_REDIRECT: 'var(--orange-400)', LP_UNUSED: 'var(--border-light)' };
+    return `
+        <div style="background: var(--bg-tertiary); border-radius: 12px; padding: 16px; color: var(--text-secondary); line-height: 1.6; margin-bottom: 12px;">
+            ${d.last.explanation}
+        </div>
+        ${d.tuples.length ? `
+        <div style="overflow-x: auto;">
+            <table style="width: 100%; border-collapse: collapse; font-size: 0.8rem; font-family: 'IBM Plex Mono', monospace;">
+                <thead>
+                    <tr style="text-align: left; color: var(--text-muted);">
+                        <th style="padding: 6px;">TID</th><th style="padding: 6px;">LP</th><th style="padding: 6px;">xmin</th><th style="padding: 6px;">xmax</th>
+                        <th style="padding: 6px;">vacuum</th><th style="padding: 6px;">removable</th><th style="padding: 6px;">data</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    ${d.tuples.map(t => `
+                    <tr style="border-top: 1px solid var(--border-light);" title="${t.reason}">
+                        <td style="padding: 6px;"><a href="#" onclick="viewDemoPage(${t.page}); return false;" style="color: var(--blue-400);">${t.tid}</a></td>
+                        <td style="padding: 6px; color: ${lpColors[t.lpFlags] || 'inherit'};">${t.lpFlags}${t.redirectTo ? ` → ${t.redirectTo}` : ''}</td>
+                        <td style="padding: 6px;">${t.xmin ? `${t.xmin} <span style="color: var(--text-muted);">${t.xminStatus || ''}</span>` : '-'}</td>
+                        <td style="padding: 6px;">${t.xmax ? `${t.xmax} <span style="color: var(--text-muted);">${t.xmaxStatus || ''}</span>` : '-'}</td>
+                        <td style="padding: 6px;">${t.vacuumState || '-'}</td>
+                        <td style="padding: 6px; color: ${t.removable ? 'var(--green-400)' : 'var(--text-muted)'};">${t.removable ? 'yes' : 'no'}</td>
+                        <td style="padding: 6px; color: var(--text-muted); max-width: 360px; overflow: hidden; text-overflow: ellipsis; white-space: nowrap;">
+                            ${t.attrs ? Object.entries(t.attrs).map(([k, v]) => `${k}=${cell(v)}`).join(', ') : t.reason}
+                        </td>
+                    </tr>`).join('')}
+                </tbody>
+            </table>
+        </div>` : ''}`;
+}
+
 function viewDemoPage(pageNo) {
     highlightTID = null;
     currentTab = 'pages';